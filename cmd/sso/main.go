@@ -2,17 +2,19 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"sso/internal/app"
 	"sso/internal/config"
-	jaegerT "sso/internal/lib/jaeger"
+	"sso/internal/lib/postgresql/migrator"
 	"sso/internal/lib/postgresql/pqfuncs"
-
-	"github.com/opentracing/opentracing-go"
+	"sso/internal/lib/tracing"
+	"sso/migrations"
 )
 
 const (
@@ -29,6 +31,25 @@ func main() {
 
 	//запуск gRPC-сервера приложения
 
+	if len(os.Args) > 1 {
+		var command func([]string) error
+
+		switch os.Args[1] {
+		case "policy":
+			command = runPolicy
+		case "migrate":
+			command = runMigrate
+		}
+
+		if command != nil {
+			if err := command(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	//config init
 
 	cfg := config.MustLoad()
@@ -42,25 +63,38 @@ func main() {
 
 	//pooling start
 	psqlDB, err := pqfuncs.Run(ctx, cfg, log)
-	if err != nil || psqlDB == nil {
+	if err != nil {
 		log.Error("Postgres init error", slog.Any("err", err))
+		os.Exit(1)
 	}
 
-	//opentracing, jaeger init
-	tracer, closer, err := jaegerT.InitJaeger(cfg)
-	if err != nil {
-		log.Error("Init jaeger error", slog.Any("err", err))
+	if cfg.Postgres.AutoMigrate {
+		m, err := migrator.New(log, psqlDB, migrations.FS)
+		if err == nil {
+			err = m.Up(ctx)
+		}
+		if err != nil {
+			log.Error("migrations error", slog.Any("err", err))
+			os.Exit(1)
+		}
 	}
-	log.Info("jaeger connected")
-
-	opentracing.SetGlobalTracer(tracer)
 
-	defer closer.Close()
+	//opentelemetry tracing init
+	shutdownTracing, err := tracing.Init(ctx, cfg.Tracing, cfg.Env)
+	if err != nil {
+		log.Error("Init tracing error", slog.Any("err", err))
+		os.Exit(1)
+	}
+	log.Info("tracing initialized", slog.String("exporter", cfg.Tracing.Exporter))
 	//starting application
-	application := app.New(log, cfg.GRPC.Port, &cfg.Postgres, cfg.TokenTTL, psqlDB)
+	application := app.New(log, cfg, psqlDB)
 
 	go application.GRPCServer.MustRun()
 
+	if application.Gateway != nil {
+		go application.Gateway.MustRun()
+	}
+
 	//signal monitoring
 	stop := make(chan os.Signal, 1)
 
@@ -69,9 +103,17 @@ func main() {
 
 	log.Info("stopping aplication", slog.String("last signal", siginf.String()))
 
-	application.GRPCServer.Stop()
+	application.Stop()
 	pqfuncs.Stop()
 
+	// spans of last requests are still in batch
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFlush()
+
+	if err := shutdownTracing(flushCtx); err != nil {
+		log.Warn("tracing was not flushed", slog.Any("err", err))
+	}
+
 	log.Info("application will stop after manage last orders before signal")
 
 }
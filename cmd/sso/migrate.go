@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"sso/internal/config"
+	postgresinit "sso/internal/lib/postgresql"
+	"sso/internal/lib/postgresql/migrator"
+	"sso/migrations"
+)
+
+const migrateUsage = `usage:
+  sso migrate up [-config path]
+  sso migrate down [-config path]
+  sso migrate status [-config path]
+  sso migrate to N [-config path]
+  sso migrate baseline N [-config path]`
+
+// runMigrate applies or rolls back embedded sql migrations
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", migrateUsage)
+	}
+
+	command := args[0]
+	args = args[1:]
+
+	var target int64
+	if command == "to" || command == "baseline" {
+		if len(args) == 0 {
+			return fmt.Errorf("version is required\n%s", migrateUsage)
+		}
+
+		version, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q\n%s", args[0], migrateUsage)
+		}
+		target = version
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("migrate "+command, flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to the config file")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := config.MustLoadPath(*configPath)
+	log := setupLogger(cfg.Env)
+
+	db, err := postgresinit.NewPsqlDB(cfg)
+	if err != nil {
+		return fmt.Errorf("postgres init error: %w", err)
+	}
+	defer db.Close()
+
+	m, err := migrator.New(log, db, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch command {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		return m.Down(ctx)
+	case "to":
+		return m.To(ctx, target)
+	case "baseline":
+		return m.Baseline(ctx, target)
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%06d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+
+		return w.Flush()
+	}
+
+	return fmt.Errorf("unknown migrate command %q\n%s", command, migrateUsage)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"sso/internal/config"
+	postgresinit "sso/internal/lib/postgresql"
+	"sso/internal/lib/redis"
+	"sso/internal/lib/tenant"
+	"sso/internal/services/policysvc"
+	permrepo "sso/internal/storage/repository/perm_repo"
+	policyrepo "sso/internal/storage/repository/policy_repo"
+)
+
+const policyUsage = `usage:
+  sso policy export [-config path] [-tenant id] [-out file]
+  sso policy import [-config path] [-tenant id] -file file [-dry-run]`
+
+// runPolicy exports or imports RBAC model of one tenant as yaml document
+func runPolicy(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", policyUsage)
+	}
+
+	fs := flag.NewFlagSet("policy "+args[0], flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to the config file")
+	out := fs.String("out", "", "file to write exported policy, stdout by default")
+	file := fs.String("file", "", "policy file to import")
+	dryRun := fs.Bool("dry-run", false, "only print changes, do not apply them")
+	tenantID := fs.Int64("tenant", tenant.Default, "tenant whose roles and permissions are exported or imported")
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	cfg := config.MustLoadPath(*configPath)
+	log := setupLogger(cfg.Env)
+
+	db, err := postgresinit.NewPsqlDB(cfg)
+	if err != nil {
+		return fmt.Errorf("postgres init error: %w", err)
+	}
+	defer db.Close()
+
+	redisClient := redis.NewRedisClient(cfg)
+	defer redisClient.Close()
+
+	policy := policysvc.New(
+		log,
+		policyrepo.New(db),
+		permrepo.NewRedisPermRepository(redisClient, "", log),
+	)
+
+	ctx := tenant.With(context.Background(), *tenantID)
+
+	switch args[0] {
+	case "export":
+		document, err := policy.Export(ctx)
+		if err != nil {
+			return err
+		}
+
+		var w io.Writer = os.Stdout
+		if *out != "" {
+			f, err := os.Create(*out)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+
+		_, err = w.Write(document)
+		return err
+
+	case "import":
+		if *file == "" {
+			return fmt.Errorf("-file is required\n%s", policyUsage)
+		}
+
+		document, err := os.ReadFile(*file)
+		if err != nil {
+			return err
+		}
+
+		changes, err := policy.Import(ctx, document, *dryRun)
+		if err != nil {
+			return err
+		}
+
+		for _, change := range changes {
+			fmt.Println(change)
+		}
+
+		switch {
+		case len(changes) == 0:
+			log.Info("policy is up to date")
+		case *dryRun:
+			log.Info("dry run, changes were not applied", slog.Int("changes", len(changes)))
+		default:
+			log.Info("policy applied", slog.Int("changes", len(changes)))
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("unknown policy command %q\n%s", args[0], policyUsage)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sso/internal/domain/models"
+	"sso/internal/lib/appsecret"
+)
+
+// defaultSecretOverlap is how long previous secret works after rotation, apps redeploy with new one meanwhile
+const defaultSecretOverlap = 24 * time.Hour
+
+type appOutput struct {
+	ID     int             `json:"id"`
+	Name   string          `json:"name"`
+	Secret string          `json:"secret,omitempty"`
+	Tokens *appTokenOutput `json:"tokens,omitempty"`
+}
+
+// appTokenOutput shows only overridden settings, others are defaults of service
+type appTokenOutput struct {
+	AccessTTL    string   `json:"access_ttl,omitempty"`
+	RefreshTTL   string   `json:"refresh_ttl,omitempty"`
+	IdleTimeout  string   `json:"idle_timeout,omitempty"`
+	GrantTypes   []string `json:"grant_types,omitempty"`
+	Audience     string   `json:"audience,omitempty"`
+	SigningKeyID string   `json:"signing_kid,omitempty"`
+}
+
+func appsCreate(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("apps create", flag.ContinueOnError)
+	name := fs.String("name", "", "app name")
+	id := fs.Int("id", 0, "app id, next free id when empty")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return fmt.Errorf("-name is required")
+	}
+
+	secret, hash, err := appsecret.Generate()
+	if err != nil {
+		return err
+	}
+
+	created, err := e.repos.Apps.CreateApp(ctx, models.App{ID: *id, Name: *name}, hash)
+	if err != nil {
+		return err
+	}
+
+	app := appOutput{ID: created.ID, Name: created.Name, Secret: secret}
+
+	return e.out.print(app, []string{"ID", "NAME", "SECRET"}, [][]string{
+		{strconv.Itoa(app.ID), app.Name, app.Secret},
+	})
+}
+
+// appsList does not show secrets, they are printed only once on create
+func appsList(ctx context.Context, e *env, _ []string) error {
+	apps, err := e.repos.Apps.Apps(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := make([]appOutput, 0, len(apps))
+	rows := make([][]string, 0, len(apps))
+	for _, app := range apps {
+		tokens := appTokens(app.Tokens)
+		out = append(out, appOutput{ID: app.ID, Name: app.Name, Tokens: &tokens})
+		rows = append(rows, []string{
+			strconv.Itoa(app.ID), app.Name,
+			orDefault(tokens.AccessTTL), orDefault(tokens.RefreshTTL), orDefault(tokens.IdleTimeout),
+			orDefault(strings.Join(tokens.GrantTypes, ",")), tokens.Audience, tokens.SigningKeyID,
+		})
+	}
+
+	return e.out.print(out, []string{"ID", "NAME", "ACCESS TTL", "REFRESH TTL", "IDLE TIMEOUT", "GRANTS", "AUDIENCE", "KID"}, rows)
+}
+
+// appsTokens replaces token settings of app, flags which are not set reset settings to defaults of service
+func appsTokens(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("apps tokens", flag.ContinueOnError)
+	id := fs.Int("id", 0, "app id")
+	accessTTL := fs.Duration("access-ttl", 0, "lifetime of access tokens")
+	refreshTTL := fs.Duration("refresh-ttl", 0, "lifetime of refresh tokens")
+	idle := fs.Duration("idle-timeout", 0, "refresh token unused for this long expires")
+	grants := fs.String("grants", "", "comma separated grant types: password, refresh_token")
+	audience := fs.String("audience", "", "aud claim of tokens")
+	kid := fs.String("kid", "", "dedicated signing key, see keys create")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *id == 0 {
+		return fmt.Errorf("-id is required")
+	}
+	if *accessTTL < 0 || *refreshTTL < 0 || *idle < 0 {
+		return fmt.Errorf("token lifetimes must not be negative")
+	}
+	if e.cfg.MaxAccessTTL > 0 && *accessTTL > e.cfg.MaxAccessTTL {
+		return fmt.Errorf("-access-ttl is longer than max_access_ttl %s", e.cfg.MaxAccessTTL)
+	}
+
+	cfg := models.AppTokenConfig{
+		AccessTTL:    *accessTTL,
+		RefreshTTL:   *refreshTTL,
+		IdleTimeout:  *idle,
+		Audience:     *audience,
+		SigningKeyID: *kid,
+	}
+	for _, grant := range strings.Split(*grants, ",") {
+		grant = strings.TrimSpace(grant)
+		if grant == "" {
+			continue
+		}
+		if grant != models.GrantPassword && grant != models.GrantRefreshToken {
+			return fmt.Errorf("unknown grant type %q", grant)
+		}
+		cfg.GrantTypes = append(cfg.GrantTypes, grant)
+	}
+
+	if err := e.repos.Apps.SetAppTokens(ctx, *id, cfg); err != nil {
+		return err
+	}
+
+	tokens := appTokens(cfg)
+	out := appOutput{ID: *id, Tokens: &tokens}
+
+	return e.out.print(out, []string{"ID", "ACCESS TTL", "REFRESH TTL", "IDLE TIMEOUT", "GRANTS", "AUDIENCE", "KID"}, [][]string{
+		{
+			strconv.Itoa(*id),
+			orDefault(tokens.AccessTTL), orDefault(tokens.RefreshTTL), orDefault(tokens.IdleTimeout),
+			orDefault(strings.Join(tokens.GrantTypes, ",")), tokens.Audience, tokens.SigningKeyID,
+		},
+	})
+}
+
+// appsRotate generates new secret of app, it is printed only once
+func appsRotate(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("apps rotate", flag.ContinueOnError)
+	id := fs.Int("id", 0, "app id")
+	overlap := fs.Duration("overlap", defaultSecretOverlap, "how long previous secret stays valid, 0 revokes it now")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *id == 0 {
+		return fmt.Errorf("-id is required")
+	}
+
+	secret, hash, err := appsecret.Generate()
+	if err != nil {
+		return err
+	}
+
+	if _, err := e.repos.Apps.RotateSecret(ctx, *id, hash, *overlap); err != nil {
+		return err
+	}
+
+	app := appOutput{ID: *id, Secret: secret}
+
+	return e.out.print(app, []string{"ID", "SECRET"}, [][]string{
+		{strconv.Itoa(app.ID), app.Secret},
+	})
+}
+
+func appTokens(cfg models.AppTokenConfig) appTokenOutput {
+	out := appTokenOutput{
+		GrantTypes:   cfg.GrantTypes,
+		Audience:     cfg.Audience,
+		SigningKeyID: cfg.SigningKeyID,
+	}
+	if cfg.AccessTTL > 0 {
+		out.AccessTTL = cfg.AccessTTL.String()
+	}
+	if cfg.RefreshTTL > 0 {
+		out.RefreshTTL = cfg.RefreshTTL.String()
+	}
+	if cfg.IdleTimeout > 0 {
+		out.IdleTimeout = cfg.IdleTimeout.String()
+	}
+
+	return out
+}
+
+func orDefault(value string) string {
+	if value == "" {
+		return "default"
+	}
+
+	return value
+}
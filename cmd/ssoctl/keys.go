@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strconv"
+	"time"
+
+	"sso/internal/domain/models"
+)
+
+const (
+	signingSecretBytes = 64
+	kidBytes           = 8
+)
+
+type keyOutput struct {
+	ID        string     `json:"kid"`
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"created_at"`
+	RetiredAt *time.Time `json:"retired_at,omitempty"`
+}
+
+func keysList(ctx context.Context, e *env, _ []string) error {
+	keys, err := e.repos.Keys.AllSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := make([]keyOutput, 0, len(keys))
+	rows := make([][]string, 0, len(keys))
+	for _, key := range keys {
+		item := keyOutput{ID: key.ID, Active: key.Active, CreatedAt: key.CreatedAt}
+
+		retired := ""
+		if !key.RetiredAt.IsZero() {
+			item.RetiredAt = &key.RetiredAt
+			retired = key.RetiredAt.Format(time.RFC3339)
+		}
+		out = append(out, item)
+		rows = append(rows, []string{key.ID, strconv.FormatBool(key.Active), key.CreatedAt.Format(time.RFC3339), retired})
+	}
+
+	return e.out.print(out, []string{"KID", "ACTIVE", "CREATED AT", "RETIRED AT"}, rows)
+}
+
+// keysRotate makes new active key, previous key keeps verifying tokens for token_ttl
+func keysRotate(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("keys rotate", flag.ContinueOnError)
+	kid := fs.String("kid", "", "key id, generated when empty")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *kid == "" {
+		id, err := randomSecret(kidBytes)
+		if err != nil {
+			return err
+		}
+		*kid = id
+	}
+
+	secret, err := randomSecret(signingSecretBytes)
+	if err != nil {
+		return err
+	}
+
+	key, err := e.repos.Keys.RotateKey(ctx, models.SigningKey{ID: *kid, Secret: secret})
+	if err != nil {
+		return err
+	}
+
+	out := keyOutput{ID: key.ID, Active: key.Active, CreatedAt: key.CreatedAt}
+
+	return e.out.print(out, []string{"KID", "ACTIVE", "CREATED AT"}, [][]string{
+		{out.ID, strconv.FormatBool(out.Active), out.CreatedAt.Format(time.RFC3339)},
+	})
+}
+
+// keysCreate makes key which is not active, it signs tokens only of apps which use it by apps tokens -kid
+func keysCreate(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("keys create", flag.ContinueOnError)
+	kid := fs.String("kid", "", "key id, generated when empty")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *kid == "" {
+		id, err := randomSecret(kidBytes)
+		if err != nil {
+			return err
+		}
+		*kid = id
+	}
+
+	secret, err := randomSecret(signingSecretBytes)
+	if err != nil {
+		return err
+	}
+
+	key, err := e.repos.Keys.CreateKey(ctx, models.SigningKey{ID: *kid, Secret: secret})
+	if err != nil {
+		return err
+	}
+
+	out := keyOutput{ID: key.ID, Active: key.Active, CreatedAt: key.CreatedAt}
+
+	return e.out.print(out, []string{"KID", "ACTIVE", "CREATED AT"}, [][]string{
+		{out.ID, strconv.FormatBool(out.Active), out.CreatedAt.Format(time.RFC3339)},
+	})
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"sso/internal/config"
+	postgresinit "sso/internal/lib/postgresql"
+	"sso/internal/lib/tenant"
+	"sso/internal/storage/repository"
+)
+
+const usage = `usage: ssoctl [-config path] [-o table|json] [-tenant id] <command> <action> [flags]
+
+apps, users and roles commands work inside tenant, default tenant is 1
+
+commands:
+  tenants create -name name            create tenant
+  tenants list                         list tenants
+  apps create -name name [-id id]      create app with generated secret
+  apps list                            list apps with token settings
+  apps rotate -id id [-overlap d]      generate new secret, previous one works during overlap
+  apps tokens -id id [-access-ttl d] [-refresh-ttl d] [-idle-timeout d] [-grants g1,g2] [-audience a] [-kid kid]
+                                       replace token settings of app, unset flags mean defaults
+  users create -username u -email e [-password p]
+  users list                           list users
+  users passwd -email e [-password p]  set password, generated when empty
+  users admin -email e [-revoke]       mark or unmark user as admin
+  roles grant -email e -role r [-app id]
+  tokens decode token                  print header and claims without verification
+  tokens inspect token                 verify token with current keyring
+  keys list                            list signing keys
+  keys rotate [-kid id]                create new active signing key
+  keys create [-kid id]                create dedicated signing key for apps tokens -kid`
+
+// env is shared state of every command
+type env struct {
+	cfg   *config.Config
+	repos *repository.Repositories
+	out   *printer
+}
+
+type command func(ctx context.Context, e *env, args []string) error
+
+var commands = map[string]map[string]command{
+	"tenants": {
+		"create": tenantsCreate,
+		"list":   tenantsList,
+	},
+	"apps": {
+		"create": appsCreate,
+		"list":   appsList,
+		"tokens": appsTokens,
+		"rotate": appsRotate,
+	},
+	"users": {
+		"create": usersCreate,
+		"list":   usersList,
+		"passwd": usersPasswd,
+		"admin":  usersAdmin,
+	},
+	"roles": {
+		"grant": rolesGrant,
+	},
+	"tokens": {
+		"decode":  tokensDecode,
+		"inspect": tokensInspect,
+	},
+	"keys": {
+		"list":   keysList,
+		"rotate": keysRotate,
+		"create": keysCreate,
+	},
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("ssoctl", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to the config file")
+	format := fs.String("o", formatTable, "output format, table or json")
+	tenantID := fs.Int64("tenant", tenant.Default, "tenant of apps, users and roles")
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() < 2 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	cmd, ok := commands[fs.Arg(0)][fs.Arg(1)]
+	if !ok {
+		return fmt.Errorf("unknown command %q %q\n%s", fs.Arg(0), fs.Arg(1), usage)
+	}
+
+	out, err := newPrinter(*format, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	// tokens decode works without database
+	if fs.Arg(0) == "tokens" && fs.Arg(1) == "decode" {
+		return cmd(context.Background(), &env{out: out}, fs.Args()[2:])
+	}
+
+	cfg := config.MustLoadPath(*configPath)
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	db, err := postgresinit.NewPsqlDB(cfg)
+	if err != nil {
+		return fmt.Errorf("postgres init error: %w", err)
+	}
+	defer db.Close()
+
+	e := &env{
+		cfg:   cfg,
+		repos: repository.New(db),
+		out:   out,
+	}
+
+	return cmd(tenant.With(context.Background(), *tenantID), e, fs.Args()[2:])
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+type roleGrantOutput struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+	AppID int64  `json:"app_id"`
+}
+
+func rolesGrant(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("roles grant", flag.ContinueOnError)
+	email := fs.String("email", "", "email")
+	role := fs.String("role", "", "role name")
+	appID := fs.Int64("app", 0, "app id, 0 grants role in every app")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *email == "" || *role == "" {
+		return fmt.Errorf("-email and -role are required")
+	}
+
+	if err := e.repos.Admin.GrantRole(ctx, *email, *role, *appID); err != nil {
+		return err
+	}
+
+	grant := roleGrantOutput{Email: *email, Role: *role, AppID: *appID}
+
+	return e.out.print(grant, []string{"EMAIL", "ROLE", "APP"}, [][]string{
+		{grant.Email, grant.Role, strconv.FormatInt(grant.AppID, 10)},
+	})
+}
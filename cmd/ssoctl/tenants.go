@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type tenantOutput struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func tenantsCreate(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("tenants create", flag.ContinueOnError)
+	name := fs.String("name", "", "tenant name")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return fmt.Errorf("-name is required")
+	}
+
+	created, err := e.repos.Tenants.CreateTenant(ctx, *name)
+	if err != nil {
+		return err
+	}
+
+	out := tenantOutput{ID: created.ID, Name: created.Name, CreatedAt: created.CreatedAt}
+
+	return e.out.print(out, []string{"ID", "NAME", "CREATED AT"}, [][]string{
+		{strconv.FormatInt(out.ID, 10), out.Name, out.CreatedAt.Format(time.RFC3339)},
+	})
+}
+
+func tenantsList(ctx context.Context, e *env, _ []string) error {
+	tenants, err := e.repos.Tenants.Tenants(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := make([]tenantOutput, 0, len(tenants))
+	rows := make([][]string, 0, len(tenants))
+	for _, t := range tenants {
+		out = append(out, tenantOutput{ID: t.ID, Name: t.Name, CreatedAt: t.CreatedAt})
+		rows = append(rows, []string{strconv.FormatInt(t.ID, 10), t.Name, t.CreatedAt.Format(time.RFC3339)})
+	}
+
+	return e.out.print(out, []string{"ID", "NAME", "CREATED AT"}, rows)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	jwtlib "sso/internal/lib/jwt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type tokenOutput struct {
+	Header map[string]any `json:"header"`
+	Claims jwt.MapClaims  `json:"claims"`
+	Valid  *bool          `json:"valid,omitempty"`
+	UserID string         `json:"user_id,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+func tokensDecode(_ context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: ssoctl tokens decode token")
+	}
+
+	out, err := decodeToken(args[0])
+	if err != nil {
+		return err
+	}
+
+	return e.out.print(out, []string{"FIELD", "VALUE"}, tokenRows(out))
+}
+
+func tokensInspect(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: ssoctl tokens inspect token")
+	}
+
+	out, err := decodeToken(args[0])
+	if err != nil {
+		return err
+	}
+
+	validator, err := jwtlib.NewService(os.Getenv("JWT_SECRET"))
+	if err != nil {
+		return err
+	}
+
+	if err := validator.LoadKeys(ctx, e.repos.Keys, e.cfg.TokenTTL); err != nil {
+		return err
+	}
+
+	valid := true
+	out.UserID, err = validator.ValidateToken(ctx, args[0])
+	if err != nil {
+		valid = false
+		out.Error = err.Error()
+	}
+	out.Valid = &valid
+
+	rows := append(tokenRows(out),
+		[]string{"valid", fmt.Sprint(valid)},
+		[]string{"user_id", out.UserID},
+		[]string{"error", out.Error},
+	)
+
+	return e.out.print(out, []string{"FIELD", "VALUE"}, rows)
+}
+
+func decodeToken(token string) (tokenOutput, error) {
+	claims := jwt.MapClaims{}
+
+	t, _, err := jwt.NewParser().ParseUnverified(token, claims)
+	if err != nil {
+		return tokenOutput{}, fmt.Errorf("cannot decode token: %w", err)
+	}
+
+	return tokenOutput{Header: t.Header, Claims: claims}, nil
+}
+
+// tokenRows flattens header and claims, unix times are printed as dates
+func tokenRows(out tokenOutput) [][]string {
+	var rows [][]string
+
+	for _, key := range sortedKeys(out.Header) {
+		rows = append(rows, []string{"header." + key, fmt.Sprint(out.Header[key])})
+	}
+
+	for _, key := range sortedKeys(out.Claims) {
+		value := fmt.Sprint(out.Claims[key])
+		if unix, ok := out.Claims[key].(float64); ok && (key == "exp" || key == "iat" || key == "nbf") {
+			value = time.Unix(int64(unix), 0).UTC().Format(time.RFC3339)
+		}
+		rows = append(rows, []string{"claims." + key, value})
+	}
+
+	return rows
+}
+
+func sortedKeys[M ~map[string]any](m M) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
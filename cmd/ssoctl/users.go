@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const generatedPasswordBytes = 12
+
+type userOutput struct {
+	ID        int64      `json:"id"`
+	Username  string     `json:"username"`
+	Email     string     `json:"email"`
+	IsAdmin   bool       `json:"is_admin"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Password  string     `json:"password,omitempty"`
+}
+
+func usersCreate(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("users create", flag.ContinueOnError)
+	username := fs.String("username", "", "username")
+	email := fs.String("email", "", "email")
+	password := fs.String("password", "", "password, generated when empty")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *username == "" || *email == "" {
+		return fmt.Errorf("-username and -email are required")
+	}
+
+	pass, generated, err := passwordOrRandom(*password)
+	if err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(pass), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	id, err := e.repos.Users.SaveUser(ctx, *username, *email, hash)
+	if err != nil {
+		return err
+	}
+
+	user := userOutput{ID: id, Username: *username, Email: *email}
+	if generated {
+		user.Password = pass
+	}
+
+	return e.out.print(user, []string{"ID", "USERNAME", "EMAIL", "PASSWORD"}, [][]string{
+		{strconv.FormatInt(id, 10), user.Username, user.Email, user.Password},
+	})
+}
+
+func usersList(ctx context.Context, e *env, _ []string) error {
+	users, err := e.repos.Admin.Users(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := make([]userOutput, 0, len(users))
+	rows := make([][]string, 0, len(users))
+	for _, u := range users {
+		out = append(out, userOutput{ID: u.ID, Username: u.Username, Email: u.Email, IsAdmin: u.IsAdmin, CreatedAt: &u.CreatedAt})
+		rows = append(rows, []string{
+			strconv.FormatInt(u.ID, 10),
+			u.Username,
+			u.Email,
+			strconv.FormatBool(u.IsAdmin),
+			u.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return e.out.print(out, []string{"ID", "USERNAME", "EMAIL", "ADMIN", "CREATED AT"}, rows)
+}
+
+func usersPasswd(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("users passwd", flag.ContinueOnError)
+	email := fs.String("email", "", "email")
+	password := fs.String("password", "", "new password, generated when empty")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *email == "" {
+		return fmt.Errorf("-email is required")
+	}
+
+	pass, generated, err := passwordOrRandom(*password)
+	if err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(pass), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := e.repos.Admin.SetPassword(ctx, *email, hash); err != nil {
+		return err
+	}
+
+	user := userOutput{Email: *email}
+	if generated {
+		user.Password = pass
+	}
+
+	return e.out.print(user, []string{"EMAIL", "PASSWORD"}, [][]string{{user.Email, user.Password}})
+}
+
+func usersAdmin(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("users admin", flag.ContinueOnError)
+	email := fs.String("email", "", "email")
+	revoke := fs.Bool("revoke", false, "remove admin rights")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *email == "" {
+		return fmt.Errorf("-email is required")
+	}
+
+	if err := e.repos.Admin.SetAdmin(ctx, *email, !*revoke); err != nil {
+		return err
+	}
+
+	user := userOutput{Email: *email, IsAdmin: !*revoke}
+
+	return e.out.print(user, []string{"EMAIL", "ADMIN"}, [][]string{{user.Email, strconv.FormatBool(user.IsAdmin)}})
+}
+
+func passwordOrRandom(password string) (pass string, generated bool, err error) {
+	if password != "" {
+		return password, false, nil
+	}
+
+	pass, err = randomSecret(generatedPasswordBytes)
+
+	return pass, true, err
+}
@@ -1,38 +1,255 @@
 package app
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
+	gatewayapp "sso/internal/app/gateway"
 	grpcapp "sso/internal/app/grpc"
 	"sso/internal/config"
+	"sso/internal/lib/certs"
+	"sso/internal/lib/clientinfo"
 	jwtlib "sso/internal/lib/jwt"
+	"sso/internal/lib/mailer"
+	"sso/internal/lib/metric"
+	"sso/internal/lib/passhash"
+	redislib "sso/internal/lib/redis"
+	"sso/internal/services/appsvc"
 	"sso/internal/services/authsvc"
-	userrepository "sso/internal/storage/repository/auth_repo"
+	"sso/internal/services/groupsvc"
+	"sso/internal/services/permsvc"
+	"sso/internal/services/policysvc"
+	"sso/internal/services/profilesvc"
+	"sso/internal/services/statsvc"
+	"sso/internal/storage/repository"
+	permrepo "sso/internal/storage/repository/perm_repo"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 )
 
+// ErrGatewayClientApp is returned when certificate of gateway is mapped to app by grpc.tls.client_apps
+var ErrGatewayClientApp = errors.New("gateway client certificate cannot authenticate as app")
+
 type App struct {
+	log        *slog.Logger
 	GRPCServer *grpcapp.App
+	Gateway    *gatewayapp.App // nil when gateway is disabled
+	redis      *redis.Client
+	stopKeys   context.CancelFunc
+	stopCerts  context.CancelFunc
+	gwTimeout  time.Duration
 }
 
-func New(log *slog.Logger, grpcPort int, postgres *config.PostgresConfig, tokenTTL time.Duration, db *pgxpool.Pool) *App {
-	//init storageg
-	dsn := os.Getenv("POSTGRES_URL")
-	storage, err := userrepository.New(dsn)
+// New builds repositories, services and gRPC server on shared postgres pool and redis client
+func New(log *slog.Logger, cfg *config.Config, db *pgxpool.Pool) *App {
+	secret := os.Getenv("JWT_SECRET")
+	tokengen, err := jwtlib.NewService(secret)
+	if err != nil {
+		panic(err)
+	}
+
+	redisClient := redislib.NewRedisClient(cfg)
+
+	//init storage
+	repos := repository.New(db)
+
+	//signing keys from database, JWT_SECRET is used while there is no active key,
+	//retired keys verify tokens until the longest access token signed by them expires
+	keyGrace := max(cfg.TokenTTL, cfg.MaxAccessTTL)
+	if err := tokengen.LoadKeys(context.Background(), repos.Keys, keyGrace); err != nil {
+		log.Warn("cannot load signing keys", slog.Any("err", err))
+	}
+
+	keysCtx, stopKeys := context.WithCancel(context.Background())
+	go tokengen.WatchKeys(keysCtx, log, repos.Keys, keyGrace, cfg.KeysRefresh)
+	userRepo := repos.Users
+	permRepo := repos.Permissions
+	statRepo := repos.Status
+	permCache := permrepo.NewRedisPermRepository(redisClient, "", log)
+
+	metrics, err := metric.CreateMetrics(cfg.Metrics.Url, cfg.Metrics.ServiceName)
+	if err != nil {
+		panic(err)
+	}
+
+	hasher := passhash.New(metrics)
+
+	//init services
+	tokenOpts := authsvc.TokenOptions{
+		Roles:        cfg.TokenClaims.Roles,
+		Permissions:  cfg.TokenClaims.Permissions,
+		MaxSize:      cfg.TokenClaims.MaxSize,
+		MaxAccessTTL: cfg.MaxAccessTTL,
+	}
+	authService := authsvc.New(log, userRepo, userRepo, userRepo, tokengen, userRepo, userRepo, statRepo, permRepo,
+		hasher, metrics, cfg.TokenTTL, tokenOpts)
+
+	proxies, err := clientinfo.ParseProxies(cfg.GRPC.TrustedProxies)
 	if err != nil {
 		panic(err)
 	}
-	secret := os.Getenv("SECRET_JWT")
-	tokengen, err := jwtlib.NewService(secret)
-	//init auth service(auth)
 
-	authService := authsvc.New(log, storage, storage, storage, tokengen, storage, tokenTTL)
+	services := grpcapp.Services{
+		Admins:   authService,
+		AppAuth:  authService,
+		Sessions: authService,
+		Proxies:  proxies,
+	}
+
+	if cfg.Services.Auth.Enabled {
+		services.Auth = authService
+	}
+
+	if cfg.Services.Permissions.Enabled {
+		services.Permissions = permsvc.New(log, permRepo, permRepo, permRepo, permCache, metrics)
+		services.Policy = policysvc.New(log, repos.Policy, permCache)
+	}
+
+	if cfg.Services.Status.Enabled {
+		services.Status = statsvc.New(log, statRepo, statRepo)
+	}
+
+	if cfg.Services.Apps.Enabled {
+		services.Apps = appsvc.New(log, repos.Apps, cfg.MaxAccessTTL)
+	}
+
+	if cfg.Services.Groups.Enabled {
+		services.Groups = groupsvc.New(log, repos.Groups, permCache)
+	}
+
+	if cfg.Services.Profile.Enabled {
+		var mail profilesvc.MailSender = mailer.NewLog(log)
+		if cfg.Mail.SMTPAddr != "" {
+			mail = mailer.NewSMTP(cfg.Mail.SMTPAddr, cfg.Mail.From, cfg.Mail.Username, cfg.Mail.Password)
+		} else if cfg.Env != "local" {
+			log.Warn("smtp is not configured, email codes are written to log")
+		}
+		services.Profile = profilesvc.New(log, repos.Profiles, hasher, authService, mail, cfg.Mail.VerificationTTL)
+	}
+
+	health := grpcapp.HealthOptions{
+		Interval:      cfg.GRPC.HealthInterval,
+		ShutdownDelay: cfg.GRPC.ShutdownDelay,
+		Dependencies: []grpcapp.Dependency{
+			{Name: "postgres", Ping: db.Ping},
+			{Name: "redis", Ping: func(ctx context.Context) error {
+				return redisClient.Ping(ctx).Err()
+			}},
+		},
+	}
 
-	grpcApp := grpcapp.New(log, grpcPort, authService)
+	certsCtx, stopCerts := context.WithCancel(context.Background())
+
+	serverTLS, gatewayTLS, err := newTLS(certsCtx, log, cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	grpcApp := grpcapp.New(log, cfg.GRPC.Port, tokengen, metrics, services, health, grpcapp.TLSOptions{
+		Config:     serverTLS,
+		ClientApps: cfg.GRPC.TLS.ClientApps,
+	})
+
+	var gateway *gatewayapp.App
+	if cfg.Gateway.Enabled {
+		gateway, err = gatewayapp.New(log, cfg.Gateway.Port, fmt.Sprintf("localhost:%d", cfg.GRPC.Port), cfg.Gateway.Timeout, gatewayTLS, gatewayapp.Services{
+			Auth:        services.Auth != nil,
+			Permissions: services.Permissions != nil,
+			Status:      services.Status != nil,
+			Apps:        services.Apps != nil,
+			Groups:      services.Groups != nil,
+			Profile:     services.Profile != nil,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
 
 	return &App{
+		log:        log,
 		GRPCServer: grpcApp,
+		Gateway:    gateway,
+		redis:      redisClient,
+		stopKeys:   stopKeys,
+		stopCerts:  stopCerts,
+		gwTimeout:  cfg.Gateway.Timeout,
+	}
+}
+
+// Stop stops http gateway, gRPC server and closes redis client
+func (a *App) Stop() {
+	if a.Gateway != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), a.gwTimeout)
+		a.Gateway.Stop(ctx)
+		cancel()
+	}
+
+	a.GRPCServer.Stop()
+	a.stopKeys()
+	a.stopCerts()
+
+	if err := a.redis.Close(); err != nil {
+		a.log.Warn("cannot close redis client", slog.Any("err", err))
+	}
+}
+
+// newTLS builds TLS configs of gRPC listener and of gateway dialing it, both are nil when TLS is disabled.
+// Certificate files are watched until ctx is done
+func newTLS(ctx context.Context, log *slog.Logger, cfg *config.Config) (*tls.Config, *tls.Config, error) {
+	const op = "app.newTLS"
+
+	tlsCfg := cfg.GRPC.TLS
+	if !tlsCfg.Enabled {
+		return nil, nil, nil
 	}
+	if tlsCfg.ReloadInterval <= 0 {
+		return nil, nil, fmt.Errorf("%s:%w: %s", op, certs.ErrInvalidReloadInterval, tlsCfg.ReloadInterval)
+	}
+
+	serverCert, err := certs.NewReloader(log, tlsCfg.CertFile, tlsCfg.KeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s:%w", op, err)
+	}
+	go serverCert.Watch(ctx, tlsCfg.ReloadInterval)
+
+	serverTLS, err := certs.ServerConfig(serverCert, tlsCfg.ClientCAFile, tlsCfg.RequireClientCert)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	gwCfg := cfg.Gateway.TLS
+
+	var gatewayCert *certs.Reloader
+	if gwCfg.CertFile != "" {
+		gatewayCert, err = certs.NewReloader(log, gwCfg.CertFile, gwCfg.KeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s:%w", op, err)
+		}
+		// gateway proxies requests of anyone who reaches HTTP port, they must not act as app
+		cn, err := gatewayCert.CommonName()
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s:%w", op, err)
+		}
+		if _, ok := tlsCfg.ClientApps[cn]; ok {
+			return nil, nil, fmt.Errorf("%s:%w: %q", op, ErrGatewayClientApp, cn)
+		}
+		go gatewayCert.Watch(ctx, tlsCfg.ReloadInterval)
+	}
+
+	serverName := gwCfg.ServerName
+	if serverName == "" {
+		serverName = "localhost"
+	}
+
+	gatewayTLS, err := certs.ClientConfig(gwCfg.CAFile, serverName, gatewayCert)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return serverTLS, gatewayTLS, nil
 }
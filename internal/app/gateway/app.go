@@ -0,0 +1,173 @@
+package gatewayapp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"sso/proto/generated/appgen"
+	"sso/proto/generated/augen"
+	"sso/proto/generated/grpgen"
+	"sso/proto/generated/permgen"
+	"sso/proto/generated/profgen"
+	"sso/proto/generated/stagen"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// App is HTTP/JSON front of gRPC services, it runs in the same process and proxies calls to gRPC port
+type App struct {
+	log    *slog.Logger
+	server *http.Server
+	port   int
+	// conn is used by handlers which are not generated from proto, nil when they are disabled
+	conn *grpc.ClientConn
+}
+
+// Services which have routes on gateway, they have to be registered on gRPC server as well
+type Services struct {
+	Auth        bool
+	Permissions bool
+	Status      bool
+	Apps        bool
+	Groups      bool
+	Profile     bool
+}
+
+// New dials gRPC port with tlsConfig, nil tlsConfig means plain TCP
+func New(log *slog.Logger, port int, grpcAddr string, timeout time.Duration, tlsConfig *tls.Config, services Services) (*App, error) {
+	const op = "gatewayapp.New"
+
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(headerMatcher),
+		runtime.WithErrorHandler(errorHandler),
+	)
+
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	}
+
+	type registerFunc func(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error
+
+	handlers := []struct {
+		enabled  bool
+		register registerFunc
+	}{
+		{services.Auth, augen.RegisterAuthHandlerFromEndpoint},
+		{services.Permissions, permgen.RegisterPermissionsHandlerFromEndpoint},
+		{services.Status, stagen.RegisterStatusHandlerFromEndpoint},
+		{services.Apps, appgen.RegisterAppsHandlerFromEndpoint},
+		{services.Groups, grpgen.RegisterGroupsHandlerFromEndpoint},
+		{services.Profile, profgen.RegisterProfileHandlerFromEndpoint},
+	}
+
+	for _, h := range handlers {
+		if !h.enabled {
+			continue
+		}
+		if err := h.register(context.Background(), mux, grpcAddr, opts); err != nil {
+			return nil, fmt.Errorf("%s:%w", op, err)
+		}
+	}
+
+	var conn *grpc.ClientConn
+	if services.Auth {
+		var err error
+
+		conn, err = grpc.NewClient(grpcAddr, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%w", op, err)
+		}
+
+		introspect := introspectHandler(augen.NewAuthClient(conn))
+		if err := mux.HandlePath(http.MethodPost, "/introspect", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+			introspect(w, r)
+		}); err != nil {
+			conn.Close()
+
+			return nil, fmt.Errorf("%s:%w", op, err)
+		}
+	}
+
+	return &App{
+		log:  log,
+		conn: conn,
+		server: &http.Server{
+			Addr:              fmt.Sprintf(":%d", port),
+			Handler:           withTraceContext(mux),
+			ReadHeaderTimeout: timeout,
+		},
+		port: port,
+	}, nil
+}
+
+// withTraceContext continues trace of HTTP caller, so gRPC client span of gateway joins it
+func withTraceContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Runs gateway without error handler to user
+func (a *App) MustRun() {
+	if err := a.Run(); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) Run() error {
+	const op = "gatewayapp.Run"
+
+	log := a.log.With(slog.String("op", op), slog.Int("port", a.port))
+
+	l, err := net.Listen("tcp", a.server.Addr)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("http gateway is running", slog.String("addr", l.Addr().String()))
+
+	if err := a.server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+// Stop waits for active requests until ctx is done
+func (a *App) Stop(ctx context.Context) {
+	const op = "gatewayapp.Stop"
+
+	log := a.log.With(slog.String("op", op))
+
+	log.Info("http gateway stops serve")
+
+	if err := a.server.Shutdown(ctx); err != nil {
+		log.Warn("http gateway was not stopped gracefully", slog.Any("err", err))
+	}
+
+	if a.conn != nil {
+		if err := a.conn.Close(); err != nil {
+			log.Warn("cannot close gRPC connection", slog.Any("err", err))
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package gatewayapp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/grpc/status"
+)
+
+// errorBody is returned on every failed request, code is gRPC status code
+type errorBody struct {
+	Error errorDetails `json:"error"`
+}
+
+type errorDetails struct {
+	Code    int    `json:"code"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// errorHandler writes gRPC error as json body with http status mapped from gRPC code
+func errorHandler(_ context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, _ *http.Request, err error) {
+	st := status.Convert(err)
+
+	body := errorBody{
+		Error: errorDetails{
+			Code:    int(st.Code()),
+			Status:  code.Code(st.Code()).String(),
+			Message: st.Message(),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(runtime.HTTPStatusFromCode(st.Code()))
+
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// headerMatcher passes credentials and request id headers as is, so gRPC interceptors see the same metadata
+func headerMatcher(key string) (string, bool) {
+	switch strings.ToLower(key) {
+	case "authorization", "x-app-id", "x-app-secret", "x-request-id":
+		return strings.ToLower(key), true
+	}
+
+	return runtime.DefaultHeaderMatcher(key)
+}
@@ -0,0 +1,122 @@
+package gatewayapp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"sso/internal/domain/models"
+	"sso/proto/generated/augen"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// introspection is token introspection response of RFC 7662, inactive token has only active field
+type introspection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+	// extensions of sso, present when token carries them
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	TenantID    int64    `json:"tenant_id,omitempty"`
+}
+
+// oauthError is error response of RFC 6749 which RFC 7662 clients expect
+type oauthError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// introspectHandler serves POST /introspect, app authenticates with HTTP basic auth
+// where client_id is id of app and client_secret is its secret
+func introspectHandler(client augen.AuthClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request", "malformed form body")
+
+			return
+		}
+
+		token := r.PostForm.Get("token")
+		if token == "" {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request", "token is required")
+
+			return
+		}
+
+		appID, secret, ok := r.BasicAuth()
+		if !ok {
+			appID, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+		}
+		if appID == "" || secret == "" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="sso"`)
+			writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "client authentication required")
+
+			return
+		}
+
+		ctx := metadata.AppendToOutgoingContext(r.Context(), "x-app-id", appID, "x-app-secret", secret)
+
+		resp, err := client.Introspect(ctx, &augen.IntrospectRequest{Token: token})
+		if err != nil {
+			switch status.Code(err) {
+			case codes.Unauthenticated, codes.PermissionDenied:
+				w.Header().Set("WWW-Authenticate", `Basic realm="sso"`)
+				writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "")
+			default:
+				writeOAuthError(w, http.StatusInternalServerError, "server_error", "")
+			}
+
+			return
+		}
+
+		body := introspection{Active: resp.Active}
+		if resp.Active {
+			body.Scope = strings.Join(resp.Scopes, " ")
+			body.ClientID = strconv.FormatUint(resp.AppId, 10)
+			body.Username = resp.Email
+			body.TokenType = oauthTokenType(resp.TokenType)
+			body.Exp = resp.Exp
+			body.Iat = resp.Iat
+			body.Sub = strconv.FormatInt(resp.Sub, 10)
+			body.Jti = resp.Jti
+			body.Roles = resp.Roles
+			body.Permissions = resp.Permissions
+			body.TenantID = resp.TenantId
+		}
+
+		writeJSON(w, http.StatusOK, body)
+	}
+}
+
+// oauthTokenType maps type of sso token to token type of RFC 6749, access tokens of sso are bearer tokens
+func oauthTokenType(tokenType string) string {
+	if tokenType == models.TokenTypeAccess {
+		return "Bearer"
+	}
+
+	return ""
+}
+
+func writeOAuthError(w http.ResponseWriter, code int, kind, description string) {
+	writeJSON(w, code, oauthError{Error: kind, Description: description})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	// introspection result must not outlive revocation in caches
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+
+	_ = json.NewEncoder(w).Encode(body)
+}
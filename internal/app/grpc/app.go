@@ -1,53 +1,167 @@
 package grpcapp
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"log/slog"
 	"net"
-	"os"
+	appsgrpc "sso/internal/grpc/apps"
 	authgrpc "sso/internal/grpc/auth"
+	groupsgrpc "sso/internal/grpc/groups"
+	permgrpc "sso/internal/grpc/permissions"
+	profilegrpc "sso/internal/grpc/profile"
+	statusgrpc "sso/internal/grpc/status"
 	"sso/internal/interceptors"
-	jwtlib "sso/internal/lib/jwt"
-
+	"sso/internal/lib/clientinfo"
+	"sso/internal/lib/metric"
+	"sso/proto/generated/appgen"
+	"sso/proto/generated/augen"
+	"sso/proto/generated/grpgen"
+	"sso/proto/generated/permgen"
+	"sso/proto/generated/profgen"
+	"sso/proto/generated/stagen"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 type App struct {
-	log        *slog.Logger
-	gRPCServer *grpc.Server
-	port       int
+	log          *slog.Logger
+	gRPCServer   *grpc.Server
+	port         int
+	health       *health.Server
+	stopChecker  context.CancelFunc
+	shutdownWait time.Duration
 }
 
-func New(log *slog.Logger, port int, authService authgrpc.AuthS) *App {
+// TLSOptions of listener, nil Config means plain TCP
+type TLSOptions struct {
+	Config *tls.Config
+	// common name of verified client certificate to app id
+	ClientApps map[string]int64
+}
 
-	jwt_new, ok := os.LookupEnv("JWT_SECRET")
-	if !ok {
-		log.Warn("cannot fint jwt secret variable")
+// Services which will be registered on gRPC server, nil service is not registered
+type Services struct {
+	Auth        authgrpc.AuthS
+	Permissions permgrpc.PermService
+	Policy      permgrpc.PolicyService
+	Status      statusgrpc.StatusSvc
+	Apps        appsgrpc.AppService
+	Groups      groupsgrpc.GroupService
+	Profile     profilegrpc.ProfileService
+	Admins      permgrpc.AdminChecker
+	AppAuth     interceptors.AppAuthenticator
+	Sessions    interceptors.SessionChecker
+	// proxies which are trusted to report address of client
+	Proxies clientinfo.Proxies
+}
+
+func New(
+	log *slog.Logger,
+	port int,
+	validator interceptors.Validator,
+	metrics metric.Metrics,
+	services Services,
+	healthOpts HealthOptions,
+	tlsOpts TLSOptions,
+) *App {
+	interceptor, err := interceptors.NewAuthInterceptor(validator, services.Admins, services.AppAuth, services.Sessions, methodPolicy, tlsOpts.ClientApps)
+	if err != nil {
+		log.Warn("cannot define interceptors.NewAuthInterceptor")
 
 		return nil
 	}
 
-	authSvc, err := jwtlib.NewService(jwt_new)
-	if err != nil {
-		log.Warn("cannot register new service")
+	logging := interceptors.NewLogging(log)
+	recovery := interceptors.NewRecovery(log)
+	red := interceptors.NewMetrics(metrics)
+
+	// otelgrpc continues trace from incoming metadata before interceptors are called,
+	// recovery goes after logging and metrics, so panics are logged and counted as Internal
+	opts := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			interceptors.UnaryRequestID,
+			logging.Unary,
+			red.Unary,
+			recovery.Unary,
+			interceptor.UnaryAuthInterceptor,
+		),
+		grpc.ChainStreamInterceptor(
+			interceptors.StreamRequestID,
+			logging.Stream,
+			red.Stream,
+			recovery.Stream,
+			interceptor.StreamAuthInterceptor,
+		),
+	}
+	if tlsOpts.Config != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsOpts.Config)))
+	}
 
-		return nil
+	gRPCServer := grpc.NewServer(opts...)
+
+	var registered []string
+
+	if services.Auth != nil {
+		authgrpc.Register(gRPCServer, services.Auth, services.Proxies)
+		registered = append(registered, augen.Auth_ServiceDesc.ServiceName)
+		log.Info("auth service registered")
 	}
 
-	interceptor, err := interceptors.NewAuthInterceptor(authSvc)
-	if err != nil {
-		log.Warn("cannot define interceptors.NewAuthInterceptor")
+	if services.Permissions != nil {
+		permgrpc.Register(gRPCServer, services.Permissions, services.Policy, services.Admins)
+		registered = append(registered, permgen.Permissions_ServiceDesc.ServiceName)
+		log.Info("permissions service registered")
+	}
 
-		return nil
+	if services.Status != nil {
+		statusgrpc.Register(gRPCServer, services.Status, services.Admins)
+		registered = append(registered, stagen.Status_ServiceDesc.ServiceName)
+		log.Info("status service registered")
+	}
+
+	if services.Apps != nil {
+		appsgrpc.Register(gRPCServer, services.Apps)
+		registered = append(registered, appgen.Apps_ServiceDesc.ServiceName)
+		log.Info("apps service registered")
+	}
+
+	if services.Groups != nil {
+		groupsgrpc.Register(gRPCServer, services.Groups, services.Admins)
+		registered = append(registered, grpgen.Groups_ServiceDesc.ServiceName)
+		log.Info("groups service registered")
+	}
+
+	if services.Profile != nil {
+		profilegrpc.Register(gRPCServer, services.Profile)
+		registered = append(registered, profgen.Profile_ServiceDesc.ServiceName)
+		log.Info("profile service registered")
 	}
-	gRPCServer := grpc.NewServer(grpc.UnaryInterceptor(interceptor.UnaryAuthInterceptor))
 
-	authgrpc.Register(gRPCServer, authService)
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(gRPCServer, healthServer)
+
+	checker := newHealthChecker(log, healthServer, registered, healthOpts)
+	// services are not serving until first check of dependencies
+	checker.setStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	ctx, stopChecker := context.WithCancel(context.Background())
+	go checker.run(ctx)
 
 	return &App{
-		log:        log,
-		gRPCServer: gRPCServer,
-		port:       port,
+		log:          log,
+		gRPCServer:   gRPCServer,
+		port:         port,
+		health:       healthServer,
+		stopChecker:  stopChecker,
+		shutdownWait: healthOpts.ShutdownDelay,
 	}
 }
 
@@ -82,7 +196,18 @@ func (a *App) Run() error {
 func (a *App) Stop() {
 	const op = "grpcapp.Stop"
 
-	a.log.With(slog.String("op", op)).Info("grpc server stops serve")
+	log := a.log.With(slog.String("op", op))
+
+	a.stopChecker()
+
+	// clients and load balancers see NOT_SERVING and drain before connections are closed
+	a.health.Shutdown()
+	if a.shutdownWait > 0 {
+		log.Info("waiting for clients to drain", slog.Duration("delay", a.shutdownWait))
+		time.Sleep(a.shutdownWait)
+	}
+
+	log.Info("grpc server stops serve")
 
 	a.gRPCServer.GracefulStop()
 }
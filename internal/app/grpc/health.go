@@ -0,0 +1,100 @@
+package grpcapp
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Dependency is an external resource which has to be reachable for services to serve
+type Dependency struct {
+	Name string
+	Ping func(ctx context.Context) error
+}
+
+type HealthOptions struct {
+	// how often dependencies are pinged
+	Interval time.Duration
+	// how long server reports NOT_SERVING before graceful stop, so load balancers drain connections
+	ShutdownDelay time.Duration
+	Dependencies  []Dependency
+}
+
+// healthChecker flips statuses of registered services by state of dependencies
+type healthChecker struct {
+	log      *slog.Logger
+	server   *health.Server
+	services []string
+	opts     HealthOptions
+}
+
+func newHealthChecker(log *slog.Logger, server *health.Server, services []string, opts HealthOptions) *healthChecker {
+	return &healthChecker{
+		log:      log,
+		server:   server,
+		services: services,
+		opts:     opts,
+	}
+}
+
+// run checks dependencies at once and then every interval until ctx is done
+func (h *healthChecker) run(ctx context.Context) {
+	h.check(ctx)
+
+	if h.opts.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(h.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.check(ctx)
+		}
+	}
+}
+
+func (h *healthChecker) check(ctx context.Context) {
+	const op = "grpcapp.healthChecker.check"
+
+	status := healthpb.HealthCheckResponse_SERVING
+
+	for _, dep := range h.opts.Dependencies {
+		pingCtx, cancel := context.WithTimeout(ctx, h.pingTimeout())
+		err := dep.Ping(pingCtx)
+		cancel()
+
+		if err != nil {
+			h.log.With(slog.String("op", op)).Warn("dependency is down", slog.String("dependency", dep.Name), slog.Any("err", err))
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+
+	h.setStatus(status)
+}
+
+func (h *healthChecker) setStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	// empty name is overall status of server
+	h.server.SetServingStatus("", status)
+
+	for _, service := range h.services {
+		h.server.SetServingStatus(service, status)
+	}
+}
+
+func (h *healthChecker) pingTimeout() time.Duration {
+	const defaultTimeout = 2 * time.Second
+
+	if h.opts.Interval > 0 && h.opts.Interval < defaultTimeout {
+		return h.opts.Interval
+	}
+
+	return defaultTimeout
+}
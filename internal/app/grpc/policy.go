@@ -0,0 +1,72 @@
+package grpcapp
+
+import (
+	"sso/internal/interceptors"
+	"sso/proto/generated/appgen"
+	"sso/proto/generated/augen"
+	"sso/proto/generated/grpgen"
+	"sso/proto/generated/permgen"
+	"sso/proto/generated/profgen"
+	"sso/proto/generated/stagen"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// methodPolicy declares who can call every method, methods which are not listed here are denied.
+// Handlers of authenticated methods check themselves that users query only their own data
+var methodPolicy = interceptors.MethodPolicy{
+	// users have no token before login
+	augen.Auth_Register_FullMethodName: interceptors.AccessPublic,
+	augen.Auth_Login_FullMethodName:    interceptors.AccessPublic,
+	// app backends ask whether their user is admin
+	augen.Auth_IsAdmin_FullMethodName: interceptors.AccessApp,
+	// resource servers check tokens of their users
+	augen.Auth_Introspect_FullMethodName: interceptors.AccessApp,
+
+	permgen.Permissions_DeleteUser_FullMethodName:    interceptors.AccessAuthenticated,
+	permgen.Permissions_UpdateUser_FullMethodName:    interceptors.AccessAuthenticated,
+	permgen.Permissions_VideoPerm_FullMethodName:     interceptors.AccessAuthenticated,
+	permgen.Permissions_ChangeOptions_FullMethodName: interceptors.AccessAuthenticated,
+	permgen.Permissions_ExportPolicy_FullMethodName:  interceptors.AccessAdmin,
+	permgen.Permissions_ImportPolicy_FullMethodName:  interceptors.AccessAdmin,
+
+	stagen.Status_IsBanned_FullMethodName:     interceptors.AccessAuthenticated,
+	stagen.Status_CurrentRole_FullMethodName:  interceptors.AccessAuthenticated,
+	stagen.Status_LastLogin_FullMethodName:    interceptors.AccessAuthenticated,
+	stagen.Status_LoginHistory_FullMethodName: interceptors.AccessAuthenticated,
+	stagen.Status_Roles_FullMethodName:        interceptors.AccessAuthenticated,
+	stagen.Status_BanUser_FullMethodName:      interceptors.AccessAdmin,
+	stagen.Status_UnbanUser_FullMethodName:    interceptors.AccessAdmin,
+
+	appgen.Apps_CreateApp_FullMethodName:    interceptors.AccessAdmin,
+	appgen.Apps_GetApp_FullMethodName:       interceptors.AccessAdmin,
+	appgen.Apps_ListApps_FullMethodName:     interceptors.AccessAdmin,
+	appgen.Apps_UpdateApp_FullMethodName:    interceptors.AccessAdmin,
+	appgen.Apps_DisableApp_FullMethodName:   interceptors.AccessAdmin,
+	appgen.Apps_EnableApp_FullMethodName:    interceptors.AccessAdmin,
+	appgen.Apps_DeleteApp_FullMethodName:    interceptors.AccessAdmin,
+	appgen.Apps_RotateSecret_FullMethodName: interceptors.AccessAdmin,
+
+	grpgen.Groups_CreateGroup_FullMethodName:  interceptors.AccessAdmin,
+	grpgen.Groups_GetGroup_FullMethodName:     interceptors.AccessAdmin,
+	grpgen.Groups_ListGroups_FullMethodName:   interceptors.AccessAdmin,
+	grpgen.Groups_UpdateGroup_FullMethodName:  interceptors.AccessAdmin,
+	grpgen.Groups_DeleteGroup_FullMethodName:  interceptors.AccessAdmin,
+	grpgen.Groups_AddMember_FullMethodName:    interceptors.AccessAdmin,
+	grpgen.Groups_RemoveMember_FullMethodName: interceptors.AccessAdmin,
+	grpgen.Groups_BindRole_FullMethodName:     interceptors.AccessAdmin,
+	grpgen.Groups_UnbindRole_FullMethodName:   interceptors.AccessAdmin,
+	grpgen.Groups_ListMembers_FullMethodName:  interceptors.AccessAdmin,
+	grpgen.Groups_UserGroups_FullMethodName:   interceptors.AccessAuthenticated,
+
+	// profile methods serve subject of token only
+	profgen.Profile_GetProfile_FullMethodName:     interceptors.AccessAuthenticated,
+	profgen.Profile_UpdateProfile_FullMethodName:  interceptors.AccessAuthenticated,
+	profgen.Profile_ChangeEmail_FullMethodName:    interceptors.AccessAuthenticated,
+	profgen.Profile_ConfirmEmail_FullMethodName:   interceptors.AccessAuthenticated,
+	profgen.Profile_ChangePassword_FullMethodName: interceptors.AccessAuthenticated,
+
+	// health probes have no credentials
+	healthpb.Health_Check_FullMethodName: interceptors.AccessPublic,
+	healthpb.Health_Watch_FullMethodName: interceptors.AccessPublic,
+}
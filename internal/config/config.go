@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"flag"
+	"log/slog"
 	"os"
 	"time"
 
@@ -15,20 +16,49 @@ type Config struct {
 	Postgres PostgresConfig `yaml:"postgres"`
 	Redis    RedisConfig    `yaml:"redis"`
 	TokenTTL time.Duration  `yaml:"token_ttl" env-required:"true"`
-	GRPC     GRPConfig      `yaml:"grpc"`
-	Metrics  Metrics        `yaml:"metrics"`
-	Jaeger   Jaeger         `yaml:"jaeger"`
+	// longest access token lifetime an app may set, retired signing keys stay valid at least so long
+	MaxAccessTTL time.Duration `yaml:"max_access_ttl" env:"MAX_ACCESS_TTL" env-default:"24h"`
+	// how often signing keys are reloaded from database
+	KeysRefresh time.Duration     `yaml:"keys_refresh" env-default:"1m"`
+	TokenClaims TokenClaimsConfig `yaml:"token_claims"`
+	GRPC        GRPConfig         `yaml:"grpc"`
+	Metrics     Metrics           `yaml:"metrics"`
+	Tracing     Tracing           `yaml:"tracing"`
+	Services    Services          `yaml:"services"`
+	Gateway     GatewayConfig     `yaml:"gateway"`
+	Mail        MailConfig        `yaml:"mail"`
+}
+
+// claims embedded to access tokens besides identity of user
+type TokenClaimsConfig struct {
+	Roles       bool `yaml:"roles" env:"TOKEN_CLAIMS_ROLES" env-default:"false"`
+	Permissions bool `yaml:"permissions" env:"TOKEN_CLAIMS_PERMISSIONS" env-default:"false"`
+	// limit of token size in bytes, permissions and then roles are left out of larger tokens
+	MaxSize int `yaml:"max_size" env:"TOKEN_CLAIMS_MAX_SIZE" env-default:"4096"`
 }
 
 // postgres config
 type PostgresConfig struct {
-	PostgresqlHost     string `yaml:"postgresql_host"`
-	PostgresqlPort     string `yaml:"postgresql_port"`
-	PostgresqlUser     string `yaml:"postgresql_user"`
-	PostgresqlPassword string `yaml:"postgresql_password"`
-	PostgresqlDbname   string `yaml:"postgresql_dbname"`
-	PostgresqlSSLMode  string `yaml:"postgresql_sslmode"`
-	PgDriver           string `yaml:"pg_driver"`
+	PostgresqlHost     string     `yaml:"postgresql_host"`
+	PostgresqlPort     string     `yaml:"postgresql_port"`
+	PostgresqlUser     string     `yaml:"postgresql_user"`
+	PostgresqlPassword string     `yaml:"postgresql_password"`
+	PostgresqlDbname   string     `yaml:"postgresql_dbname"`
+	PostgresqlSSLMode  string     `yaml:"postgresql_sslmode"`
+	PgDriver           string     `yaml:"pg_driver"`
+	Pool               PoolConfig `yaml:"pool"`
+	// applies pending migrations on start
+	AutoMigrate bool `yaml:"auto_migrate" env:"AUTO_MIGRATE" env-default:"false"`
+}
+
+// postgres connection pool config
+type PoolConfig struct {
+	MaxConns          int32         `yaml:"max_conns" env-default:"20"`
+	MinConns          int32         `yaml:"min_conns" env-default:"5"`
+	MaxConnLifetime   time.Duration `yaml:"max_conn_lifetime" env-default:"2m"`
+	MaxConnIdleTime   time.Duration `yaml:"max_conn_idle_time" env-default:"20s"`
+	HealthCheckPeriod time.Duration `yaml:"health_check_period" env-default:"30s"`
+	ConnectTimeout    time.Duration `yaml:"connect_timeout" env-default:"5s"`
 }
 
 // redis config
@@ -48,6 +78,28 @@ type RedisConfig struct {
 type GRPConfig struct {
 	Port    int           `yaml:"port"`
 	Timeout time.Duration `yaml:"timeout"`
+	// how often postgres and redis are pinged for health status
+	HealthInterval time.Duration `yaml:"health_interval" env-default:"10s"`
+	// how long server reports NOT_SERVING before graceful stop
+	ShutdownDelay time.Duration `yaml:"shutdown_delay" env-default:"5s"`
+	TLS           TLSConfig     `yaml:"tls"`
+	// CIDRs of reverse proxies whose x-forwarded-for is trusted, the in-process gateway connects over loopback
+	TrustedProxies []string `yaml:"trusted_proxies" env:"GRPC_TRUSTED_PROXIES" env-default:"127.0.0.1/32,::1/128"`
+}
+
+// TLS of gRPC listener
+type TLSConfig struct {
+	Enabled  bool   `yaml:"enabled" env:"GRPC_TLS_ENABLED"`
+	CertFile string `yaml:"cert_file" env:"GRPC_TLS_CERT_FILE"`
+	KeyFile  string `yaml:"key_file" env:"GRPC_TLS_KEY_FILE"`
+	// how often cert and key files are checked for changes
+	ReloadInterval time.Duration `yaml:"reload_interval" env-default:"30s"`
+	// CA bundle of client certificates, enables mTLS
+	ClientCAFile string `yaml:"client_ca_file" env:"GRPC_TLS_CLIENT_CA_FILE"`
+	// without it callers can connect without certificate and authenticate with tokens
+	RequireClientCert bool `yaml:"require_client_cert"`
+	// common name of client certificate subject to app id, such callers authenticate as app without secret
+	ClientApps map[string]int64 `yaml:"client_apps"`
 }
 
 type Metrics struct {
@@ -55,10 +107,85 @@ type Metrics struct {
 	ServiceName string `yaml:"prom_service_name"`
 }
 
-type Jaeger struct {
-	Host        string `yaml:"jaeger_host"`
-	ServiceName string `yaml:"jaeger_service_name"`
-	LogSpans    bool   `yaml:"jaeger_log_spans"`
+// opentelemetry tracing config
+type Tracing struct {
+	// otlp-grpc, otlp-http, stdout for local runs or none
+	Exporter string `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none"`
+	// host:port of collector, e.g. localhost:4317 for otlp-grpc and localhost:4318 for otlp-http
+	Endpoint string `yaml:"endpoint" env:"TRACING_ENDPOINT"`
+	Insecure bool   `yaml:"insecure"`
+	// share of traces started by this service which are sampled, incoming traces keep decision of caller
+	SampleRatio float64 `yaml:"sample_ratio" env-default:"1"`
+	ServiceName string  `yaml:"service_name" env-default:"sso"`
+	// extra resource attributes of every span, e.g. service.version
+	Attributes map[string]string `yaml:"attributes"`
+}
+
+// http/json gateway config
+type GatewayConfig struct {
+	Enabled bool          `yaml:"enabled" env-default:"true"`
+	Port    int           `yaml:"port" env-default:"8080"`
+	Timeout time.Duration `yaml:"timeout" env-default:"10s"`
+	// used to dial gRPC port when grpc.tls is enabled
+	TLS GatewayTLSConfig `yaml:"tls"`
+}
+
+type GatewayTLSConfig struct {
+	// CA which signed certificate of gRPC listener, system roots when empty
+	CAFile string `yaml:"ca_file"`
+	// name in certificate of gRPC listener, localhost when empty
+	ServerName string `yaml:"server_name"`
+	// client certificate for mTLS listener
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+}
+
+// outgoing mail, messages are only written to log when smtp_addr is empty
+type MailConfig struct {
+	SMTPAddr string `yaml:"smtp_addr" env:"MAIL_SMTP_ADDR"`
+	From     string `yaml:"from" env:"MAIL_FROM" env-default:"sso@localhost"`
+	Username string `yaml:"username" env:"MAIL_USERNAME"`
+	Password string `yaml:"password" env:"MAIL_PASSWORD"`
+	// how long code which confirms new email address is valid
+	VerificationTTL time.Duration `yaml:"verification_ttl" env-default:"24h"`
+}
+
+// gRPC services which will be registered on server
+type Services struct {
+	Auth        ServiceConfig `yaml:"auth"`
+	Permissions ServiceConfig `yaml:"permissions"`
+	Status      ServiceConfig `yaml:"status"`
+	Apps        ServiceConfig `yaml:"apps"`
+	Groups      ServiceConfig `yaml:"groups"`
+	Profile     ServiceConfig `yaml:"profile"`
+}
+
+type ServiceConfig struct {
+	Enabled bool `yaml:"enabled" env-default:"true"`
+}
+
+const redactedValue = "[REDACTED]"
+
+// loggedConfig has no LogValue method, so slog prints its fields instead of calling Config.LogValue again
+type loggedConfig Config
+
+// LogValue hides passwords when config is logged on start
+func (c Config) LogValue() slog.Value {
+	c.Postgres.PostgresqlPassword = redact(c.Postgres.PostgresqlPassword)
+	c.Redis.RedisPassword = redact(c.Redis.RedisPassword)
+	c.Redis.Password = redact(c.Redis.Password)
+	c.Mail.Password = redact(c.Mail.Password)
+
+	return slog.AnyValue(loggedConfig(c))
+}
+
+// redact keeps empty secrets empty, so it is still visible that secret is not set
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	return redactedValue
 }
 
 var (
@@ -67,7 +194,14 @@ var (
 )
 
 func MustLoad() *Config {
-	path := FetchConfigFlag()
+	return MustLoadPath(FetchConfigFlag())
+}
+
+// MustLoadPath loads config from the given file, used by subcommands with their own flags
+func MustLoadPath(path string) *Config {
+	if path == "" {
+		path = os.Getenv("CONFIG_PATH")
+	}
 	if path == "" {
 		panic("config path is empty" + path)
 	}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogValueRedactsSecrets(t *testing.T) {
+	cfg := &Config{Env: "prod"}
+	cfg.Postgres.PostgresqlPassword = "pg-secret"
+	cfg.Redis.RedisPassword = "redis-secret"
+	cfg.Redis.Password = "rd-secret"
+	cfg.Mail.Password = "smtp-secret"
+	cfg.Mail.SMTPAddr = "smtp.example.com:587"
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("starting application", slog.Any("cfg", cfg))
+	out := buf.String()
+
+	for _, secret := range []string{"pg-secret", "redis-secret", "rd-secret", "smtp-secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log contains %q: %s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "smtp.example.com:587") || !strings.Contains(out, redactedValue) {
+		t.Errorf("log lost non-secret fields or redaction marker: %s", out)
+	}
+	if cfg.Mail.Password != "smtp-secret" {
+		t.Error("LogValue changed config itself")
+	}
+}
@@ -1,6 +1,56 @@
 package models
 
+import (
+	"slices"
+	"time"
+)
+
 type App struct {
-	ID     int
-	Name   string
+	ID             int
+	TenantID       int64
+	Name           string
+	Description    string
+	LogoURL        string
+	RedirectURIs   []string
+	AllowedOrigins []string
+	// disabled app cannot log users in nor authenticate itself
+	Disabled  bool
+	Secrets   []AppSecret
+	Tokens    AppTokenConfig
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// AppSecret is stored as hash, plain secret is shown only once when it is generated
+type AppSecret struct {
+	ID        int64
+	Hash      string
+	CreatedAt time.Time
+	// previous secret keeps working until ExpiresAt after rotation, zero value means secret does not expire
+	ExpiresAt time.Time
+}
+
+// grant types which app can use to obtain tokens
+const (
+	GrantPassword     = "password"
+	GrantRefreshToken = "refresh_token"
+)
+
+// AppTokenConfig overrides token settings of service for one app, zero values mean defaults of service
+type AppTokenConfig struct {
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	// refresh token which is not used for IdleTimeout expires before RefreshTTL, zero disables it
+	IdleTimeout time.Duration
+	// empty GrantTypes allow every grant
+	GrantTypes []string
+	// aud claim of tokens, no claim when empty
+	Audience string
+	// kid of dedicated signing key, active key of service is used when empty
+	SigningKeyID string
+}
+
+// AllowsGrant reports whether app may obtain tokens with grant type
+func (c AppTokenConfig) AllowsGrant(grant string) bool {
+	return len(c.GrantTypes) == 0 || slices.Contains(c.GrantTypes, grant)
 }
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Ban is a record of user_bans history
+type Ban struct {
+	ID        int64
+	UserID    int64
+	BannedBy  int64
+	Reason    string
+	CreatedAt time.Time
+	ExpiresAt time.Time //zero value means permanent ban
+}
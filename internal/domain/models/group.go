@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Group carries roles for its members, members of group also get roles of every ancestor of group
+type Group struct {
+	ID          int64
+	Name        string
+	Description string
+	// ParentID is zero for top level group
+	ParentID  int64
+	Roles     []GroupRole
+	CreatedAt time.Time
+}
+
+// GroupRole is role bound to group in app, AppID = 0 means role is bound in every app
+type GroupRole struct {
+	Role  string
+	AppID uint64
+}
+
+// GroupMember is user which is member of group or of one of its subgroups
+type GroupMember struct {
+	UserID   int64
+	Username string
+	Email    string
+	// GroupID is group which user was added to, it differs from listed group for members of subgroups
+	GroupID int64
+}
+
+// UserGroup is group of user, inherited groups are ancestors of groups which user was added to
+type UserGroup struct {
+	ID       int64
+	Name     string
+	ParentID int64
+	Direct   bool
+}
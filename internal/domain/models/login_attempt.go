@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// outcomes of login attempt
+const (
+	LoginSuccess     = "success"
+	LoginUnknownUser = "unknown_user"
+	LoginBadPassword = "bad_password"
+	LoginBanned      = "banned"
+	LoginInvalidApp  = "invalid_app"
+	LoginGrantDenied = "grant_not_allowed"
+	LoginAppDisabled = "app_disabled"
+	LoginInternal    = "internal_error"
+)
+
+// LoginAttempt is a record of login_history, UserID is zero when email is unknown
+type LoginAttempt struct {
+	ID        int64
+	UserID    int64
+	Email     string
+	AppID     uint64
+	Success   bool
+	Reason    string
+	IP        string
+	UserAgent string
+	CreatedAt time.Time
+}
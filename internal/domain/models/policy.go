@@ -0,0 +1,76 @@
+package models
+
+import "fmt"
+
+// AllApps is app id of role binding, that applies to every app
+const AllApps = 0
+
+// Policy is the whole RBAC model, that is exported to and imported from yaml
+type Policy struct {
+	Roles           []PolicyRole           `yaml:"roles"`
+	Permissions     []string               `yaml:"permissions"`
+	RolePermissions []PolicyRolePermission `yaml:"role_permissions"`
+	Bindings        []PolicyBinding        `yaml:"bindings"`
+}
+
+type PolicyRole struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+}
+
+type PolicyRolePermission struct {
+	Role       string `yaml:"role"`
+	Permission string `yaml:"permission"`
+	Effect     string `yaml:"effect"`
+}
+
+// PolicyBinding grants role to user (by email) in app, AppID = AllApps means every app
+type PolicyBinding struct {
+	User  string `yaml:"user"`
+	Role  string `yaml:"role"`
+	AppID int    `yaml:"app_id"`
+}
+
+// policy change operations
+const (
+	PolicyAdd    = "+"
+	PolicyUpdate = "~"
+	PolicyRemove = "-"
+)
+
+// policy change kinds
+const (
+	PolicyKindRole           = "role"
+	PolicyKindPermission     = "permission"
+	PolicyKindRolePermission = "role_permission"
+	PolicyKindBinding        = "binding"
+)
+
+// PolicyChange is a single step to move stored policy to the desired one,
+// only the field which belongs to Kind is filled
+type PolicyChange struct {
+	Op             string
+	Kind           string
+	Role           PolicyRole
+	Permission     string
+	RolePermission PolicyRolePermission
+	Binding        PolicyBinding
+}
+
+func (c PolicyChange) String() string {
+	switch c.Kind {
+	case PolicyKindRole:
+		if c.Role.Description != "" {
+			return fmt.Sprintf("%s role %s (%s)", c.Op, c.Role.Name, c.Role.Description)
+		}
+		return fmt.Sprintf("%s role %s", c.Op, c.Role.Name)
+	case PolicyKindPermission:
+		return fmt.Sprintf("%s permission %s", c.Op, c.Permission)
+	case PolicyKindRolePermission:
+		return fmt.Sprintf("%s role_permission %s -> %s (%s)",
+			c.Op, c.RolePermission.Role, c.RolePermission.Permission, c.RolePermission.Effect)
+	case PolicyKindBinding:
+		return fmt.Sprintf("%s binding %s -> %s (app %d)", c.Op, c.Binding.User, c.Binding.Role, c.Binding.AppID)
+	}
+	return fmt.Sprintf("%s %s", c.Op, c.Kind)
+}
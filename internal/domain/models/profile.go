@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Profile is what user can see and change about themselves
+type Profile struct {
+	UserID   int64
+	TenantID int64
+	Username string
+	Email    string
+	Avatar   string
+	// PendingEmail waits for confirmation, empty when there is no unexpired change
+	PendingEmail string
+	// LastLogin is zero when user has never logged in
+	LastLogin time.Time
+	CreatedAt time.Time
+}
+
+// EmailChange is new address of user which is not confirmed yet, only hash of code is stored
+type EmailChange struct {
+	UserID    int64
+	NewEmail  string
+	CodeHash  string
+	ExpiresAt time.Time
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// SigningKey is a jwt signing secret, tokens carry its ID in kid header
+type SigningKey struct {
+	ID        string
+	Secret    string
+	Active    bool
+	CreatedAt time.Time
+	RetiredAt time.Time //zero value means key was never retired
+}
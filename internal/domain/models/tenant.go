@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// Tenant is organization which owns users, apps, roles and permissions
+type Tenant struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// types of tokens told by typ claim, tokens issued before it was added are access tokens
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// TokenClaims are claims of validated access token
+type TokenClaims struct {
+	Type   string
+	UserID int64
+	Email  string
+	AppID  int64
+	// TenantID is zero in tokens issued before tenants
+	TenantID int64
+	Scopes   []string
+	// Roles and Permissions are nil when token does not carry them
+	Roles       []string
+	Permissions []string
+	ID          string
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+}
+
+// TokenGrant is what access token authorizes in app, nil Roles and Permissions are left out of token
+type TokenGrant struct {
+	Scopes      []string
+	Roles       []string
+	Permissions []string
+}
+
+// Introspection is state of token as sso sees it, claims are filled only for active token
+type Introspection struct {
+	Active bool
+	Claims TokenClaims
+}
@@ -4,6 +4,7 @@ import "time"
 
 type User struct {
 	ID             int
+	TenantID       int64
 	Username       string
 	Email          string
 	HashedPass     []byte
@@ -1,5 +1,52 @@
 package models
 
+import "strings"
+
 type Permission struct {
 	Perm bool
 }
+
+// effects of role permission grant
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// permission names which are checked by permissions service
+//
+// names are written as "resource:action", so grant "video:*" covers every video action
+// and grant "*" covers everything
+const (
+	PermDeleteUser    = "delete_user"
+	PermUpdateUser    = "update_user"
+	PermVideoDownload = "video:download"
+	PermChangeOptions = "change_options"
+)
+
+// PermissionRule is a single allow or deny grant of permission, that user has got through his roles
+type PermissionRule struct {
+	Name   string
+	Effect string
+}
+
+const (
+	permWildcard          = "*"
+	permResourceSeparator = ":"
+)
+
+// Covers reports whether rule applies to requested permission.
+//
+// Rule name can be an exact name ("video:download"), a resource family ("video:*")
+// or a global wildcard ("*")
+func (r PermissionRule) Covers(requested string) bool {
+	if r.Name == permWildcard || r.Name == requested {
+		return true
+	}
+
+	family, ok := strings.CutSuffix(r.Name, permResourceSeparator+permWildcard)
+	if !ok {
+		return false
+	}
+
+	return strings.HasPrefix(requested, family+permResourceSeparator)
+}
@@ -0,0 +1,35 @@
+package models
+
+import "testing"
+
+func TestPermissionRuleCovers(t *testing.T) {
+	tests := []struct {
+		rule      string
+		requested string
+		want      bool
+	}{
+		{rule: "video:download", requested: "video:download", want: true},
+		{rule: "video:download", requested: "video:upload", want: false},
+		{rule: "video:*", requested: "video:download", want: true},
+		{rule: "video:*", requested: "video:*", want: true},
+		{rule: "video:*", requested: "audio:download", want: false},
+		{rule: "video:*", requested: "video", want: false},
+		{rule: "video:*", requested: "videos:download", want: false},
+		{rule: "video:*", requested: "video:hd:download", want: true},
+		{rule: "*", requested: "delete_user", want: true},
+		{rule: "*", requested: "video:download", want: true},
+		{rule: "delete_user", requested: "delete_user", want: true},
+		{rule: "delete_*", requested: "delete_user", want: false},
+		{rule: "", requested: "video:download", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule+"/"+tt.requested, func(t *testing.T) {
+			rule := PermissionRule{Name: tt.rule, Effect: EffectAllow}
+
+			if got := rule.Covers(tt.requested); got != tt.want {
+				t.Errorf("Covers(%q) = %v, want %v", tt.requested, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,45 @@
 package dto
 
+import "time"
+
 type IsBannedRespStruct struct {
-	IsBanned bool
-	Message  string
+	IsBanned  bool
+	Message   string
+	Reason    string
+	ExpiresAt time.Time //zero value means permanent ban
 }
 
 type CurrentRoleRespStruct struct {
 	Username string
 	Role     string
 }
+
+// LoginHistoryFilter selects page of user login history, newest attempts first.
+//
+// Zero From/To mean no time bound, BeforeID is id of the last attempt of previous page
+type LoginHistoryFilter struct {
+	UserID   int64
+	From     time.Time
+	To       time.Time
+	BeforeID int64
+	PageSize int
+}
+
+// UserRole is a role bound to user in app, AppID = 0 means role is bound in every app
+type UserRole struct {
+	AppID             uint64
+	Role              string
+	Description       string
+	Group             string // group which role is inherited from, empty for direct roles
+	Permissions       []string
+	DeniedPermissions []string
+}
+
+// AppRolesStruct groups roles of user by app
+type AppRolesStruct struct {
+	AppID                uint64
+	Roles                []UserRole
+	EffectivePermissions []string
+	// denies which make exceptions of wildcards in EffectivePermissions
+	ExcludedPermissions []string
+}
@@ -0,0 +1,244 @@
+package apps
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"sso/internal/domain/models"
+	appsvalidation "sso/internal/grpc/apps_validation"
+	"sso/internal/services/appsvc"
+	"sso/internal/storage"
+	"sso/proto/generated/appgen"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type AppService interface {
+	Register(ctx context.Context, app models.App) (models.App, string, error)
+	App(ctx context.Context, appID int) (models.App, error)
+	Apps(ctx context.Context) ([]models.App, error)
+	Update(ctx context.Context, app models.App) (models.App, error)
+	SetDisabled(ctx context.Context, appID int, disabled bool) error
+	Delete(ctx context.Context, appID int) error
+	RotateSecret(ctx context.Context, appID int, overlap time.Duration) (string, models.AppSecret, error)
+}
+
+// serverAPI is admin-only, interceptor rejects other callers before handlers
+type serverAPI struct {
+	appgen.UnimplementedAppsServer
+	apps AppService
+}
+
+func Register(gRPC *grpc.Server, apps AppService) {
+	appgen.RegisterAppsServer(gRPC, &serverAPI{apps: apps})
+}
+
+func (s *serverAPI) CreateApp(ctx context.Context, req *appgen.CreateAppRequest) (*appgen.CreateAppResponse, error) {
+	if err := appsvalidation.CreateAppValidation(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	app, secret, err := s.apps.Register(ctx, models.App{
+		ID:             int(req.AppId),
+		Name:           req.Name,
+		Description:    req.Description,
+		LogoURL:        req.LogoUrl,
+		RedirectURIs:   req.RedirectUris,
+		AllowedOrigins: req.AllowedOrigins,
+		Tokens:         tokenConfig(req.Tokens),
+	})
+	if err != nil {
+		return nil, appError(err)
+	}
+
+	return &appgen.CreateAppResponse{
+		App:    appResponse(app),
+		Secret: secret,
+	}, nil
+}
+
+func (s *serverAPI) GetApp(ctx context.Context, req *appgen.GetAppRequest) (*appgen.App, error) {
+	if err := appsvalidation.AppIDValidation(req.AppId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid app id argument")
+	}
+
+	app, err := s.apps.App(ctx, int(req.AppId))
+	if err != nil {
+		return nil, appError(err)
+	}
+
+	return appResponse(app), nil
+}
+
+func (s *serverAPI) ListApps(ctx context.Context, _ *appgen.ListAppsRequest) (*appgen.ListAppsResponse, error) {
+	apps, err := s.apps.Apps(ctx)
+	if err != nil {
+		return nil, appError(err)
+	}
+
+	resp := &appgen.ListAppsResponse{
+		Apps: make([]*appgen.App, 0, len(apps)),
+	}
+	for _, app := range apps {
+		resp.Apps = append(resp.Apps, appResponse(app))
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) UpdateApp(ctx context.Context, req *appgen.UpdateAppRequest) (*appgen.App, error) {
+	if err := appsvalidation.UpdateAppValidation(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	app, err := s.apps.Update(ctx, models.App{
+		ID:             int(req.AppId),
+		Name:           req.Name,
+		Description:    req.Description,
+		LogoURL:        req.LogoUrl,
+		RedirectURIs:   req.RedirectUris,
+		AllowedOrigins: req.AllowedOrigins,
+		Tokens:         tokenConfig(req.Tokens),
+	})
+	if err != nil {
+		return nil, appError(err)
+	}
+
+	return appResponse(app), nil
+}
+
+func (s *serverAPI) DisableApp(ctx context.Context, req *appgen.DisableAppRequest) (*appgen.DisableAppResponse, error) {
+	if err := appsvalidation.AppIDValidation(req.AppId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid app id argument")
+	}
+
+	if err := s.apps.SetDisabled(ctx, int(req.AppId), true); err != nil {
+		return nil, appError(err)
+	}
+
+	return &appgen.DisableAppResponse{Disabled: true}, nil
+}
+
+func (s *serverAPI) EnableApp(ctx context.Context, req *appgen.EnableAppRequest) (*appgen.EnableAppResponse, error) {
+	if err := appsvalidation.AppIDValidation(req.AppId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid app id argument")
+	}
+
+	if err := s.apps.SetDisabled(ctx, int(req.AppId), false); err != nil {
+		return nil, appError(err)
+	}
+
+	return &appgen.EnableAppResponse{Enabled: true}, nil
+}
+
+func (s *serverAPI) DeleteApp(ctx context.Context, req *appgen.DeleteAppRequest) (*appgen.DeleteAppResponse, error) {
+	if err := appsvalidation.AppIDValidation(req.AppId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid app id argument")
+	}
+
+	if err := s.apps.Delete(ctx, int(req.AppId)); err != nil {
+		return nil, appError(err)
+	}
+
+	return &appgen.DeleteAppResponse{Deleted: true}, nil
+}
+
+func (s *serverAPI) RotateSecret(ctx context.Context, req *appgen.RotateSecretRequest) (*appgen.RotateSecretResponse, error) {
+	if err := appsvalidation.RotateSecretValidation(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	overlap := appsvc.DefaultSecretOverlap
+	if req.OverlapSeconds > 0 {
+		overlap = time.Duration(req.OverlapSeconds) * time.Second
+	}
+	if req.RevokePrevious {
+		overlap = 0
+	}
+
+	secret, info, err := s.apps.RotateSecret(ctx, int(req.AppId), overlap)
+	if err != nil {
+		return nil, appError(err)
+	}
+
+	return &appgen.RotateSecretResponse{
+		Secret: secret,
+		Info:   secretResponse(info),
+		// previous secret expires at the same transaction time as new one is created
+		PreviousExpiresAt: info.CreatedAt.Add(overlap).Format(time.RFC3339),
+	}, nil
+}
+
+func appError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrAppNotFound):
+		return status.Error(codes.NotFound, "app not found")
+	case errors.Is(err, storage.ErrAppExists):
+		return status.Error(codes.AlreadyExists, "app with this id or name already exists")
+	case errors.Is(err, storage.ErrKeyNotFound):
+		return status.Error(codes.InvalidArgument, "signing key not found")
+	case errors.Is(err, appsvc.ErrOverlapTooLong):
+		return status.Error(codes.InvalidArgument, "secret overlap is too long")
+	case errors.Is(err, appsvc.ErrAccessTTLTooLong):
+		return status.Error(codes.InvalidArgument, "access token ttl is too long")
+	case errors.Is(err, appsvc.ErrInvalidTokenTTL):
+		return status.Error(codes.InvalidArgument, "token ttl is out of range")
+	}
+
+	return status.Error(codes.Internal, "apps internal server error")
+}
+
+// tokenConfig expects settings checked by validation, seconds beyond appsvc.MaxTokenTTL would overflow time.Duration
+func tokenConfig(tokens *appgen.TokenSettings) models.AppTokenConfig {
+	return models.AppTokenConfig{
+		AccessTTL:    time.Duration(tokens.GetAccessTtlSeconds()) * time.Second,
+		RefreshTTL:   time.Duration(tokens.GetRefreshTtlSeconds()) * time.Second,
+		IdleTimeout:  time.Duration(tokens.GetIdleTimeoutSeconds()) * time.Second,
+		GrantTypes:   tokens.GetGrantTypes(),
+		Audience:     tokens.GetAudience(),
+		SigningKeyID: tokens.GetSigningKid(),
+	}
+}
+
+func appResponse(app models.App) *appgen.App {
+	resp := &appgen.App{
+		AppId:          uint64(app.ID),
+		Name:           app.Name,
+		Description:    app.Description,
+		LogoUrl:        app.LogoURL,
+		RedirectUris:   app.RedirectURIs,
+		AllowedOrigins: app.AllowedOrigins,
+		Disabled:       app.Disabled,
+		Tokens: &appgen.TokenSettings{
+			AccessTtlSeconds:   int64(app.Tokens.AccessTTL.Seconds()),
+			RefreshTtlSeconds:  int64(app.Tokens.RefreshTTL.Seconds()),
+			IdleTimeoutSeconds: int64(app.Tokens.IdleTimeout.Seconds()),
+			GrantTypes:         app.Tokens.GrantTypes,
+			Audience:           app.Tokens.Audience,
+			SigningKid:         app.Tokens.SigningKeyID,
+		},
+		Secrets:   make([]*appgen.SecretInfo, 0, len(app.Secrets)),
+		CreatedAt: app.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: app.UpdatedAt.Format(time.RFC3339),
+	}
+	for _, secret := range app.Secrets {
+		resp.Secrets = append(resp.Secrets, secretResponse(secret))
+	}
+
+	return resp
+}
+
+func secretResponse(secret models.AppSecret) *appgen.SecretInfo {
+	info := &appgen.SecretInfo{
+		Id:        secret.ID,
+		CreatedAt: secret.CreatedAt.Format(time.RFC3339),
+	}
+	if !secret.ExpiresAt.IsZero() {
+		info.ExpiresAt = secret.ExpiresAt.Format(time.RFC3339)
+	}
+
+	return info
+}
@@ -0,0 +1,118 @@
+package appsvalidation
+
+import (
+	"errors"
+	"net/url"
+	"sso/internal/domain/models"
+	"sso/internal/services/appsvc"
+	"sso/proto/generated/appgen"
+
+	"github.com/go-ozzo/ozzo-validation/is"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const maxURIs = 20
+
+// maxTTLSeconds keeps token lifetimes of apps in range of time.Duration
+var maxTTLSeconds = int64(appsvc.MaxTokenTTL.Seconds())
+
+func CreateAppValidation(req *appgen.CreateAppRequest) error {
+	if err := validation.ValidateStruct(
+		req,
+		validation.Field(&req.Name, validation.Required, validation.Length(3, 100)),
+		validation.Field(&req.Description, validation.Length(0, 1000)),
+		validation.Field(&req.LogoUrl, is.URL),
+		validation.Field(&req.RedirectUris, validation.Length(0, maxURIs), validation.Each(validation.By(IsRedirectURI))),
+		validation.Field(&req.AllowedOrigins, validation.Length(0, maxURIs), validation.Each(validation.By(IsOrigin))),
+	); err != nil {
+		return err
+	}
+
+	return tokenSettingsValidation(req.Tokens)
+}
+
+func UpdateAppValidation(req *appgen.UpdateAppRequest) error {
+	if err := validation.ValidateStruct(
+		req,
+		validation.Field(&req.AppId, validation.Required),
+		validation.Field(&req.Name, validation.Required, validation.Length(3, 100)),
+		validation.Field(&req.Description, validation.Length(0, 1000)),
+		validation.Field(&req.LogoUrl, is.URL),
+		validation.Field(&req.RedirectUris, validation.Length(0, maxURIs), validation.Each(validation.By(IsRedirectURI))),
+		validation.Field(&req.AllowedOrigins, validation.Length(0, maxURIs), validation.Each(validation.By(IsOrigin))),
+	); err != nil {
+		return err
+	}
+
+	return tokenSettingsValidation(req.Tokens)
+}
+
+func RotateSecretValidation(req *appgen.RotateSecretRequest) error {
+	return validation.ValidateStruct(
+		req,
+		validation.Field(&req.AppId, validation.Required),
+		validation.Field(&req.OverlapSeconds, validation.Min(0), validation.Max(int64(appsvc.MaxSecretOverlap.Seconds()))),
+	)
+}
+
+// AppIDValidation is used by requests which carry only app id
+func AppIDValidation(appID uint64) error {
+	return validation.Validate(appID, validation.Required)
+}
+
+func tokenSettingsValidation(tokens *appgen.TokenSettings) error {
+	if tokens == nil {
+		return nil
+	}
+
+	return validation.ValidateStruct(
+		tokens,
+		validation.Field(&tokens.AccessTtlSeconds, validation.Min(0), validation.Max(maxTTLSeconds)),
+		validation.Field(&tokens.RefreshTtlSeconds, validation.Min(0), validation.Max(maxTTLSeconds)),
+		validation.Field(&tokens.IdleTimeoutSeconds, validation.Min(0), validation.Max(maxTTLSeconds)),
+		validation.Field(&tokens.GrantTypes, validation.Each(validation.In(models.GrantPassword, models.GrantRefreshToken))),
+		validation.Field(&tokens.Audience, validation.Length(0, 255)),
+		validation.Field(&tokens.SigningKid, validation.Length(0, 64)),
+	)
+}
+
+// IsRedirectURI accepts absolute URI without fragment, plain http only for loopback,
+// custom schemes are allowed for mobile apps
+func IsRedirectURI(value interface{}) error {
+	raw, ok := value.(string)
+	if !ok {
+		return errors.New("redirect uri is not a string")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return errors.New("redirect uri must be absolute")
+	}
+	if u.Fragment != "" {
+		return errors.New("redirect uri must not have fragment")
+	}
+	if u.Scheme == "http" && u.Hostname() != "localhost" && u.Hostname() != "127.0.0.1" {
+		return errors.New("http redirect uri is allowed only for localhost")
+	}
+
+	return nil
+}
+
+// IsOrigin accepts scheme, host and optional port as browsers send them in Origin header
+func IsOrigin(value interface{}) error {
+	raw, ok := value.(string)
+	if !ok {
+		return errors.New("origin is not a string")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("origin must be http(s)://host[:port]")
+	}
+	if u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return errors.New("origin must not have path, query or credentials")
+	}
+
+	return nil
+}
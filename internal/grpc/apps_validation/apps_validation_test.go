@@ -0,0 +1,37 @@
+package appsvalidation
+
+import (
+	"math"
+	"testing"
+
+	"sso/proto/generated/appgen"
+)
+
+func TestTokenSettingsValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		tokens  *appgen.TokenSettings
+		wantErr bool
+	}{
+		{name: "no settings", tokens: nil},
+		{name: "defaults", tokens: &appgen.TokenSettings{}},
+		{name: "in range", tokens: &appgen.TokenSettings{AccessTtlSeconds: 3600, RefreshTtlSeconds: 86400, IdleTimeoutSeconds: 3600}},
+		{name: "negative access ttl", tokens: &appgen.TokenSettings{AccessTtlSeconds: -1}, wantErr: true},
+		{name: "negative refresh ttl", tokens: &appgen.TokenSettings{RefreshTtlSeconds: -1}, wantErr: true},
+		{name: "negative idle timeout", tokens: &appgen.TokenSettings{IdleTimeoutSeconds: -1}, wantErr: true},
+		{name: "access ttl overflows duration", tokens: &appgen.TokenSettings{AccessTtlSeconds: math.MaxInt64}, wantErr: true},
+		{name: "refresh ttl above limit", tokens: &appgen.TokenSettings{RefreshTtlSeconds: maxTTLSeconds + 1}, wantErr: true},
+		{name: "idle timeout overflows duration", tokens: &appgen.TokenSettings{IdleTimeoutSeconds: 1 << 40}, wantErr: true},
+		{name: "unknown grant", tokens: &appgen.TokenSettings{GrantTypes: []string{"implicit"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &appgen.CreateAppRequest{Name: "billing", Tokens: tt.tokens}
+
+			if err := CreateAppValidation(req); (err != nil) != tt.wantErr {
+				t.Errorf("CreateAppValidation() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,11 +3,13 @@ package auth
 import (
 	"context"
 	"errors"
+	"sso/internal/domain/models"
 	authvalidation "sso/internal/grpc/auth_validation"
+	"sso/internal/lib/clientinfo"
 	"sso/internal/services/authsvc"
 	"sso/internal/storage"
+	"sso/proto/generated/augen"
 
-	augen "github.com/killerquinn/protos/generated/auth_generated"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -19,26 +21,34 @@ type AuthS interface {
 		email string,
 		password string,
 		appID uint64,
+		scopes []string,
 	) (token string, err error)
 	RegisterNewUser(
 		ctx context.Context,
 		username string,
 		email string,
 		password string,
+		appID uint64,
 	) (userID int64, err error)
 	IsAdmin(
 		ctx context.Context,
 		userID int64,
 	) (bool, error)
+	Introspect(
+		ctx context.Context,
+		token string,
+	) (models.Introspection, error)
 }
 
 type serverAPI struct {
 	augen.UnimplementedAuthServer
-	auth AuthS
+	auth    AuthS
+	proxies clientinfo.Proxies
 }
 
-func Register(gRPC *grpc.Server, auth AuthS) {
-	augen.RegisterAuthServer(gRPC, &serverAPI{auth: auth})
+// Register adds auth service to gRPC server, client address is taken from metadata only when sent by one of proxies
+func Register(gRPC *grpc.Server, auth AuthS, proxies clientinfo.Proxies) {
+	augen.RegisterAuthServer(gRPC, &serverAPI{auth: auth, proxies: proxies})
 }
 
 func (s *serverAPI) Login(ctx context.Context, req *augen.LoginRequest) (*augen.LoginResponse, error) {
@@ -46,11 +56,25 @@ func (s *serverAPI) Login(ctx context.Context, req *augen.LoginRequest) (*augen.
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	token, err := s.auth.Login(ctx, req.Email, req.Password, req.AppId)
+	ctx = clientinfo.NewContext(ctx, clientinfo.FromIncoming(ctx, s.proxies))
+
+	token, err := s.auth.Login(ctx, req.Email, req.Password, req.AppId, req.Scopes)
 	if err != nil {
+		if errors.Is(err, authsvc.ErrTokenTooLarge) {
+			return nil, status.Error(codes.InvalidArgument, "too many scopes requested")
+		}
 		if errors.Is(err, authsvc.ErrInvalidCredentials) {
 			return nil, status.Error(codes.InvalidArgument, "error invalid credentials, retry with new password/login")
 		}
+		if errors.Is(err, authsvc.ErrUserBanned) {
+			return nil, status.Error(codes.PermissionDenied, "account is banned")
+		}
+		if errors.Is(err, authsvc.ErrAppDisabled) {
+			return nil, status.Error(codes.PermissionDenied, "app is disabled")
+		}
+		if errors.Is(err, authsvc.ErrGrantNotAllowed) {
+			return nil, status.Error(codes.PermissionDenied, "app does not allow password login")
+		}
 
 		return nil, status.Error(codes.Internal, "unable to login")
 	}
@@ -67,11 +91,17 @@ func (s *serverAPI) Register(ctx context.Context, req *augen.RegisterRequest) (*
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	userID, err := s.auth.RegisterNewUser(ctx, req.Username, req.Email, req.Password)
+	userID, err := s.auth.RegisterNewUser(ctx, req.Username, req.Email, req.Password, req.AppId)
 	if err != nil {
 		if errors.Is(err, storage.ErrUserNotFound) {
 			return nil, status.Error(codes.AlreadyExists, "user already exists")
 		}
+		if errors.Is(err, authsvc.ErrInvalidAppID) {
+			return nil, status.Error(codes.InvalidArgument, "unknown app")
+		}
+		if errors.Is(err, authsvc.ErrAppDisabled) {
+			return nil, status.Error(codes.PermissionDenied, "app is disabled")
+		}
 
 		return nil, status.Error(codes.Internal, "status internal")
 	}
@@ -98,3 +128,36 @@ func (s *serverAPI) IsAdmin(ctx context.Context, req *augen.IsAdminRequest) (*au
 		IsAdmin: isadm,
 	}, nil
 }
+
+func (s *serverAPI) Introspect(ctx context.Context, req *augen.IntrospectRequest) (*augen.IntrospectResponse, error) {
+	if err := authvalidation.ValidateIntrospectRequest(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	info, err := s.auth.Introspect(ctx, req.Token)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "unable to introspect token")
+	}
+	if !info.Active {
+		return &augen.IntrospectResponse{Active: false}, nil
+	}
+
+	resp := &augen.IntrospectResponse{
+		Active:      true,
+		Sub:         info.Claims.UserID,
+		AppId:       uint64(info.Claims.AppID),
+		Scopes:      info.Claims.Scopes,
+		Exp:         info.Claims.ExpiresAt.Unix(),
+		Jti:         info.Claims.ID,
+		Email:       info.Claims.Email,
+		Roles:       info.Claims.Roles,
+		Permissions: info.Claims.Permissions,
+		TenantId:    info.Claims.TenantID,
+		TokenType:   info.Claims.Type,
+	}
+	if !info.Claims.IssuedAt.IsZero() {
+		resp.Iat = info.Claims.IssuedAt.Unix()
+	}
+
+	return resp, nil
+}
@@ -3,33 +3,41 @@ package authvalidation
 import (
 	"fmt"
 	"net/mail"
+	"sso/proto/generated/augen"
 
 	validation "github.com/go-ozzo/ozzo-validation"
-	augen "github.com/killerquinn/protos/generated/auth_generated"
 )
 
 func ValidateUserLoginRequest(req *augen.LoginRequest) error {
 	return validation.ValidateStruct(
 		req,
-		validation.Field(req.Email, validation.Required, validation.By(IsValidEmail), validation.Length(4, 50)),
-		validation.Field(req.Password, validation.Required, validation.Length(8, 50)),
-		validation.Field(req.AppId, validation.Required, validation.Length(1, 15)),
+		validation.Field(&req.Email, validation.Required, validation.By(IsValidEmail), validation.Length(4, 50)),
+		validation.Field(&req.Password, validation.Required, validation.Length(8, 50)),
+		validation.Field(&req.AppId, validation.Required),
+		validation.Field(&req.Scopes, validation.Length(0, 50), validation.Each(validation.Length(1, 255))),
 	)
 }
 
 func ValidateUsrRegisterRequest(req *augen.RegisterRequest) error {
 	return validation.ValidateStruct(
 		req,
-		validation.Field(req.Email, validation.Required, validation.By(IsValidEmail), validation.Length(4, 50)),
-		validation.Field(req.Password, validation.Required, validation.Length(6, 100)),
-		validation.Field(req.Username, validation.Required, validation.Length(3, 20)),
+		validation.Field(&req.Email, validation.Required, validation.By(IsValidEmail), validation.Length(4, 50)),
+		validation.Field(&req.Password, validation.Required, validation.Length(6, 100)),
+		validation.Field(&req.Username, validation.Required, validation.Length(3, 20)),
 	)
 }
 
 func ValidateIsAdminRequest(req *augen.IsAdminRequest) error {
 	return validation.ValidateStruct(
 		req,
-		validation.Field(req.UserId, validation.Required),
+		validation.Field(&req.UserId, validation.Required),
+	)
+}
+
+func ValidateIntrospectRequest(req *augen.IntrospectRequest) error {
+	return validation.ValidateStruct(
+		req,
+		validation.Field(&req.Token, validation.Required),
 	)
 }
 
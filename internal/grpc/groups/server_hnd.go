@@ -0,0 +1,290 @@
+package groups
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"sso/internal/domain/models"
+	groupsvalidation "sso/internal/grpc/groups_validation"
+	"sso/internal/interceptors"
+	"sso/internal/storage"
+	"sso/proto/generated/grpgen"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type GroupService interface {
+	Create(ctx context.Context, group models.Group) (models.Group, error)
+	Group(ctx context.Context, groupID int64) (models.Group, error)
+	Groups(ctx context.Context) ([]models.Group, error)
+	Update(ctx context.Context, group models.Group) (models.Group, error)
+	Delete(ctx context.Context, groupID int64) error
+	AddMember(ctx context.Context, groupID int64, userID int64) error
+	RemoveMember(ctx context.Context, groupID int64, userID int64) error
+	BindRole(ctx context.Context, groupID int64, role string, appID uint64) error
+	UnbindRole(ctx context.Context, groupID int64, role string, appID uint64) error
+	Members(ctx context.Context, groupID int64, nested bool) ([]models.GroupMember, error)
+	UserGroups(ctx context.Context, userID int64) ([]models.UserGroup, error)
+}
+
+type AdminChecker interface {
+	IsAdmin(ctx context.Context, userID int64) (bool, error)
+}
+
+// serverAPI is admin-only except UserGroups, interceptor rejects other callers before handlers
+type serverAPI struct {
+	grpgen.UnimplementedGroupsServer
+	groups GroupService
+	admins AdminChecker
+}
+
+func Register(gRPC *grpc.Server, groups GroupService, admins AdminChecker) {
+	grpgen.RegisterGroupsServer(gRPC, &serverAPI{groups: groups, admins: admins})
+}
+
+func (s *serverAPI) CreateGroup(ctx context.Context, req *grpgen.CreateGroupRequest) (*grpgen.Group, error) {
+	if err := groupsvalidation.CreateGroupValidation(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	group, err := s.groups.Create(ctx, models.Group{
+		Name:        req.Name,
+		Description: req.Description,
+		ParentID:    req.ParentId,
+	})
+	if err != nil {
+		return nil, groupError(err)
+	}
+
+	return groupResponse(group), nil
+}
+
+func (s *serverAPI) GetGroup(ctx context.Context, req *grpgen.GetGroupRequest) (*grpgen.Group, error) {
+	if err := groupsvalidation.GroupIDValidation(req.GroupId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid group id argument")
+	}
+
+	group, err := s.groups.Group(ctx, req.GroupId)
+	if err != nil {
+		return nil, groupError(err)
+	}
+
+	return groupResponse(group), nil
+}
+
+func (s *serverAPI) ListGroups(ctx context.Context, _ *grpgen.ListGroupsRequest) (*grpgen.ListGroupsResponse, error) {
+	groups, err := s.groups.Groups(ctx)
+	if err != nil {
+		return nil, groupError(err)
+	}
+
+	resp := &grpgen.ListGroupsResponse{
+		Groups: make([]*grpgen.Group, 0, len(groups)),
+	}
+	for _, group := range groups {
+		resp.Groups = append(resp.Groups, groupResponse(group))
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) UpdateGroup(ctx context.Context, req *grpgen.UpdateGroupRequest) (*grpgen.Group, error) {
+	if err := groupsvalidation.UpdateGroupValidation(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	group, err := s.groups.Update(ctx, models.Group{
+		ID:          req.GroupId,
+		Name:        req.Name,
+		Description: req.Description,
+		ParentID:    req.ParentId,
+	})
+	if err != nil {
+		return nil, groupError(err)
+	}
+
+	return groupResponse(group), nil
+}
+
+func (s *serverAPI) DeleteGroup(ctx context.Context, req *grpgen.DeleteGroupRequest) (*grpgen.DeleteGroupResponse, error) {
+	if err := groupsvalidation.GroupIDValidation(req.GroupId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid group id argument")
+	}
+
+	if err := s.groups.Delete(ctx, req.GroupId); err != nil {
+		return nil, groupError(err)
+	}
+
+	return &grpgen.DeleteGroupResponse{Deleted: true}, nil
+}
+
+func (s *serverAPI) AddMember(ctx context.Context, req *grpgen.MemberRequest) (*grpgen.MemberResponse, error) {
+	if err := groupsvalidation.MemberValidation(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.groups.AddMember(ctx, req.GroupId, req.UserId); err != nil {
+		return nil, groupError(err)
+	}
+
+	return &grpgen.MemberResponse{Success: true}, nil
+}
+
+func (s *serverAPI) RemoveMember(ctx context.Context, req *grpgen.MemberRequest) (*grpgen.MemberResponse, error) {
+	if err := groupsvalidation.MemberValidation(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.groups.RemoveMember(ctx, req.GroupId, req.UserId); err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, "user is not member of group")
+		}
+
+		return nil, groupError(err)
+	}
+
+	return &grpgen.MemberResponse{Success: true}, nil
+}
+
+func (s *serverAPI) BindRole(ctx context.Context, req *grpgen.GroupRoleRequest) (*grpgen.GroupRoleResponse, error) {
+	if err := groupsvalidation.GroupRoleValidation(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.groups.BindRole(ctx, req.GroupId, req.Role, req.AppId); err != nil {
+		return nil, groupError(err)
+	}
+
+	return &grpgen.GroupRoleResponse{Success: true}, nil
+}
+
+func (s *serverAPI) UnbindRole(ctx context.Context, req *grpgen.GroupRoleRequest) (*grpgen.GroupRoleResponse, error) {
+	if err := groupsvalidation.GroupRoleValidation(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.groups.UnbindRole(ctx, req.GroupId, req.Role, req.AppId); err != nil {
+		if errors.Is(err, storage.ErrRoleNotFound) {
+			return nil, status.Error(codes.NotFound, "role is not bound to group")
+		}
+
+		return nil, groupError(err)
+	}
+
+	return &grpgen.GroupRoleResponse{Success: true}, nil
+}
+
+func (s *serverAPI) ListMembers(ctx context.Context, req *grpgen.ListMembersRequest) (*grpgen.ListMembersResponse, error) {
+	if err := groupsvalidation.GroupIDValidation(req.GroupId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid group id argument")
+	}
+
+	members, err := s.groups.Members(ctx, req.GroupId, req.IncludeSubgroups)
+	if err != nil {
+		return nil, groupError(err)
+	}
+
+	resp := &grpgen.ListMembersResponse{
+		Members: make([]*grpgen.Member, 0, len(members)),
+	}
+	for _, member := range members {
+		resp.Members = append(resp.Members, &grpgen.Member{
+			UserId:   member.UserID,
+			Username: member.Username,
+			Email:    member.Email,
+			GroupId:  member.GroupID,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) UserGroups(ctx context.Context, req *grpgen.UserGroupsRequest) (*grpgen.UserGroupsResponse, error) {
+	if err := groupsvalidation.UserGroupsValidation(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id argument")
+	}
+
+	if err := s.requireSelfOrAdmin(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	groups, err := s.groups.UserGroups(ctx, req.UserId)
+	if err != nil {
+		return nil, groupError(err)
+	}
+
+	resp := &grpgen.UserGroupsResponse{
+		Groups: make([]*grpgen.UserGroup, 0, len(groups)),
+	}
+	for _, group := range groups {
+		resp.Groups = append(resp.Groups, &grpgen.UserGroup{
+			GroupId:  group.ID,
+			Name:     group.Name,
+			ParentId: group.ParentID,
+			Direct:   group.Direct,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) requireSelfOrAdmin(ctx context.Context, userID int64) error {
+	principal, ok := interceptors.PrincipalFromContext(ctx)
+	if !ok || principal.UserID == 0 {
+		return status.Error(codes.Unauthenticated, "user unauthenticated")
+	}
+	if principal.UserID == userID || principal.IsAdmin {
+		return nil
+	}
+
+	isAdmin, err := s.admins.IsAdmin(ctx, principal.UserID)
+	if err != nil {
+		return status.Error(codes.Internal, "groups internal server error")
+	}
+	if !isAdmin {
+		return status.Error(codes.PermissionDenied, "users can access only their own groups")
+	}
+
+	return nil
+}
+
+func groupError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrGroupNotFound):
+		return status.Error(codes.NotFound, "group not found")
+	case errors.Is(err, storage.ErrUserNotFound):
+		return status.Error(codes.NotFound, "user not found")
+	case errors.Is(err, storage.ErrRoleNotFound):
+		return status.Error(codes.NotFound, "role not found")
+	case errors.Is(err, storage.ErrGroupExists):
+		return status.Error(codes.AlreadyExists, "group with this name already exists")
+	case errors.Is(err, storage.ErrGroupCycle):
+		return status.Error(codes.InvalidArgument, "group cannot be moved under itself or its subgroup")
+	case errors.Is(err, storage.ErrGroupHasSubgroups):
+		return status.Error(codes.FailedPrecondition, "group has subgroups")
+	}
+
+	return status.Error(codes.Internal, "groups internal server error")
+}
+
+func groupResponse(group models.Group) *grpgen.Group {
+	resp := &grpgen.Group{
+		GroupId:     group.ID,
+		Name:        group.Name,
+		Description: group.Description,
+		ParentId:    group.ParentID,
+		Roles:       make([]*grpgen.GroupRole, 0, len(group.Roles)),
+		CreatedAt:   group.CreatedAt.Format(time.RFC3339),
+	}
+	for _, role := range group.Roles {
+		resp.Roles = append(resp.Roles, &grpgen.GroupRole{
+			Role:  role.Role,
+			AppId: role.AppID,
+		})
+	}
+
+	return resp
+}
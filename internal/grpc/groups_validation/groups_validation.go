@@ -0,0 +1,54 @@
+package groupsvalidation
+
+import (
+	"sso/proto/generated/grpgen"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+func CreateGroupValidation(req *grpgen.CreateGroupRequest) error {
+	return validation.ValidateStruct(
+		req,
+		validation.Field(&req.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&req.Description, validation.Length(0, 1000)),
+		validation.Field(&req.ParentId, validation.Min(int64(0))),
+	)
+}
+
+func UpdateGroupValidation(req *grpgen.UpdateGroupRequest) error {
+	return validation.ValidateStruct(
+		req,
+		validation.Field(&req.GroupId, validation.Required, validation.Min(int64(1))),
+		validation.Field(&req.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&req.Description, validation.Length(0, 1000)),
+		validation.Field(&req.ParentId, validation.Min(int64(0))),
+	)
+}
+
+func MemberValidation(req *grpgen.MemberRequest) error {
+	return validation.ValidateStruct(
+		req,
+		validation.Field(&req.GroupId, validation.Required, validation.Min(int64(1))),
+		validation.Field(&req.UserId, validation.Required, validation.Min(int64(1))),
+	)
+}
+
+func GroupRoleValidation(req *grpgen.GroupRoleRequest) error {
+	return validation.ValidateStruct(
+		req,
+		validation.Field(&req.GroupId, validation.Required, validation.Min(int64(1))),
+		validation.Field(&req.Role, validation.Required, validation.Length(1, 100)),
+	)
+}
+
+func UserGroupsValidation(req *grpgen.UserGroupsRequest) error {
+	return validation.ValidateStruct(
+		req,
+		validation.Field(&req.UserId, validation.Required),
+	)
+}
+
+// GroupIDValidation is used by requests which carry only group id
+func GroupIDValidation(groupID int64) error {
+	return validation.Validate(groupID, validation.Required, validation.Min(int64(1)))
+}
@@ -3,63 +3,77 @@ package permissions
 import (
 	"context"
 	"errors"
-	"fmt"
+	"sso/internal/domain/models"
 	permvalidation "sso/internal/grpc/permissions_validation"
-	"sso/internal/services/authsvc"
+	"sso/internal/interceptors"
+	"sso/internal/services/policysvc"
 	"sso/internal/storage"
 	permgen "sso/proto/generated/permgen"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type PermService interface {
-	DeleteUsrPermission(
+	CheckDltUsrPermit(
 		ctx context.Context,
-		app_id uint64,
-		user_id int64,
+		userID int64,
+		appID uint64,
 	) (bool, error)
-	UpdateUsrPermission(
+	CheckUpdUsrPermit(
 		ctx context.Context,
-		app_id uint64,
-		user_id int64,
+		userID int64,
+		appID uint64,
 	) (bool, error)
-	DownloadPermission(
+	CheckDwnldPermission(
 		ctx context.Context,
-		app_id uint64,
-		user_id int64,
+		userID int64,
+		appID uint64,
 	) (bool, error)
-	ChangeOptionPermission(
+	CheckChgOptPerm(
 		ctx context.Context,
-		app_id uint64,
-		user_id int64,
+		userID int64,
+		appID uint64,
 	) (bool, error)
 }
 
+type PolicyService interface {
+	Export(ctx context.Context) ([]byte, error)
+	Import(ctx context.Context, document []byte, dryRun bool) ([]models.PolicyChange, error)
+}
+
+type AdminChecker interface {
+	IsAdmin(ctx context.Context, userID int64) (bool, error)
+}
+
 type serverAPII struct {
 	permgen.UnimplementedPermissionsServer
 	permissions PermService
+	policy      PolicyService
+	admins      AdminChecker
 }
 
-func Register(gRPC *grpc.Server, permissions PermService) {
-	permgen.RegisterPermissionsServer(gRPC, serverAPII{permissions: permissions})
+func Register(gRPC *grpc.Server, permissions PermService, policy PolicyService, admins AdminChecker) {
+	permgen.RegisterPermissionsServer(gRPC, &serverAPII{
+		permissions: permissions,
+		policy:      policy,
+		admins:      admins,
+	})
 }
 
-func (s *serverAPII) CheckUserDltPerm(ctx context.Context, req *permgen.DeleteRequest) (*permgen.DeleteResponse, error) {
-	const op = "gprc.Permissions.server.dltperm"
-
+func (s *serverAPII) DeleteUser(ctx context.Context, req *permgen.DeleteRequest) (*permgen.DeleteResponse, error) {
 	if err := permvalidation.ValidateDeletePerm(req); err != nil {
-		return nil, fmt.Errorf("%s:%w", op, err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	permission, err := s.permissions.DeleteUsrPermission(ctx, req.AppId, req.UserId)
-	if err != nil {
-		if errors.Is(err, authsvc.ErrInvalidCredentials) {
-			return &permgen.DeleteResponse{
-				Permission: false,
-			}, fmt.Errorf("%s forbidden: invalid credentials", op)
-		}
+	if err := s.requireSelfOrAdmin(ctx, req.UserId); err != nil {
+		return nil, err
+	}
 
-		return nil, storage.ErrAppNotFound
+	permission, err := s.permissions.CheckDltUsrPermit(ctx, req.UserId, req.AppId)
+	if err != nil {
+		return nil, permissionError(err)
 	}
 
 	return &permgen.DeleteResponse{
@@ -67,21 +81,18 @@ func (s *serverAPII) CheckUserDltPerm(ctx context.Context, req *permgen.DeleteRe
 	}, nil
 }
 
-func (s *serverAPII) CheckUserUpdatePerm(ctx context.Context, req *permgen.UpdateRequest) (*permgen.UpdateResponse, error) {
-	const op = "grpc.Permissions.server.updperm"
-
+func (s *serverAPII) UpdateUser(ctx context.Context, req *permgen.UpdateRequest) (*permgen.UpdateResponse, error) {
 	if err := permvalidation.ValidatePermUpdate(req); err != nil {
-		return nil, fmt.Errorf("%s:%w", op, err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.requireSelfOrAdmin(ctx, req.UserId); err != nil {
+		return nil, err
 	}
 
-	permission, err := s.permissions.UpdateUsrPermission(ctx, req.AppId, req.UserId)
+	permission, err := s.permissions.CheckUpdUsrPermit(ctx, req.UserId, req.AppId)
 	if err != nil {
-		if errors.Is(err, storage.ErrDoesntAllowed) {
-			return &permgen.UpdateResponse{
-				Permission: false,
-			}, fmt.Errorf("%s", op)
-		}
-		return nil, storage.ErrAppNotFound
+		return nil, permissionError(err)
 	}
 
 	return &permgen.UpdateResponse{
@@ -89,20 +100,18 @@ func (s *serverAPII) CheckUserUpdatePerm(ctx context.Context, req *permgen.Updat
 	}, nil
 }
 
-func (s *serverAPII) CheckDownloadPerm(ctx context.Context, req *permgen.DownloadRequest) (*permgen.DownloadResponse, error) {
-	const op = "grpc.Permissions.server.dwlperm"
-
+func (s *serverAPII) VideoPerm(ctx context.Context, req *permgen.DownloadRequest) (*permgen.DownloadResponse, error) {
 	if err := permvalidation.ValidateDownload(req); err != nil {
-		return nil, fmt.Errorf("%s:%w", op, err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.requireSelfOrAdmin(ctx, req.UserId); err != nil {
+		return nil, err
 	}
 
-	permission, err := s.permissions.DownloadPermission(ctx, req.AppId, req.UserId)
+	permission, err := s.permissions.CheckDwnldPermission(ctx, req.UserId, req.AppId)
 	if err != nil {
-		if errors.Is(err, storage.ErrDoesntAllowed) {
-			return &permgen.DownloadResponse{
-				Permission: false,
-			}, fmt.Errorf("%s", op)
-		}
+		return nil, permissionError(err)
 	}
 
 	return &permgen.DownloadResponse{
@@ -110,23 +119,109 @@ func (s *serverAPII) CheckDownloadPerm(ctx context.Context, req *permgen.Downloa
 	}, nil
 }
 
-func (s *serverAPII) CheckOptionChgPerm(ctx context.Context, req *permgen.ChangeOptionsRequest) (*permgen.ChangeOptionsResponse, error) {
-	const op = "grpc.Permissions.server.chgperm"
-
+func (s *serverAPII) ChangeOptions(ctx context.Context, req *permgen.ChangeOptionsRequest) (*permgen.ChangeOptionsResponse, error) {
 	if err := permvalidation.ValidatePermOption(req); err != nil {
-		return nil, fmt.Errorf("%s:%w", op, err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.requireSelfOrAdmin(ctx, req.UserId); err != nil {
+		return nil, err
 	}
 
-	permission, err := s.permissions.ChangeOptionPermission(ctx, req.AppId, req.UserId)
+	permission, err := s.permissions.CheckChgOptPerm(ctx, req.UserId, req.AppId)
 	if err != nil {
-		if errors.Is(err, storage.ErrDoesntAllowed) {
-			return &permgen.ChangeOptionsResponse{
-				Permission: false,
-			}, fmt.Errorf("%s", op)
-		}
+		return nil, permissionError(err)
 	}
 
 	return &permgen.ChangeOptionsResponse{
 		Permission: permission,
 	}, nil
 }
+
+// permissionError maps permission service errors to grpc statuses
+func permissionError(err error) error {
+	if errors.Is(err, storage.ErrAppNotFound) {
+		return status.Error(codes.NotFound, "app not found")
+	}
+
+	return status.Error(codes.Internal, "internal server error")
+}
+
+// requireAdmin lets only authenticated admins manage the RBAC model
+func (s *serverAPII) requireAdmin(ctx context.Context) error {
+	principal, ok := interceptors.PrincipalFromContext(ctx)
+	if !ok || principal.UserID == 0 {
+		return status.Error(codes.Unauthenticated, "user unauthenticated")
+	}
+	// admin-only methods are already checked by interceptor
+	if principal.IsAdmin {
+		return nil
+	}
+
+	isAdmin, err := s.admins.IsAdmin(ctx, principal.UserID)
+	if err != nil {
+		return status.Error(codes.Internal, "internal server error")
+	}
+	if !isAdmin {
+		return status.Error(codes.PermissionDenied, "users can access only their own data")
+	}
+
+	return nil
+}
+
+// requireSelfOrAdmin lets users check only their own permissions, admins can check anyone's
+func (s *serverAPII) requireSelfOrAdmin(ctx context.Context, userID int64) error {
+	callerID, ok := interceptors.UserIDFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "user unauthenticated")
+	}
+	if callerID == userID {
+		return nil
+	}
+
+	return s.requireAdmin(ctx)
+}
+
+func (s *serverAPII) ExportPolicy(ctx context.Context, req *permgen.ExportPolicyRequest) (*permgen.ExportPolicyResponse, error) {
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	document, err := s.policy.Export(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "unable to export policy")
+	}
+
+	return &permgen.ExportPolicyResponse{
+		Policy: string(document),
+	}, nil
+}
+
+func (s *serverAPII) ImportPolicy(ctx context.Context, req *permgen.ImportPolicyRequest) (*permgen.ImportPolicyResponse, error) {
+	if err := permvalidation.ValidateImportPolicy(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	changes, err := s.policy.Import(ctx, []byte(req.Policy), req.DryRun)
+	if err != nil {
+		if errors.Is(err, policysvc.ErrInvalidPolicy) || errors.Is(err, storage.ErrUserNotFound) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, "unable to import policy")
+	}
+
+	resp := &permgen.ImportPolicyResponse{
+		Changes: make([]string, 0, len(changes)),
+		Applied: !req.DryRun && len(changes) > 0,
+	}
+	for _, change := range changes {
+		resp.Changes = append(resp.Changes, change.String())
+	}
+
+	return resp, nil
+}
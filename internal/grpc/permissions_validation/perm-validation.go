@@ -9,31 +9,38 @@ import (
 func ValidatePermOption(req *permgen.ChangeOptionsRequest) error {
 	return validation.ValidateStruct(
 		req,
-		validation.Field(req.AppId, validation.Required, validation.Length(1, 3)),
-		validation.Field(req.UserId, validation.Required, validation.Length(1, 10)),
+		validation.Field(&req.AppId, validation.Required),
+		validation.Field(&req.UserId, validation.Required),
 	)
 }
 
 func ValidatePermUpdate(req *permgen.UpdateRequest) error {
 	return validation.ValidateStruct(
 		req,
-		validation.Field(req.AppId, validation.Required, validation.Length(1, 3)),
-		validation.Field(req.UserId, validation.Required, validation.Length(1, 10)),
+		validation.Field(&req.AppId, validation.Required),
+		validation.Field(&req.UserId, validation.Required),
 	)
 }
 
 func ValidateDeletePerm(req *permgen.DeleteRequest) error {
 	return validation.ValidateStruct(
 		req,
-		validation.Field(req.AppId, validation.Required, validation.Length(1, 3)),
-		validation.Field(req.UserId, validation.Required, validation.Length(1, 10)),
+		validation.Field(&req.AppId, validation.Required),
+		validation.Field(&req.UserId, validation.Required),
 	)
 }
 
 func ValidateDownload(req *permgen.DownloadRequest) error {
 	return validation.ValidateStruct(
 		req,
-		validation.Field(req.AppId, validation.Required, validation.Length(1, 3)),
-		validation.Field(req.UserId, validation.Required, validation.Length(1, 10)),
+		validation.Field(&req.AppId, validation.Required),
+		validation.Field(&req.UserId, validation.Required),
+	)
+}
+
+func ValidateImportPolicy(req *permgen.ImportPolicyRequest) error {
+	return validation.ValidateStruct(
+		req,
+		validation.Field(&req.Policy, validation.Required),
 	)
 }
@@ -0,0 +1,192 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"sso/internal/domain/models"
+	profilevalidation "sso/internal/grpc/profile_validation"
+	"sso/internal/interceptors"
+	"sso/internal/services/profilesvc"
+	"sso/internal/storage"
+	"sso/proto/generated/profgen"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type ProfileService interface {
+	Profile(ctx context.Context, userID int64) (models.Profile, error)
+	Update(ctx context.Context, userID int64, username string, avatar string) (models.Profile, error)
+	ChangeEmail(ctx context.Context, userID int64, email string, password string) (time.Time, error)
+	ConfirmEmail(ctx context.Context, userID int64, code string) (models.Profile, error)
+	ChangePassword(
+		ctx context.Context,
+		userID int64,
+		appID uint64,
+		scopes []string,
+		current string,
+		password string,
+	) (string, error)
+}
+
+// serverAPI serves the caller only, user is taken from token subject and never from request
+type serverAPI struct {
+	profgen.UnimplementedProfileServer
+	profiles ProfileService
+}
+
+func Register(gRPC *grpc.Server, profiles ProfileService) {
+	profgen.RegisterProfileServer(gRPC, &serverAPI{profiles: profiles})
+}
+
+func (s *serverAPI) GetProfile(ctx context.Context, _ *profgen.GetProfileRequest) (*profgen.UserProfile, error) {
+	caller, err := callerOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := s.profiles.Profile(ctx, caller.UserID)
+	if err != nil {
+		return nil, profileError(err)
+	}
+
+	return profileResponse(profile), nil
+}
+
+func (s *serverAPI) UpdateProfile(ctx context.Context, req *profgen.UpdateProfileRequest) (*profgen.UserProfile, error) {
+	if err := profilevalidation.UpdateProfileValidation(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	caller, err := callerOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := s.profiles.Update(ctx, caller.UserID, req.Username, req.Avatar)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserExists) {
+			return nil, status.Error(codes.AlreadyExists, "username is taken")
+		}
+
+		return nil, profileError(err)
+	}
+
+	return profileResponse(profile), nil
+}
+
+func (s *serverAPI) ChangeEmail(ctx context.Context, req *profgen.ChangeEmailRequest) (*profgen.ChangeEmailResponse, error) {
+	if err := profilevalidation.ChangeEmailValidation(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	caller, err := callerOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt, err := s.profiles.ChangeEmail(ctx, caller.UserID, req.NewEmail, req.Password)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserExists) {
+			return nil, status.Error(codes.AlreadyExists, "email is taken")
+		}
+
+		return nil, profileError(err)
+	}
+
+	return &profgen.ChangeEmailResponse{
+		PendingEmail: req.NewEmail,
+		ExpiresAt:    expiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+func (s *serverAPI) ConfirmEmail(ctx context.Context, req *profgen.ConfirmEmailRequest) (*profgen.UserProfile, error) {
+	if err := profilevalidation.ConfirmEmailValidation(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid code argument")
+	}
+
+	caller, err := callerOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := s.profiles.ConfirmEmail(ctx, caller.UserID, req.Code)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserExists) {
+			return nil, status.Error(codes.AlreadyExists, "email is taken")
+		}
+
+		return nil, profileError(err)
+	}
+
+	return profileResponse(profile), nil
+}
+
+func (s *serverAPI) ChangePassword(ctx context.Context, req *profgen.ChangePasswordRequest) (*profgen.ChangePasswordResponse, error) {
+	if err := profilevalidation.ChangePasswordValidation(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	caller, err := callerOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.profiles.ChangePassword(
+		ctx,
+		caller.UserID,
+		uint64(caller.AppID),
+		caller.Scopes,
+		req.CurrentPassword,
+		req.NewPassword,
+	)
+	if err != nil {
+		return nil, profileError(err)
+	}
+
+	return &profgen.ChangePasswordResponse{Token: token}, nil
+}
+
+// callerOf returns user of token, app credentials are not enough for profile methods
+func callerOf(ctx context.Context) (interceptors.Principal, error) {
+	principal, ok := interceptors.PrincipalFromContext(ctx)
+	if !ok || principal.UserID == 0 {
+		return interceptors.Principal{}, status.Error(codes.Unauthenticated, "user unauthenticated")
+	}
+
+	return principal, nil
+}
+
+func profileError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrUserNotFound):
+		return status.Error(codes.NotFound, "user not found")
+	case errors.Is(err, profilesvc.ErrInvalidPassword):
+		return status.Error(codes.PermissionDenied, "invalid current password")
+	case errors.Is(err, profilesvc.ErrSameEmail):
+		return status.Error(codes.InvalidArgument, "new email is the current one")
+	case errors.Is(err, profilesvc.ErrInvalidCode):
+		return status.Error(codes.InvalidArgument, "invalid or expired code")
+	}
+
+	return status.Error(codes.Internal, "profile internal server error")
+}
+
+func profileResponse(profile models.Profile) *profgen.UserProfile {
+	resp := &profgen.UserProfile{
+		UserId:       profile.UserID,
+		Username:     profile.Username,
+		Email:        profile.Email,
+		Avatar:       profile.Avatar,
+		PendingEmail: profile.PendingEmail,
+		CreatedAt:    profile.CreatedAt.Format(time.RFC3339),
+	}
+	if !profile.LastLogin.IsZero() {
+		resp.LastLogin = profile.LastLogin.Format(time.RFC3339)
+	}
+
+	return resp
+}
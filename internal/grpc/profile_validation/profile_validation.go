@@ -0,0 +1,42 @@
+package profilevalidation
+
+import (
+	authvalidation "sso/internal/grpc/auth_validation"
+	"sso/proto/generated/profgen"
+
+	"github.com/go-ozzo/ozzo-validation/is"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// limits are the same as on registration
+func UpdateProfileValidation(req *profgen.UpdateProfileRequest) error {
+	return validation.ValidateStruct(
+		req,
+		validation.Field(&req.Username, validation.Required, validation.Length(3, 20)),
+		validation.Field(&req.Avatar, validation.Length(0, 2048), is.URL),
+	)
+}
+
+func ChangeEmailValidation(req *profgen.ChangeEmailRequest) error {
+	return validation.ValidateStruct(
+		req,
+		validation.Field(&req.NewEmail, validation.Required, validation.By(authvalidation.IsValidEmail), validation.Length(4, 50)),
+		validation.Field(&req.Password, validation.Required),
+	)
+}
+
+func ConfirmEmailValidation(req *profgen.ConfirmEmailRequest) error {
+	return validation.ValidateStruct(
+		req,
+		validation.Field(&req.Code, validation.Required, is.Hexadecimal, validation.Length(64, 64)),
+	)
+}
+
+func ChangePasswordValidation(req *profgen.ChangePasswordRequest) error {
+	return validation.ValidateStruct(
+		req,
+		validation.Field(&req.CurrentPassword, validation.Required),
+		validation.Field(&req.NewPassword, validation.Required, validation.Length(6, 100)),
+	)
+}
@@ -1,8 +1,11 @@
 package status
 
 import (
+	"sso/internal/domain/models"
 	"sso/internal/dto"
 	statusvalidation "sso/internal/grpc/status_validation"
+	"sso/internal/interceptors"
+	"sso/internal/services/statsvc"
 	"sso/internal/storage"
 	stagen "sso/proto/generated/stagen"
 
@@ -12,6 +15,8 @@ import (
 
 	"context"
 	"errors"
+	"strconv"
+	"time"
 )
 
 type StatusSvc interface {
@@ -19,23 +24,46 @@ type StatusSvc interface {
 		ctx context.Context,
 		userID int64,
 	) (*dto.IsBannedRespStruct, error)
-	Lastlogin(
+	LastLogin(
 		ctx context.Context,
 		userID int64,
-	) (string, error)
-	CurrentRole(
+	) (time.Time, error)
+	CurrentUserRole(
 		ctx context.Context,
 		userID int64,
 	) (*dto.CurrentRoleRespStruct, error)
+	BanUser(
+		ctx context.Context,
+		ban models.Ban,
+	) (int64, error)
+	UnbanUser(
+		ctx context.Context,
+		userID int64,
+		unbannedBy int64,
+		reason string,
+	) error
+	LoginHistory(
+		ctx context.Context,
+		filter dto.LoginHistoryFilter,
+	) ([]models.LoginAttempt, int64, error)
+	Roles(
+		ctx context.Context,
+		userID int64,
+	) ([]dto.AppRolesStruct, error)
+}
+
+type AdminChecker interface {
+	IsAdmin(ctx context.Context, userID int64) (bool, error)
 }
 
 type serverAPI struct {
 	stagen.UnimplementedStatusServer
 	statusS StatusSvc
+	admins  AdminChecker
 }
 
-func Register(gRPC *grpc.Server, status StatusSvc) {
-	stagen.RegisterStatusServer(gRPC, &serverAPI{statusS: status})
+func Register(gRPC *grpc.Server, status StatusSvc, admins AdminChecker) {
+	stagen.RegisterStatusServer(gRPC, &serverAPI{statusS: status, admins: admins})
 }
 
 func (s *serverAPI) IsBanned(ctx context.Context, req *stagen.IsBannedRequest) (*stagen.IsBannedResponse, error) {
@@ -44,6 +72,10 @@ func (s *serverAPI) IsBanned(ctx context.Context, req *stagen.IsBannedRequest) (
 		return nil, status.Error(codes.InvalidArgument, "invalid user id argument")
 	}
 
+	if err := s.requireSelfOrAdmin(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
 	isBanned := &dto.IsBannedRespStruct{}
 
 	isBanned, err := s.statusS.IsBanned(ctx, req.UserId)
@@ -56,10 +88,16 @@ func (s *serverAPI) IsBanned(ctx context.Context, req *stagen.IsBannedRequest) (
 		return nil, status.Error(codes.Internal, "status internal server error")
 	}
 
-	return &stagen.IsBannedResponse{
+	resp := &stagen.IsBannedResponse{
 		IsBanned: isBanned.IsBanned,
 		Message:  isBanned.Message,
-	}, nil
+		Reason:   isBanned.Reason,
+	}
+	if !isBanned.ExpiresAt.IsZero() {
+		resp.ExpiresAt = isBanned.ExpiresAt.Format(time.RFC3339)
+	}
+
+	return resp, nil
 }
 
 func (s *serverAPI) LastLogin(ctx context.Context, req *stagen.LastLogRequest) (*stagen.LastLogResponse, error) {
@@ -68,7 +106,11 @@ func (s *serverAPI) LastLogin(ctx context.Context, req *stagen.LastLogRequest) (
 		return nil, status.Error(codes.InvalidArgument, "invalid user id argument")
 	}
 
-	lastLogin, err := s.statusS.Lastlogin(ctx, req.UserId)
+	if err := s.requireSelfOrAdmin(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	lastLogin, err := s.statusS.LastLogin(ctx, req.UserId)
 	if err != nil {
 		if errors.Is(err, storage.ErrUserNotFound) {
 
@@ -78,9 +120,12 @@ func (s *serverAPI) LastLogin(ctx context.Context, req *stagen.LastLogRequest) (
 		return nil, status.Error(codes.Internal, "status internal server error")
 	}
 
-	return &stagen.LastLogResponse{
-		Lastlogin: lastLogin,
-	}, nil
+	resp := &stagen.LastLogResponse{}
+	if !lastLogin.IsZero() {
+		resp.Lastlogin = lastLogin.Format(time.RFC3339)
+	}
+
+	return resp, nil
 }
 
 func (s *serverAPI) CurrentRole(ctx context.Context, req *stagen.RoleRequest) (*stagen.RoleResponse, error) {
@@ -89,9 +134,13 @@ func (s *serverAPI) CurrentRole(ctx context.Context, req *stagen.RoleRequest) (*
 		return nil, status.Error(codes.InvalidArgument, "invalid user id argument")
 	}
 
+	if err := s.requireSelfOrAdmin(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
 	currentRole := &dto.CurrentRoleRespStruct{}
 
-	currentRole, err := s.statusS.CurrentRole(ctx, req.UserId)
+	currentRole, err := s.statusS.CurrentUserRole(ctx, req.UserId)
 	if err != nil {
 		if errors.Is(err, storage.ErrUserNotFound) {
 
@@ -106,3 +155,196 @@ func (s *serverAPI) CurrentRole(ctx context.Context, req *stagen.RoleRequest) (*
 		Role:     currentRole.Role,
 	}, nil
 }
+
+// requireSelfOrAdmin lets users read only their own data, admins can read anyone's
+func (s *serverAPI) requireSelfOrAdmin(ctx context.Context, userID int64) error {
+	callerID, ok := interceptors.UserIDFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "user unauthenticated")
+	}
+	if callerID == userID {
+		return nil
+	}
+
+	_, err := s.requireAdmin(ctx)
+
+	return err
+}
+
+// requireAdmin returns id of the calling admin
+func (s *serverAPI) requireAdmin(ctx context.Context) (int64, error) {
+	principal, ok := interceptors.PrincipalFromContext(ctx)
+	if !ok || principal.UserID == 0 {
+		return 0, status.Error(codes.Unauthenticated, "user unauthenticated")
+	}
+	// admin-only methods are already checked by interceptor
+	if principal.IsAdmin {
+		return principal.UserID, nil
+	}
+
+	isAdmin, err := s.admins.IsAdmin(ctx, principal.UserID)
+	if err != nil {
+		return 0, status.Error(codes.Internal, "status internal server error")
+	}
+	if !isAdmin {
+		return 0, status.Error(codes.PermissionDenied, "users can access only their own data")
+	}
+
+	return principal.UserID, nil
+}
+
+func (s *serverAPI) BanUser(ctx context.Context, req *stagen.BanRequest) (*stagen.BanResponse, error) {
+
+	if err := statusvalidation.BanUserValidation(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	adminID, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ban := models.Ban{
+		UserID:   req.UserId,
+		BannedBy: adminID,
+		Reason:   req.Reason,
+	}
+	if req.ExpiresAt != "" {
+		ban.ExpiresAt, _ = time.Parse(time.RFC3339, req.ExpiresAt)
+	}
+
+	banID, err := s.statusS.BanUser(ctx, ban)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+
+			return nil, status.Error(codes.NotFound, "user with current id does not found")
+		}
+		if errors.Is(err, statsvc.ErrBanExpired) {
+
+			return nil, status.Error(codes.InvalidArgument, "ban expiry is in the past")
+		}
+
+		return nil, status.Error(codes.Internal, "status internal server error")
+	}
+
+	return &stagen.BanResponse{
+		BanId: banID,
+	}, nil
+}
+
+func (s *serverAPI) UnbanUser(ctx context.Context, req *stagen.UnbanRequest) (*stagen.UnbanResponse, error) {
+
+	if err := statusvalidation.UnbanUserValidation(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id argument")
+	}
+
+	adminID, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.statusS.UnbanUser(ctx, req.UserId, adminID, req.Reason); err != nil {
+		if errors.Is(err, storage.ErrUserNotBanned) {
+
+			return nil, status.Error(codes.FailedPrecondition, "user is not banned")
+		}
+
+		return nil, status.Error(codes.Internal, "status internal server error")
+	}
+
+	return &stagen.UnbanResponse{
+		Unbanned: true,
+	}, nil
+}
+
+func (s *serverAPI) LoginHistory(ctx context.Context, req *stagen.LoginHistoryRequest) (*stagen.LoginHistoryResponse, error) {
+
+	if err := statusvalidation.LoginHistoryValidation(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.requireSelfOrAdmin(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	filter := dto.LoginHistoryFilter{
+		UserID:   req.UserId,
+		PageSize: int(req.PageSize),
+	}
+	filter.From, _ = time.Parse(time.RFC3339, req.From)
+	filter.To, _ = time.Parse(time.RFC3339, req.To)
+	if req.PageToken != "" {
+		filter.BeforeID, _ = strconv.ParseInt(req.PageToken, 10, 64)
+	}
+
+	attempts, next, err := s.statusS.LoginHistory(ctx, filter)
+	if err != nil {
+
+		return nil, status.Error(codes.Internal, "status internal server error")
+	}
+
+	resp := &stagen.LoginHistoryResponse{
+		Attempts: make([]*stagen.LoginAttempt, 0, len(attempts)),
+	}
+	for _, attempt := range attempts {
+		resp.Attempts = append(resp.Attempts, &stagen.LoginAttempt{
+			Id:        attempt.ID,
+			Success:   attempt.Success,
+			Reason:    attempt.Reason,
+			Ip:        attempt.IP,
+			UserAgent: attempt.UserAgent,
+			AppId:     attempt.AppID,
+			CreatedAt: attempt.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	if next != 0 {
+		resp.NextPageToken = strconv.FormatInt(next, 10)
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) Roles(ctx context.Context, req *stagen.RolesRequest) (*stagen.RolesResponse, error) {
+
+	if err := statusvalidation.RolesValidation(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id argument")
+	}
+
+	if err := s.requireSelfOrAdmin(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	apps, err := s.statusS.Roles(ctx, req.UserId)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+
+			return nil, status.Error(codes.NotFound, "user with current id does not found")
+		}
+
+		return nil, status.Error(codes.Internal, "status internal server error")
+	}
+
+	resp := &stagen.RolesResponse{
+		Apps: make([]*stagen.AppRoles, 0, len(apps)),
+	}
+	for _, app := range apps {
+		appRoles := &stagen.AppRoles{
+			AppId:                app.AppID,
+			Roles:                make([]*stagen.RoleEntry, 0, len(app.Roles)),
+			EffectivePermissions: app.EffectivePermissions,
+			ExcludedPermissions:  app.ExcludedPermissions,
+		}
+		for _, role := range app.Roles {
+			appRoles.Roles = append(appRoles.Roles, &stagen.RoleEntry{
+				Role:              role.Role,
+				RoleDescription:   role.Description,
+				Permissions:       role.Permissions,
+				DeniedPermissions: role.DeniedPermissions,
+				Group:             role.Group,
+			})
+		}
+		resp.Apps = append(resp.Apps, appRoles)
+	}
+
+	return resp, nil
+}
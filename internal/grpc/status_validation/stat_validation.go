@@ -2,6 +2,9 @@ package statusvalidation
 
 import (
 	stagen "sso/proto/generated/stagen"
+	"time"
+
+	"github.com/go-ozzo/ozzo-validation/is"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 )
@@ -9,20 +12,55 @@ import (
 func IsBannedValidation(req *stagen.IsBannedRequest) error {
 	return validation.ValidateStruct(
 		req,
-		validation.Field(req.UserId, validation.Required, validation.Length(1, 10)),
+		validation.Field(&req.UserId, validation.Required),
 	)
 }
 
 func LastLoginValidation(req *stagen.LastLogRequest) error {
 	return validation.ValidateStruct(
 		req,
-		validation.Field(req.UserId, validation.Required, validation.Length(1, 10)),
+		validation.Field(&req.UserId, validation.Required),
 	)
 }
 
 func CurrentRoleRequest(req *stagen.RoleRequest) error {
 	return validation.ValidateStruct(
 		req,
-		validation.Field(req.UserId, validation.Required, validation.Length(1, 10)),
+		validation.Field(&req.UserId, validation.Required),
+	)
+}
+
+func BanUserValidation(req *stagen.BanRequest) error {
+	return validation.ValidateStruct(
+		req,
+		validation.Field(&req.UserId, validation.Required),
+		validation.Field(&req.Reason, validation.Required, validation.Length(3, 500)),
+		validation.Field(&req.ExpiresAt, validation.Date(time.RFC3339)),
+	)
+}
+
+func UnbanUserValidation(req *stagen.UnbanRequest) error {
+	return validation.ValidateStruct(
+		req,
+		validation.Field(&req.UserId, validation.Required),
+		validation.Field(&req.Reason, validation.Length(0, 500)),
+	)
+}
+
+func LoginHistoryValidation(req *stagen.LoginHistoryRequest) error {
+	return validation.ValidateStruct(
+		req,
+		validation.Field(&req.UserId, validation.Required),
+		validation.Field(&req.From, validation.Date(time.RFC3339)),
+		validation.Field(&req.To, validation.Date(time.RFC3339)),
+		validation.Field(&req.PageSize, validation.Min(0)),
+		validation.Field(&req.PageToken, is.Digit),
+	)
+}
+
+func RolesValidation(req *stagen.RolesRequest) error {
+	return validation.ValidateStruct(
+		req,
+		validation.Field(&req.UserId, validation.Required),
 	)
 }
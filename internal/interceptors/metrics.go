@@ -0,0 +1,52 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"sso/internal/lib/metric"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	metricUnary  = "unary"
+	metricStream = "stream"
+)
+
+// Metrics records rate, errors and duration of every method, status label is gRPC code
+type Metrics struct {
+	metrics metric.Metrics
+}
+
+func NewMetrics(metrics metric.Metrics) *Metrics {
+	return &Metrics{metrics: metrics}
+}
+
+func (m *Metrics) Unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+
+	resp, err := handler(ctx, req)
+
+	m.observe(metricUnary, info.FullMethod, start, err)
+
+	return resp, err
+}
+
+func (m *Metrics) Stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+
+	err := handler(srv, ss)
+
+	m.observe(metricStream, info.FullMethod, start, err)
+
+	return err
+}
+
+func (m *Metrics) observe(kind string, method string, start time.Time, err error) {
+	code := int(status.Code(err))
+
+	m.metrics.IncHits(code, kind, method)
+	m.metrics.ObserveResponseTime(code, kind, method, time.Since(start).Seconds())
+}
@@ -3,28 +3,71 @@ package interceptors
 import (
 	"context"
 	"errors"
-	"log"
+	"strconv"
+	"strings"
+
+	"sso/internal/domain/models"
+	"sso/internal/lib/tenant"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
 type authInterceptor struct {
 	validator Validator
+	admins    AdminChecker
+	apps      AppAuthenticator
+	sessions  SessionChecker
+	policy    MethodPolicy
+	certApps  map[string]int64
 }
 
 type Validator interface {
-	ValidateToken(ctx context.Context, token string) (string, error)
+	TokenClaims(ctx context.Context, token string) (models.TokenClaims, error)
+}
+
+type AdminChecker interface {
+	IsAdmin(ctx context.Context, userID int64) (bool, error)
+}
+
+// AppAuthenticator checks app credentials and returns tenant of app
+type AppAuthenticator interface {
+	AuthenticateApp(ctx context.Context, appID int64, secret string) (tenantID int64, err error)
+	// AppTenant resolves tenant of app which was identified by client certificate
+	AppTenant(ctx context.Context, appID int64) (int64, error)
+}
+
+// SessionChecker rejects signed tokens which were revoked: user is banned or changed password
+// after token was issued. Signature and exp alone do not tell it
+type SessionChecker interface {
+	SessionActive(ctx context.Context, claims models.TokenClaims) (bool, error)
 }
 
-func NewAuthInterceptor(validator Validator) (*authInterceptor, error) {
-	if validator == nil {
+// NewAuthInterceptor enforces policy, certApps maps common name of verified client certificate to app id
+func NewAuthInterceptor(
+	validator Validator,
+	admins AdminChecker,
+	apps AppAuthenticator,
+	sessions SessionChecker,
+	policy MethodPolicy,
+	certApps map[string]int64,
+) (*authInterceptor, error) {
+	if validator == nil || admins == nil || apps == nil || sessions == nil {
 		return nil, errors.New("unregistered user")
 	}
 
-	return &authInterceptor{validator: validator}, nil
+	return &authInterceptor{
+		validator: validator,
+		admins:    admins,
+		apps:      apps,
+		sessions:  sessions,
+		policy:    policy,
+		certApps:  certApps,
+	}, nil
 }
 
 const (
@@ -33,29 +76,146 @@ const (
 
 type contextKey string
 
-const UserIDKey contextKey = "user_id"
+const (
+	appIDHeader     = "x-app-id"
+	appSecretHeader = "x-app-secret"
+)
 
 func (ai *authInterceptor) UnaryAuthInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
-	md, ok := metadata.FromIncomingContext(ctx)
+	ctx, err := ai.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (ai *authInterceptor) StreamAuthInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := ai.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticate enforces access policy of method and puts resolved principal and its tenant to context
+func (ai *authInterceptor) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	access, ok := ai.policy[fullMethod]
 	if !ok {
-		return nil, status.Error(codes.Unauthenticated, "metadata is not provided")
+		return nil, status.Error(codes.PermissionDenied, "method has no access policy")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	switch access {
+	case AccessPublic:
+		return ctx, nil
+
+	case AccessApp:
+		principal, err := ai.appPrincipal(ctx, md)
+		if err != nil {
+			return nil, err
+		}
+
+		return withPrincipal(tenant.With(ctx, principal.TenantID), principal), nil
+
+	case AccessAuthenticated, AccessAdmin:
+		principal, err := ai.userPrincipal(ctx, md)
+		if err != nil {
+			return nil, err
+		}
+		ctx = tenant.With(ctx, principal.TenantID)
+
+		// admins manage only their own tenant
+		if access == AccessAdmin {
+			isAdmin, err := ai.admins.IsAdmin(ctx, principal.UserID)
+			if err != nil {
+				return nil, status.Error(codes.Internal, "internal error")
+			}
+			if !isAdmin {
+				return nil, status.Error(codes.PermissionDenied, "method is allowed only for admins")
+			}
+			principal.IsAdmin = true
+		}
+
+		return withPrincipal(ctx, principal), nil
 	}
 
-	token := md["authorization"]
+	return nil, status.Error(codes.PermissionDenied, "method has no access policy")
+}
+
+func (ai *authInterceptor) userPrincipal(ctx context.Context, md metadata.MD) (Principal, error) {
+	token := md.Get("authorization")
 	if len(token) == ZeroIntValue {
-		return nil, status.Error(codes.Unauthenticated, "invalid token provide")
+		return Principal{}, status.Error(codes.Unauthenticated, "invalid token provide")
 	}
 
-	log.Printf("recieved request on method %s", info.FullMethod)
+	claims, err := ai.validator.TokenClaims(ctx, strings.TrimPrefix(token[0], "Bearer "))
+	if err != nil {
+		return Principal{}, status.Error(codes.Unauthenticated, "user unauthenticated")
+	}
+
+	tenantID := tenant.OrDefault(claims.TenantID)
 
-	userID, err := ai.validator.ValidateToken(ctx, token[0])
+	active, err := ai.sessions.SessionActive(tenant.With(ctx, tenantID), claims)
 	if err != nil {
-		return nil, status.Error(codes.Unauthenticated, "user unauthenticated")
+		return Principal{}, status.Error(codes.Internal, "internal error")
+	}
+	if !active {
+		return Principal{}, status.Error(codes.Unauthenticated, "token was revoked")
 	}
 
-	ctx = context.WithValue(ctx, UserIDKey, userID)
+	return Principal{
+		UserID:   claims.UserID,
+		AppID:    claims.AppID,
+		TenantID: tenantID,
+		Scopes:   claims.Scopes,
+	}, nil
+}
 
-	log.Printf("sending response on method %s", info.FullMethod)
+func (ai *authInterceptor) appPrincipal(ctx context.Context, md metadata.MD) (Principal, error) {
+	// mTLS callers are identified by certificate, they need no app secret
+	if appID, ok := ai.certApp(ctx); ok {
+		tenantID, err := ai.apps.AppTenant(ctx, appID)
+		if err != nil {
+			return Principal{}, status.Error(codes.Unauthenticated, "app unauthenticated")
+		}
 
-	return handler(ctx, req)
+		return Principal{AppID: appID, TenantID: tenantID, IsApp: true}, nil
+	}
+
+	ids, secrets := md.Get(appIDHeader), md.Get(appSecretHeader)
+	if len(ids) == ZeroIntValue || len(secrets) == ZeroIntValue {
+		return Principal{}, status.Error(codes.Unauthenticated, "app credentials are not provided")
+	}
+
+	appID, err := strconv.ParseInt(ids[0], 10, 64)
+	if err != nil {
+		return Principal{}, status.Error(codes.Unauthenticated, "invalid app id")
+	}
+
+	tenantID, err := ai.apps.AuthenticateApp(ctx, appID, secrets[0])
+	if err != nil {
+		return Principal{}, status.Error(codes.Unauthenticated, "app unauthenticated")
+	}
+
+	return Principal{AppID: appID, TenantID: tenantID, IsApp: true}, nil
+}
+
+// certApp maps verified client certificate to app, unverified certificates are ignored
+func (ai *authInterceptor) certApp(ctx context.Context) (int64, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ZeroIntValue, false
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == ZeroIntValue || len(tlsInfo.State.VerifiedChains[0]) == ZeroIntValue {
+		return ZeroIntValue, false
+	}
+
+	appID, ok := ai.certApps[tlsInfo.State.VerifiedChains[0][0].Subject.CommonName]
+
+	return appID, ok
 }
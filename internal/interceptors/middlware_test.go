@@ -0,0 +1,254 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"sso/internal/domain/models"
+	"sso/internal/lib/tenant"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	userToken   = "user-token"
+	adminToken  = "admin-token"
+	appSecret   = "app-secret"
+	tokenTenant = 3
+	appTenant   = 5
+)
+
+var errBadToken = errors.New("bad token")
+
+type fakeValidator struct{}
+
+func (fakeValidator) TokenClaims(_ context.Context, token string) (models.TokenClaims, error) {
+	switch token {
+	case userToken:
+		return models.TokenClaims{UserID: 1, AppID: 1, TenantID: tokenTenant}, nil
+	case adminToken:
+		return models.TokenClaims{UserID: 2, AppID: 1, TenantID: tokenTenant}, nil
+	}
+
+	return models.TokenClaims{}, errBadToken
+}
+
+type fakeAdmins struct{}
+
+func (fakeAdmins) IsAdmin(_ context.Context, userID int64) (bool, error) {
+	return userID == 2, nil
+}
+
+type fakeApps struct{}
+
+func (fakeApps) AuthenticateApp(_ context.Context, appID int64, secret string) (int64, error) {
+	if appID != 1 || secret != appSecret {
+		return 0, errBadToken
+	}
+
+	return appTenant, nil
+}
+
+func (fakeApps) AppTenant(context.Context, int64) (int64, error) {
+	return appTenant, nil
+}
+
+// fakeSessions reports sessions of listed users as revoked
+type fakeSessions struct {
+	revoked map[int64]bool
+	tenants []int64
+}
+
+func (s *fakeSessions) SessionActive(ctx context.Context, claims models.TokenClaims) (bool, error) {
+	id, _ := tenant.FromContext(ctx)
+	s.tenants = append(s.tenants, id)
+
+	return !s.revoked[claims.UserID], nil
+}
+
+func newTestInterceptor(t *testing.T, sessions *fakeSessions) *authInterceptor {
+	t.Helper()
+
+	ai, err := NewAuthInterceptor(fakeValidator{}, fakeAdmins{}, fakeApps{}, sessions, MethodPolicy{
+		"/test/Public":        AccessPublic,
+		"/test/App":           AccessApp,
+		"/test/Authenticated": AccessAuthenticated,
+		"/test/Admin":         AccessAdmin,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewAuthInterceptor: %v", err)
+	}
+
+	return ai
+}
+
+func incoming(pairs ...string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(pairs...))
+}
+
+func TestAuthenticateRevokedToken(t *testing.T) {
+	sessions := &fakeSessions{revoked: map[int64]bool{1: true}}
+	ai := newTestInterceptor(t, sessions)
+
+	_, err := ai.authenticate(incoming("authorization", "Bearer "+userToken), "/test/Authenticated")
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("revoked token: got %v, want Unauthenticated", err)
+	}
+
+	if len(sessions.tenants) != 1 || sessions.tenants[0] != tokenTenant {
+		t.Errorf("session is checked in tenants %v, want [%d]", sessions.tenants, tokenTenant)
+	}
+
+	ctx, err := ai.authenticate(incoming("authorization", "Bearer "+adminToken), "/test/Authenticated")
+	if err != nil {
+		t.Fatalf("active token: %v", err)
+	}
+	if p, ok := PrincipalFromContext(ctx); !ok || p.UserID != 2 {
+		t.Errorf("principal = %+v, want user 2", p)
+	}
+}
+
+func TestAuthenticatePolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		md         []string
+		wantCode   codes.Code
+		wantUser   int64
+		wantApp    int64
+		wantTenant int64
+		wantAdmin  bool
+	}{
+		{
+			name:     "method without policy is denied",
+			method:   "/test/Unlisted",
+			md:       []string{"authorization", "Bearer " + adminToken},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "public method needs no credentials",
+			method:   "/test/Public",
+			wantCode: codes.OK,
+		},
+		{
+			name:       "app method with app credentials",
+			method:     "/test/App",
+			md:         []string{appIDHeader, "1", appSecretHeader, appSecret},
+			wantCode:   codes.OK,
+			wantApp:    1,
+			wantTenant: appTenant,
+		},
+		{
+			name:     "app method with wrong secret",
+			method:   "/test/App",
+			md:       []string{appIDHeader, "1", appSecretHeader, "guess"},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "app method with invalid app id",
+			method:   "/test/App",
+			md:       []string{appIDHeader, "one", appSecretHeader, appSecret},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "app method does not accept user token",
+			method:   "/test/App",
+			md:       []string{"authorization", "Bearer " + userToken},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "authenticated method without token",
+			method:   "/test/Authenticated",
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "authenticated method with invalid token",
+			method:   "/test/Authenticated",
+			md:       []string{"authorization", "Bearer forged"},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "authenticated method does not accept app credentials",
+			method:   "/test/Authenticated",
+			md:       []string{appIDHeader, "1", appSecretHeader, appSecret},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:       "authenticated method with user token",
+			method:     "/test/Authenticated",
+			md:         []string{"authorization", "Bearer " + userToken},
+			wantCode:   codes.OK,
+			wantUser:   1,
+			wantApp:    1,
+			wantTenant: tokenTenant,
+		},
+		{
+			name:     "admin method with token of non-admin",
+			method:   "/test/Admin",
+			md:       []string{"authorization", "Bearer " + userToken},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "admin method without token",
+			method:   "/test/Admin",
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:       "admin method with token of admin",
+			method:     "/test/Admin",
+			md:         []string{"authorization", "Bearer " + adminToken},
+			wantCode:   codes.OK,
+			wantUser:   2,
+			wantApp:    1,
+			wantTenant: tokenTenant,
+			wantAdmin:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ai := newTestInterceptor(t, &fakeSessions{})
+
+			ctx, err := ai.authenticate(incoming(tt.md...), tt.method)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("authenticate() error = %v, want code %v", err, tt.wantCode)
+			}
+			if err != nil || tt.method == "/test/Public" {
+				return
+			}
+
+			p, ok := PrincipalFromContext(ctx)
+			if !ok {
+				t.Fatal("no principal in context")
+			}
+			if p.UserID != tt.wantUser || p.AppID != tt.wantApp || p.IsAdmin != tt.wantAdmin {
+				t.Errorf("principal = %+v, want user %d app %d admin %v", p, tt.wantUser, tt.wantApp, tt.wantAdmin)
+			}
+			if p.IsApp != (tt.wantUser == 0) {
+				t.Errorf("principal IsApp = %v for user %d", p.IsApp, tt.wantUser)
+			}
+
+			if id, ok := tenant.FromContext(ctx); !ok || id != tt.wantTenant {
+				t.Errorf("tenant in context = %d, want %d", id, tt.wantTenant)
+			}
+			if p.TenantID != tt.wantTenant {
+				t.Errorf("principal tenant = %d, want %d", p.TenantID, tt.wantTenant)
+			}
+		})
+	}
+}
+
+func TestAuthenticatePublicHasNoPrincipal(t *testing.T) {
+	ai := newTestInterceptor(t, &fakeSessions{})
+
+	ctx, err := ai.authenticate(incoming("authorization", "Bearer "+adminToken), "/test/Public")
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if p, ok := PrincipalFromContext(ctx); ok {
+		t.Errorf("public method got principal %+v", p)
+	}
+}
@@ -0,0 +1,33 @@
+package interceptors
+
+// Access is what caller has to present to call a method
+type Access int
+
+const (
+	// no credentials required
+	AccessPublic Access = iota + 1
+	// valid user token
+	AccessAuthenticated
+	// valid token of admin user
+	AccessAdmin
+	// app id and secret from x-app-id and x-app-secret metadata
+	AccessApp
+)
+
+func (a Access) String() string {
+	switch a {
+	case AccessPublic:
+		return "public"
+	case AccessAuthenticated:
+		return "authenticated"
+	case AccessAdmin:
+		return "admin"
+	case AccessApp:
+		return "app"
+	}
+
+	return "unknown"
+}
+
+// MethodPolicy maps full method name to required access, methods missing in policy are denied
+type MethodPolicy map[string]Access
@@ -0,0 +1,52 @@
+package interceptors
+
+import (
+	"context"
+)
+
+// Principal is the caller resolved by auth interceptor
+type Principal struct {
+	UserID int64 // 0 when caller is an app
+	AppID  int64
+	// tenant of token or of app, requests are served inside it
+	TenantID int64
+	Scopes   []string
+	// set only by admin-only methods, other methods have to check admin rights themselves
+	IsAdmin bool
+	// caller has authenticated with app id and secret instead of user token
+	IsApp bool
+}
+
+const principalKey contextKey = "principal"
+
+func withPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// PrincipalFromContext returns caller of authenticated method
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey).(Principal)
+
+	return p, ok
+}
+
+// UserIDFromContext returns id of authenticated user, false for public methods and app callers
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	p, ok := PrincipalFromContext(ctx)
+	if !ok || p.UserID == ZeroIntValue {
+		return ZeroIntValue, false
+	}
+
+	return p.UserID, true
+}
+
+// HasScope reports whether token of caller grants scope
+func (p Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,58 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	requestIDHeader            = "x-request-id"
+	RequestIDKey    contextKey = "request_id"
+)
+
+// UnaryRequestID takes request id from x-request-id metadata or generates a new one,
+// puts it to context and returns it to client in header
+func UnaryRequestID(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx = withRequestID(ctx)
+
+	return handler(ctx, req)
+}
+
+func StreamRequestID(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx := withRequestID(ss.Context())
+
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// RequestIDFromContext returns id of request, which has been put to context by request id interceptor
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(RequestIDKey).(string)
+
+	return id
+}
+
+func withRequestID(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var id string
+	if ids := md.Get(requestIDHeader); len(ids) > 0 && ids[0] != "" {
+		id = ids[0]
+	} else {
+		id = newRequestID()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, id))
+
+	return context.WithValue(ctx, RequestIDKey, id)
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,49 @@
+package appsecret
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// secretBytes gives 256 bit secrets, so plain sha256 is enough to store them,
+// slow password hashes would only make every app call expensive
+const secretBytes = 32
+
+// Generate returns new random secret and its hash
+func Generate() (secret string, hash string, err error) {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	secret = hex.EncodeToString(b)
+
+	return secret, Hash(secret), nil
+}
+
+// Hash returns hex sha256 of secret, the same as migration computes for old plain secrets
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Matches compares secret with every hash in constant time
+func Matches(hashes []string, secret string) bool {
+	if secret == "" {
+		return false
+	}
+
+	got := []byte(Hash(secret))
+
+	matched := false
+	for _, hash := range hashes {
+		if subtle.ConstantTimeCompare([]byte(hash), got) == 1 {
+			matched = true
+		}
+	}
+
+	return matched
+}
@@ -0,0 +1,56 @@
+package appsecret
+
+import "testing"
+
+func TestMatches(t *testing.T) {
+	secret, hash, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	other, otherHash, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		hashes []string
+		secret string
+		want   bool
+	}{
+		{name: "current secret", hashes: []string{hash}, secret: secret, want: true},
+		{name: "previous secret during overlap", hashes: []string{otherHash, hash}, secret: secret, want: true},
+		{name: "second secret of two", hashes: []string{hash, otherHash}, secret: other, want: true},
+		{name: "wrong secret", hashes: []string{hash}, secret: other, want: false},
+		{name: "no hashes", hashes: nil, secret: secret, want: false},
+		{name: "empty secret", hashes: []string{hash, Hash("")}, secret: "", want: false},
+		{name: "hash is not accepted as secret", hashes: []string{hash}, secret: hash, want: false},
+		{name: "plain secret stored instead of hash", hashes: []string{secret}, secret: secret, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Matches(tt.hashes, tt.secret); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateIsRandom(t *testing.T) {
+	first, _, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	second, _, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	if first == second {
+		t.Error("two generated secrets are equal")
+	}
+	if len(first) != 2*secretBytes {
+		t.Errorf("secret has %d hex chars, want %d", len(first), 2*secretBytes)
+	}
+}
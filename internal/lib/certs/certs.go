@@ -0,0 +1,219 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoCertificates        = errors.New("no certificates found in CA bundle")
+	ErrInvalidReloadInterval = errors.New("reload interval must be positive")
+)
+
+// Reloader serves key pair from files and picks up renewed files without restart
+type Reloader struct {
+	log      *slog.Logger
+	certFile string
+	keyFile  string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// NewReloader loads key pair, files have to be valid on start
+func NewReloader(log *slog.Logger, certFile, keyFile string) (*Reloader, error) {
+	const op = "certs.NewReloader"
+
+	r := &Reloader{
+		log:      log,
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	if _, err := r.reload(); err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return r, nil
+}
+
+// GetCertificate is tls.Config.GetCertificate of server
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// CommonName returns common name of subject of current certificate
+func (r *Reloader) CommonName() (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	leaf := r.cert.Leaf
+	if leaf == nil {
+		var err error
+		if leaf, err = x509.ParseCertificate(r.cert.Certificate[0]); err != nil {
+			return "", err
+		}
+	}
+
+	return leaf.Subject.CommonName, nil
+}
+
+// GetClientCertificate is tls.Config.GetClientCertificate of client
+func (r *Reloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.GetCertificate(nil)
+}
+
+// Watch checks files every interval until ctx is done, broken files keep previous key pair in use.
+// Files are not watched when interval is not positive
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	const op = "certs.Watch"
+
+	log := r.log.With(slog.String("op", op), slog.String("cert", r.certFile))
+
+	if interval <= 0 {
+		log.Warn("certificate is not watched", slog.Duration("interval", interval))
+
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.reload()
+			if err != nil {
+				log.Warn("cannot reload certificate", slog.Any("err", err))
+
+				continue
+			}
+			if reloaded {
+				log.Info("certificate reloaded")
+			}
+		}
+	}
+}
+
+// reload reads files when any of them has changed since last load
+func (r *Reloader) reload() (bool, error) {
+	modTime, err := latestModTime(r.certFile, r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	unchanged := r.cert != nil && modTime.Equal(r.modTime)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+func latestModTime(files ...string) (time.Time, error) {
+	var latest time.Time
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
+
+// ServerConfig returns TLS config of listener, client certificates are verified against clientCAFile when it is set
+func ServerConfig(cert *Reloader, clientCAFile string, requireClientCert bool) (*tls.Config, error) {
+	const op = "certs.ServerConfig"
+
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: cert.GetCertificate,
+	}
+
+	if clientCAFile == "" {
+		return cfg, nil
+	}
+
+	pool, err := LoadCertPool(clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	cfg.ClientCAs = pool
+	// callers without certificate still can authenticate with tokens
+	cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	if requireClientCert {
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return cfg, nil
+}
+
+// ClientConfig returns TLS config of dialer, system roots are used when caFile is empty, cert can be nil
+func ClientConfig(caFile, serverName string, cert *Reloader) (*tls.Config, error) {
+	const op = "certs.ClientConfig"
+
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+
+	if caFile != "" {
+		pool, err := LoadCertPool(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%w", op, err)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if cert != nil {
+		cfg.GetClientCertificate = cert.GetClientCertificate
+	}
+
+	return cfg, nil
+}
+
+// LoadCertPool reads PEM bundle of CA certificates
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%w: %s", ErrNoCertificates, file)
+	}
+
+	return pool, nil
+}
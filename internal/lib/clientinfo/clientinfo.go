@@ -0,0 +1,142 @@
+package clientinfo
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// Info describes the client which sent request
+type Info struct {
+	IP        string
+	UserAgent string
+}
+
+type contextKey struct{}
+
+// Proxies are networks of trusted reverse proxies, only they may report address of client in metadata
+type Proxies []*net.IPNet
+
+// ParseProxies parses CIDRs of trusted proxies, single addresses are accepted as well
+func ParseProxies(cidrs []string) (Proxies, error) {
+	const op = "clientinfo.ParseProxies"
+
+	proxies := make(Proxies, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("%s: invalid proxy address %q", op, cidr)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%w", op, err)
+		}
+		proxies = append(proxies, network)
+	}
+
+	return proxies, nil
+}
+
+// Trusted reports whether ip belongs to one of trusted proxies
+func (p Proxies) Trusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, network := range p {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FromIncoming reads client info of gRPC request.
+//
+// IP is the peer address, unless the peer is a trusted proxy. Then x-forwarded-for
+// is walked from the right and the first hop which is not a trusted proxy is taken,
+// x-real-ip is used when proxy sent no x-forwarded-for
+func FromIncoming(ctx context.Context, proxies Proxies) Info {
+	var info Info
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		info.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(info.IP); err == nil {
+			info.IP = host
+		}
+	}
+
+	if info.IP != "" && proxies.Trusted(info.IP) {
+		if ip := forwardedFor(md.Get("x-forwarded-for"), proxies); ip != "" {
+			info.IP = ip
+		} else if realIP := md.Get("x-real-ip"); len(realIP) > 0 && net.ParseIP(strings.TrimSpace(realIP[0])) != nil {
+			info.IP = strings.TrimSpace(realIP[0])
+		}
+	}
+
+	// http gateway puts user agent of browser to grpcgateway-user-agent
+	if ua := md.Get("grpcgateway-user-agent"); len(ua) > 0 {
+		info.UserAgent = ua[0]
+	} else if ua := md.Get("user-agent"); len(ua) > 0 {
+		info.UserAgent = ua[0]
+	}
+
+	return info
+}
+
+// forwardedFor returns the right-most hop which is not a trusted proxy,
+// hops left of it were written by the client itself and can not be trusted
+func forwardedFor(values []string, proxies Proxies) string {
+	var hops []string
+	for _, value := range values {
+		for _, hop := range strings.Split(value, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		if net.ParseIP(hops[i]) == nil {
+			// garbage in the chain, nothing left of it is reliable
+			return ""
+		}
+		if !proxies.Trusted(hops[i]) || i == 0 {
+			return hops[i]
+		}
+	}
+
+	return ""
+}
+
+func NewContext(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(contextKey{}).(Info)
+
+	return info
+}
@@ -0,0 +1,134 @@
+package clientinfo
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestFromIncoming(t *testing.T) {
+	proxies, err := ParseProxies([]string{"127.0.0.1/32", "::1", "10.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("ParseProxies: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		peer string
+		md   []string
+		want string
+	}{
+		{
+			name: "no metadata",
+			peer: "203.0.113.7:5000",
+			want: "203.0.113.7",
+		},
+		{
+			name: "forwarded header from untrusted peer is ignored",
+			peer: "203.0.113.7:5000",
+			md:   []string{"x-forwarded-for", "198.51.100.1"},
+			want: "203.0.113.7",
+		},
+		{
+			name: "real ip header from untrusted peer is ignored",
+			peer: "203.0.113.7:5000",
+			md:   []string{"x-real-ip", "198.51.100.1"},
+			want: "203.0.113.7",
+		},
+		{
+			name: "gateway on loopback",
+			peer: "127.0.0.1:41000",
+			md:   []string{"x-forwarded-for", "198.51.100.1"},
+			want: "198.51.100.1",
+		},
+		{
+			name: "gateway on ipv6 loopback",
+			peer: "[::1]:41000",
+			md:   []string{"x-forwarded-for", "2001:db8::1"},
+			want: "2001:db8::1",
+		},
+		{
+			name: "spoofed hops left of the real client are skipped",
+			peer: "127.0.0.1:41000",
+			md:   []string{"x-forwarded-for", "1.2.3.4, 198.51.100.1"},
+			want: "198.51.100.1",
+		},
+		{
+			name: "trusted hops are skipped",
+			peer: "127.0.0.1:41000",
+			md:   []string{"x-forwarded-for", "1.2.3.4, 198.51.100.1, 10.1.2.3"},
+			want: "198.51.100.1",
+		},
+		{
+			name: "chain of trusted hops gives left-most",
+			peer: "127.0.0.1:41000",
+			md:   []string{"x-forwarded-for", "10.0.0.5, 10.1.2.3"},
+			want: "10.0.0.5",
+		},
+		{
+			name: "several header values",
+			peer: "127.0.0.1:41000",
+			md:   []string{"x-forwarded-for", "1.2.3.4", "x-forwarded-for", "198.51.100.1"},
+			want: "198.51.100.1",
+		},
+		{
+			name: "garbage hop falls back to peer",
+			peer: "127.0.0.1:41000",
+			md:   []string{"x-forwarded-for", "198.51.100.1, not-an-ip"},
+			want: "127.0.0.1",
+		},
+		{
+			name: "real ip from trusted peer",
+			peer: "127.0.0.1:41000",
+			md:   []string{"x-real-ip", "198.51.100.1"},
+			want: "198.51.100.1",
+		},
+		{
+			name: "forwarded for wins over real ip",
+			peer: "127.0.0.1:41000",
+			md:   []string{"x-real-ip", "1.2.3.4", "x-forwarded-for", "198.51.100.1"},
+			want: "198.51.100.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, err := net.ResolveTCPAddr("tcp", tt.peer)
+			if err != nil {
+				t.Fatalf("ResolveTCPAddr: %v", err)
+			}
+
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(tt.md...))
+
+			if got := FromIncoming(ctx, proxies).IP; got != tt.want {
+				t.Errorf("IP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromIncomingUserAgent(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"user-agent", "grpc-go/1.69",
+		"grpcgateway-user-agent", "Mozilla/5.0",
+	))
+
+	if got := FromIncoming(ctx, nil).UserAgent; got != "Mozilla/5.0" {
+		t.Errorf("UserAgent = %q, want browser user agent", got)
+	}
+}
+
+func TestParseProxies(t *testing.T) {
+	if _, err := ParseProxies([]string{"10.0.0.0/8", " 192.168.1.1 ", ""}); err != nil {
+		t.Errorf("valid proxies: %v", err)
+	}
+	for _, invalid := range []string{"10.0.0.0/33", "gateway"} {
+		if _, err := ParseProxies([]string{invalid}); err == nil {
+			t.Errorf("ParseProxies(%q) succeeded, want error", invalid)
+		}
+	}
+}
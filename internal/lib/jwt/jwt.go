@@ -2,9 +2,14 @@ package jwtlib
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"sso/internal/domain/models"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -12,10 +17,15 @@ import (
 
 type service struct {
 	secret []byte
+
+	mu     sync.RWMutex
+	active *models.SigningKey
+	keys   map[string][]byte
 }
 
 var (
 	ErrInvalidToken = errors.New("invalid jwt-token")
+	ErrRefreshToken = errors.New("refresh token cannot be used as access token")
 )
 
 func NewService(secret string) (*service, error) {
@@ -29,17 +39,60 @@ func NewService(secret string) (*service, error) {
 	}, nil
 }
 
-func (s *service) NewToken(user models.User, app models.App, duration time.Duration) (string, error) {
+// NewToken signs access token of user in app, grant is embedded as scope, roles and perms claims
+func (s *service) NewToken(user models.User, app models.App, duration time.Duration, grant models.TokenGrant) (string, error) {
+	return s.newToken(models.TokenTypeAccess, user, app, duration, grant)
+}
+
+// NewRefreshToken signs refresh token of user in app, it carries no grant and is rejected as access token
+func (s *service) NewRefreshToken(user models.User, app models.App, duration time.Duration) (string, error) {
+	return s.newToken(models.TokenTypeRefresh, user, app, duration, models.TokenGrant{})
+}
+
+func (s *service) newToken(typ string, user models.User, app models.App, duration time.Duration, grant models.TokenGrant) (string, error) {
 	token := jwt.New(jwt.SigningMethodHS256)
 
 	claims := token.Claims.(jwt.MapClaims)
 
+	jti, err := tokenID()
+	if err != nil {
+		return "", fmt.Errorf("failed to gen new token: %w", err)
+	}
+
+	now := time.Now()
+
+	claims["typ"] = typ
 	claims["uid"] = user.ID
 	claims["email"] = user.Email
-	claims["exp"] = time.Now().Add(duration).Unix()
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(duration).Unix()
 	claims["app_id"] = app.ID
+	claims["tid"] = user.TenantID
+	// resource servers revoke single tokens by jti
+	claims["jti"] = jti
+	if app.Tokens.Audience != "" {
+		claims["aud"] = app.Tokens.Audience
+	}
+	if len(grant.Scopes) > 0 {
+		claims["scope"] = strings.Join(grant.Scopes, " ")
+	}
+	// empty list is kept, it tells resource server that user has no roles or permissions at all
+	if grant.Roles != nil {
+		claims["roles"] = grant.Roles
+	}
+	if grant.Permissions != nil {
+		claims["perms"] = grant.Permissions
+	}
 
-	tokenString, err := token.SignedString([]byte(s.secret))
+	kid, secret, err := s.signingKey(app.Tokens.SigningKeyID)
+	if err != nil {
+		return "", fmt.Errorf("failed to gen new token: %w", err)
+	}
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	tokenString, err := token.SignedString(secret)
 	if err != nil {
 		return "", fmt.Errorf("failed to gen new token: %w", err)
 	}
@@ -47,26 +100,120 @@ func (s *service) NewToken(user models.User, app models.App, duration time.Durat
 	return tokenString, nil
 }
 
-func (s *service) ValidateToken(_ context.Context, token string) (string, error) {
+func tokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// ValidateToken returns id of user from valid token
+func (s *service) ValidateToken(ctx context.Context, token string) (string, error) {
+	claims, err := s.TokenClaims(ctx, token)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.FormatInt(claims.UserID, 10), nil
+}
+
+// TokenClaims validates access token and returns its claims, refresh tokens are rejected
+func (s *service) TokenClaims(ctx context.Context, token string) (models.TokenClaims, error) {
+	claims, err := s.ParseClaims(ctx, token)
+	if err != nil {
+		return models.TokenClaims{}, err
+	}
+
+	if claims.Type != models.TokenTypeAccess {
+		return models.TokenClaims{}, fmt.Errorf("%w: %w", ErrInvalidToken, ErrRefreshToken)
+	}
+
+	return claims, nil
+}
+
+// ParseClaims validates token of any type and returns its claims, Type tells access token from refresh one
+func (s *service) ParseClaims(_ context.Context, token string) (models.TokenClaims, error) {
 	t, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return "", fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return s.secret, nil
+		return s.verificationKey(token)
 	})
 	if err != nil {
-		return "", errors.Join(ErrInvalidToken, err)
+		return models.TokenClaims{}, errors.Join(ErrInvalidToken, err)
 	}
 
-	if claims, ok := t.Claims.(jwt.MapClaims); ok && t.Valid {
+	claims, ok := t.Claims.(jwt.MapClaims)
+	if !ok || !t.Valid {
+		return models.TokenClaims{}, ErrInvalidToken
+	}
+
+	var result models.TokenClaims
+
+	switch typ, _ := claims["typ"].(string); typ {
+	case "", models.TokenTypeAccess:
+		result.Type = models.TokenTypeAccess
+	case models.TokenTypeRefresh:
+		result.Type = models.TokenTypeRefresh
+	default:
+		return models.TokenClaims{}, fmt.Errorf("unknown token type: %w", ErrInvalidToken)
+	}
 
-		id, ok := claims["uid"].(string)
-		if !ok {
-			return "", fmt.Errorf("cannot extract user: %w", ErrInvalidToken)
+	switch id := claims["uid"].(type) {
+	case string:
+		result.UserID, err = strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return models.TokenClaims{}, fmt.Errorf("cannot extract user: %w", ErrInvalidToken)
 		}
+	case float64:
+		result.UserID = int64(id)
+	default:
+		return models.TokenClaims{}, fmt.Errorf("cannot extract user: %w", ErrInvalidToken)
+	}
 
-		return id, nil
+	if appID, ok := claims["app_id"].(float64); ok {
+		result.AppID = int64(appID)
+	}
+	if tenantID, ok := claims["tid"].(float64); ok {
+		result.TenantID = int64(tenantID)
+	}
+
+	result.Email, _ = claims["email"].(string)
+	result.ID, _ = claims["jti"].(string)
+
+	// scopes are space separated as in oauth2
+	if scope, ok := claims["scope"].(string); ok {
+		result.Scopes = strings.Fields(scope)
+	}
+	result.Roles = stringList(claims["roles"])
+	result.Permissions = stringList(claims["perms"])
+
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		result.ExpiresAt = exp.Time
+	}
+
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		result.IssuedAt = iat.Time
+	}
+
+	return result, nil
+}
+
+// stringList converts json array claim, missing claim gives nil and empty array gives empty slice
+func stringList(claim any) []string {
+	values, ok := claim.([]any)
+	if !ok {
+		return nil
+	}
+
+	list := make([]string, 0, len(values))
+	for _, v := range values {
+		if str, ok := v.(string); ok {
+			list = append(list, str)
+		}
 	}
 
-	return "", ErrInvalidToken
+	return list
 }
@@ -0,0 +1,50 @@
+package jwtlib
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"sso/internal/domain/models"
+)
+
+func TestTokenClaimsRejectsRefreshToken(t *testing.T) {
+	s, err := NewService("secret")
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+
+	user := models.User{ID: 7, Email: "user@example.com"}
+	app := models.App{ID: 1}
+
+	access, err := s.NewToken(user, app, time.Minute, models.TokenGrant{})
+	if err != nil {
+		t.Fatalf("NewToken: %v", err)
+	}
+	refresh, err := s.NewRefreshToken(user, app, time.Hour)
+	if err != nil {
+		t.Fatalf("NewRefreshToken: %v", err)
+	}
+
+	claims, err := s.TokenClaims(context.Background(), access)
+	if err != nil {
+		t.Fatalf("access token: %v", err)
+	}
+	if claims.Type != models.TokenTypeAccess || claims.UserID != 7 {
+		t.Errorf("access claims = %+v", claims)
+	}
+
+	_, err = s.TokenClaims(context.Background(), refresh)
+	if !errors.Is(err, ErrRefreshToken) {
+		t.Fatalf("refresh token: got %v, want %v", err, ErrRefreshToken)
+	}
+
+	claims, err = s.ParseClaims(context.Background(), refresh)
+	if err != nil {
+		t.Fatalf("ParseClaims of refresh token: %v", err)
+	}
+	if claims.Type != models.TokenTypeRefresh {
+		t.Errorf("refresh token type = %q", claims.Type)
+	}
+}
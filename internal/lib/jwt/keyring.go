@@ -0,0 +1,111 @@
+package jwtlib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"sso/internal/domain/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownKey = errors.New("unknown signing key")
+)
+
+type KeyProvider interface {
+	SigningKeys(ctx context.Context, grace time.Duration) ([]models.SigningKey, error)
+}
+
+// SetKeys replaces keyring, new tokens are signed with active key,
+// without active key service falls back to secret from constructor
+func (s *service) SetKeys(keys []models.SigningKey) {
+	ring := make(map[string][]byte, len(keys))
+
+	var active *models.SigningKey
+	for i := range keys {
+		ring[keys[i].ID] = []byte(keys[i].Secret)
+		if keys[i].Active {
+			active = &keys[i]
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = ring
+	s.active = active
+}
+
+// LoadKeys reads keyring from provider, retired keys stay valid during grace
+func (s *service) LoadKeys(ctx context.Context, provider KeyProvider, grace time.Duration) error {
+	const op = "jwtlib.LoadKeys"
+
+	keys, err := provider.SigningKeys(ctx, grace)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	s.SetKeys(keys)
+
+	return nil
+}
+
+// WatchKeys reloads keyring every interval until ctx is done, so rotated keys are picked up without restart
+func (s *service) WatchKeys(ctx context.Context, log *slog.Logger, provider KeyProvider, grace time.Duration, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.LoadKeys(ctx, provider, grace); err != nil {
+				log.Warn("cannot reload signing keys", slog.Any("err", err))
+			}
+		}
+	}
+}
+
+// signingKey returns dedicated key of app when kid is set, otherwise active key.
+// Missing dedicated key is an error, tokens signed with another key would be rejected by app
+func (s *service) signingKey(kid string) (string, []byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if kid != "" {
+		secret, ok := s.keys[kid]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: %s", ErrUnknownKey, kid)
+		}
+
+		return kid, secret, nil
+	}
+
+	if s.active == nil {
+		return "", s.secret, nil
+	}
+
+	return s.active.ID, []byte(s.active.Secret), nil
+}
+
+func (s *service) verificationKey(token *jwt.Token) ([]byte, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return s.secret, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	secret, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, kid)
+	}
+
+	return secret, nil
+}
@@ -0,0 +1,76 @@
+// Package mailer delivers emails to users, e.g. codes which confirm new email address
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// SMTP sends mail through relay, PLAIN auth is used when username is set
+type SMTP struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTP(addr string, from string, username string, password string) *SMTP {
+	s := &SMTP{
+		addr: addr,
+		from: from,
+	}
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		s.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return s
+}
+
+// Send delivers plain text message, net/smtp cannot be cancelled, so ctx is checked only before dialing
+func (s *SMTP) Send(ctx context.Context, to string, subject string, body string) error {
+	const op = "mailer.Send"
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	// recipient and subject go to headers, line breaks would let them add headers of their own
+	if strings.ContainsAny(to+subject, "\r\n") {
+		return fmt.Errorf("%s: line break in header", op)
+	}
+
+	msg := "From: " + s.from + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + body
+
+	if err := smtp.SendMail(s.addr, s.auth, s.from, []string{to}, []byte(msg)); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+// Log writes messages to log instead of sending them, it is meant for local environment only
+type Log struct {
+	log *slog.Logger
+}
+
+func NewLog(log *slog.Logger) *Log {
+	return &Log{log: log}
+}
+
+func (l *Log) Send(_ context.Context, to string, subject string, body string) error {
+	l.log.Info("mail is not sent, smtp is not configured",
+		slog.String("to", to),
+		slog.String("subject", subject),
+		slog.String("body", body),
+	)
+
+	return nil
+}
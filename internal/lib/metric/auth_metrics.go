@@ -0,0 +1,125 @@
+package metric
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// token types of IncTokenIssued
+const (
+	TokenAccess  = "access"
+	TokenRefresh = "refresh"
+)
+
+// decisions and cache tiers of IncPermissionCheck
+const (
+	DecisionAllow = "allow"
+	DecisionDeny  = "deny"
+	DecisionError = "error"
+
+	TierCache    = "cache"
+	TierDatabase = "database"
+)
+
+// AuthMetrics records business outcomes of authentication, services depend on it instead of prometheus
+type AuthMetrics interface {
+	// outcome is models.LoginAttempt reason
+	IncLogin(outcome string, appID uint64)
+	IncRegistration()
+	IncTokenIssued(tokenType string)
+	IncPermissionCheck(permission, decision, tier string)
+	ObservePasswordHash(algorithm, operation string, seconds float64)
+}
+
+type authCollectors struct {
+	logins           *prometheus.CounterVec
+	registrations    prometheus.Counter
+	tokensIssued     *prometheus.CounterVec
+	permissionChecks *prometheus.CounterVec
+	passwordHash     *prometheus.HistogramVec
+}
+
+func newAuthCollectors(name string) (*authCollectors, error) {
+	const op = "metric.newAuthCollectors"
+
+	c := &authCollectors{
+		logins: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: name + "_login_attempts_total",
+				Help: "Login attempts by outcome and app",
+			},
+			[]string{"outcome", "app_id"},
+		),
+		registrations: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: name + "_registrations_total",
+				Help: "Registered users",
+			},
+		),
+		tokensIssued: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: name + "_tokens_issued_total",
+				Help: "Issued tokens by type",
+			},
+			[]string{"type"},
+		),
+		permissionChecks: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: name + "_permission_checks_total",
+				Help: "Permission checks by decision and tier which answered",
+			},
+			[]string{"permission", "decision", "tier"},
+		),
+		// bcrypt with default cost takes tens of milliseconds
+		passwordHash: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    name + "_password_hash_seconds",
+				Help:    "Latency of password hashing and comparison",
+				Buckets: []float64{.01, .025, .05, .1, .25, .5, 1, 2.5},
+			},
+			[]string{"algorithm", "operation"},
+		),
+	}
+
+	for _, collector := range []prometheus.Collector{
+		c.logins,
+		c.registrations,
+		c.tokensIssued,
+		c.permissionChecks,
+		c.passwordHash,
+	} {
+		if err := prometheus.Register(collector); err != nil {
+
+			return nil, fmt.Errorf("%s:%w", op, err)
+		}
+	}
+
+	return c, nil
+}
+
+func (metr *PrometheusMetrics) IncLogin(outcome string, appID uint64) {
+
+	metr.auth.logins.WithLabelValues(outcome, strconv.FormatUint(appID, 10)).Inc()
+}
+
+func (metr *PrometheusMetrics) IncRegistration() {
+
+	metr.auth.registrations.Inc()
+}
+
+func (metr *PrometheusMetrics) IncTokenIssued(tokenType string) {
+
+	metr.auth.tokensIssued.WithLabelValues(tokenType).Inc()
+}
+
+func (metr *PrometheusMetrics) IncPermissionCheck(permission, decision, tier string) {
+
+	metr.auth.permissionChecks.WithLabelValues(permission, decision, tier).Inc()
+}
+
+func (metr *PrometheusMetrics) ObservePasswordHash(algorithm, operation string, seconds float64) {
+
+	metr.auth.passwordHash.WithLabelValues(algorithm, operation).Observe(seconds)
+}
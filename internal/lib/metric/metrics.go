@@ -20,9 +20,11 @@ type PrometheusMetrics struct {
 	HitsTotal prometheus.Counter
 	Hits      *prometheus.CounterVec
 	Times     *prometheus.HistogramVec
+
+	auth *authCollectors
 }
 
-func CreateMetrics(addr, name string) (Metrics, error) {
+func CreateMetrics(addr, name string) (*PrometheusMetrics, error) {
 	const op = "metric.CreateMetrics"
 
 	var metr PrometheusMetrics
@@ -63,6 +65,13 @@ func CreateMetrics(addr, name string) (Metrics, error) {
 		return nil, fmt.Errorf("%s:%w", op, err)
 	}
 
+	auth, err := newAuthCollectors(name)
+	if err != nil {
+
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	metr.auth = auth
+
 	if err := prometheus.Register(collectors.NewBuildInfoCollector()); err != nil {
 
 		return nil, fmt.Errorf("%s:%w", op, err)
@@ -0,0 +1,63 @@
+package passhash
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// algorithm label passed to Observer
+const Bcrypt = "bcrypt"
+
+// Observer receives latency of hashing, e.g. metric.AuthMetrics
+type Observer interface {
+	ObservePasswordHash(algorithm, operation string, seconds float64)
+}
+
+// operations passed to Observer
+const (
+	opGenerate = "generate"
+	opCompare  = "compare"
+)
+
+// Hasher wraps bcrypt calls to report how long they take
+type Hasher struct {
+	observer Observer
+}
+
+// New returns bcrypt hasher, observer can be nil
+func New(observer Observer) *Hasher {
+	return &Hasher{observer: observer}
+}
+
+func (h *Hasher) Hash(password string) ([]byte, error) {
+	const op = "passhash.Hash"
+
+	defer h.observe(opGenerate, time.Now())
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return hash, nil
+}
+
+func (h *Hasher) Compare(hash []byte, password string) error {
+	const op = "passhash.Compare"
+
+	defer h.observe(opCompare, time.Now())
+
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+func (h *Hasher) observe(operation string, start time.Time) {
+	if h.observer != nil {
+		h.observer.ObservePasswordHash(Bcrypt, operation, time.Since(start).Seconds())
+	}
+}
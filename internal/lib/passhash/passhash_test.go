@@ -0,0 +1,52 @@
+package passhash
+
+import (
+	"testing"
+)
+
+type recorder struct {
+	calls []string
+}
+
+func (r *recorder) ObservePasswordHash(algorithm, operation string, seconds float64) {
+	r.calls = append(r.calls, algorithm+"/"+operation)
+}
+
+func TestHasherObservesCalls(t *testing.T) {
+	obs := &recorder{}
+	h := New(obs)
+
+	hash, err := h.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	if err := h.Compare(hash, "correct horse"); err != nil {
+		t.Errorf("Compare with right password: %v", err)
+	}
+	if err := h.Compare(hash, "battery staple"); err == nil {
+		t.Error("Compare with wrong password succeeded")
+	}
+
+	want := []string{"bcrypt/generate", "bcrypt/compare", "bcrypt/compare"}
+	if len(obs.calls) != len(want) {
+		t.Fatalf("observed %v, want %v", obs.calls, want)
+	}
+	for i := range want {
+		if obs.calls[i] != want[i] {
+			t.Errorf("call %d = %q, want %q", i, obs.calls[i], want[i])
+		}
+	}
+}
+
+func TestHasherWithoutObserver(t *testing.T) {
+	h := New(nil)
+
+	hash, err := h.Hash("secret")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if err := h.Compare(hash, "secret"); err != nil {
+		t.Errorf("Compare: %v", err)
+	}
+}
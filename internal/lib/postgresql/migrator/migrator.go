@@ -0,0 +1,403 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"sso/internal/lib/tenant"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockID is key of postgres advisory lock, only one migrator can work with schema at a time
+const lockID int64 = 7265721
+
+const (
+	createMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations(
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+	)
+	`
+
+	selectAppliedMigrations = `
+	SELECT version, applied_at FROM schema_migrations
+	`
+
+	insertMigration = `
+	INSERT INTO schema_migrations(version, name) VALUES ($1, $2)
+	`
+
+	deleteMigration = `
+	DELETE FROM schema_migrations WHERE version = $1
+	`
+
+	acquireLock = `SELECT pg_advisory_lock($1)`
+	releaseLock = `SELECT pg_advisory_unlock($1)`
+)
+
+var (
+	ErrUnknownVersion  = errors.New("unknown migration version")
+	ErrMissingDownFile = errors.New("migration has no down file")
+)
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus shows whether migration was applied, AppliedAt is zero for pending ones
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	log        *slog.Logger
+	db         *pgxpool.Pool
+	migrations []Migration
+}
+
+// New reads migrations from fsys, files are named as 000001_name.up.sql and 000001_name.down.sql
+func New(log *slog.Logger, db *pgxpool.Pool, fsys fs.FS) (*Migrator, error) {
+	const op = "migrator.New"
+
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return &Migrator{
+		log:        log,
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has different names: %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up(ctx context.Context) error {
+	const op = "migrator.Up"
+
+	if err := m.migrate(ctx, m.latest()); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+// Down rolls back the last applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	const op = "migrator.Down"
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			if _, ok := applied[m.migrations[i].Version]; ok {
+				return m.rollback(ctx, conn, m.migrations[i])
+			}
+		}
+
+		m.log.Info("nothing to roll back")
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+// To migrates schema up or down to given version, version 0 rolls back everything
+func (m *Migrator) To(ctx context.Context, version int64) error {
+	const op = "migrator.To"
+
+	if version != 0 && !m.known(version) {
+		return fmt.Errorf("%s:%w: %d", op, ErrUnknownVersion, version)
+	}
+
+	if err := m.migrate(ctx, version); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+// Baseline records migrations up to given version as applied without running them,
+// it is used when schema of existing database was created before the migrator
+func (m *Migrator) Baseline(ctx context.Context, version int64) error {
+	const op = "migrator.Baseline"
+
+	if !m.known(version) {
+		return fmt.Errorf("%s:%w: %d", op, ErrUnknownVersion, version)
+	}
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			for _, migration := range m.migrations {
+				if _, ok := applied[migration.Version]; ok || migration.Version > version {
+					continue
+				}
+
+				if _, err := tx.Exec(ctx, insertMigration, migration.Version, migration.Name); err != nil {
+					return fmt.Errorf("record %d_%s: %w", migration.Version, migration.Name, err)
+				}
+
+				m.log.Info("migration recorded as applied", slog.Int64("version", migration.Version), slog.String("name", migration.Name))
+			}
+
+			return nil
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+// Status returns every known migration with its state
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	const op = "migrator.Status"
+
+	var statuses []MigrationStatus
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			appliedAt, ok := applied[migration.Version]
+			statuses = append(statuses, MigrationStatus{
+				Migration: migration,
+				Applied:   ok,
+				AppliedAt: appliedAt,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return statuses, nil
+}
+
+func (m *Migrator) migrate(ctx context.Context, target int64) error {
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; ok && migration.Version > target {
+				if err := m.rollback(ctx, conn, migration); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; !ok && migration.Version <= target {
+				if err := m.apply(ctx, conn, migration); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, migration Migration) error {
+	err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, migration.Up); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx, insertMigration, migration.Version, migration.Name)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("apply %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	m.log.Info("migration applied", slog.Int64("version", migration.Version), slog.String("name", migration.Name))
+
+	return nil
+}
+
+func (m *Migrator) rollback(ctx context.Context, conn *pgxpool.Conn, migration Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("%d_%s: %w", migration.Version, migration.Name, ErrMissingDownFile)
+	}
+
+	err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, migration.Down); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx, deleteMigration, migration.Version)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("rollback %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	m.log.Info("migration rolled back", slog.Int64("version", migration.Version), slog.String("name", migration.Name))
+
+	return nil
+}
+
+// withLock holds advisory lock on one connection, so parallel instances do not migrate schema twice.
+// Connection is not scoped to tenant, data migrations see rows of every tenant
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.db.Acquire(tenant.Unscoped(ctx))
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, acquireLock, lockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+
+	defer func() {
+		if _, err := conn.Exec(context.Background(), releaseLock, lockID); err != nil {
+			m.log.Warn("cannot release migration lock", slog.Any("err", err))
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, createMigrationsTable); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func appliedVersions(ctx context.Context, conn *pgxpool.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.Query(ctx, selectAppliedMigrations)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]time.Time)
+
+	var (
+		version   int64
+		appliedAt time.Time
+	)
+	_, err = pgx.ForEachRow(rows, []any{&version, &appliedAt}, func() error {
+		applied[version] = appliedAt
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+func (m *Migrator) latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+func (m *Migrator) known(version int64) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+
+	return false
+}
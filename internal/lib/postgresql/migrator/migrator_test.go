@@ -0,0 +1,100 @@
+package migrator
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"sso/migrations"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000002_roles.up.sql":   {Data: []byte("CREATE TABLE roles();")},
+		"000002_roles.down.sql": {Data: []byte("DROP TABLE roles;")},
+		"000001_users.up.sql":   {Data: []byte("CREATE TABLE users();")},
+		"000010_index.up.sql":   {Data: []byte("CREATE INDEX i ON users(id);")},
+		"migrations.go":         {Data: []byte("package migrations")},
+		"README.md":             {Data: []byte("not a migration")},
+	}
+
+	migrations, err := load(fsys)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	want := []Migration{
+		{Version: 1, Name: "users", Up: "CREATE TABLE users();"},
+		{Version: 2, Name: "roles", Up: "CREATE TABLE roles();", Down: "DROP TABLE roles;"},
+		{Version: 10, Name: "index", Up: "CREATE INDEX i ON users(id);"},
+	}
+	if len(migrations) != len(want) {
+		t.Fatalf("loaded %d migrations, want %d: %+v", len(migrations), len(want), migrations)
+	}
+	for i := range want {
+		if migrations[i] != want[i] {
+			t.Errorf("migration %d = %+v, want %+v", i, migrations[i], want[i])
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		wantErr string
+	}{
+		{
+			name: "name mismatch",
+			fsys: fstest.MapFS{
+				"000001_users.up.sql":     {Data: []byte("CREATE TABLE users();")},
+				"000001_account.down.sql": {Data: []byte("DROP TABLE users;")},
+			},
+			wantErr: "different names",
+		},
+		{
+			name: "missing up file",
+			fsys: fstest.MapFS{
+				"000001_users.up.sql":   {Data: []byte("CREATE TABLE users();")},
+				"000002_roles.down.sql": {Data: []byte("DROP TABLE roles;")},
+			},
+			wantErr: "migration 2_roles has no up file",
+		},
+		{
+			name: "version out of range",
+			fsys: fstest.MapFS{
+				"99999999999999999999_huge.up.sql": {Data: []byte("SELECT 1;")},
+			},
+			wantErr: "99999999999999999999_huge.up.sql",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := load(tt.fsys)
+			if err == nil {
+				t.Fatal("load succeeded, want error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %q does not mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// migrations shipped with service must load and be numbered without gaps
+func TestLoadRepositoryMigrations(t *testing.T) {
+	loaded, err := load(migrations.FS)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	for i, m := range loaded {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %d_%s follows version %d", m.Version, m.Name, i)
+		}
+		if m.Down == "" {
+			t.Errorf("migration %d_%s has no down file", m.Version, m.Name)
+		}
+	}
+}
@@ -4,52 +4,62 @@ import (
 	"context"
 	"fmt"
 	"sso/internal/config"
-	"time"
 
 	"errors"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// settings of db init
-const (
-	maxConns          = 20
-	connsMaxLifetime  = 120
-	minConns          = 5
-	connMaxIdleTime   = 20
-	healthCheckPeriod = 30
-)
-
 var (
 	ErrCannotConnectToDB = errors.New("cannot connect to database")
 )
 
+// NewPsqlDB opens pool configured from config.PostgresConfig and checks that database is reachable
 func NewPsqlDB(c *config.Config) (*pgxpool.Pool, error) {
-	dataSource := fmt.Sprintf("host=%s port=%s user=%s dbname=%s sslmode=disable password=%s",
+	const op = "postgresinit.NewPsqlDB"
+
+	sslMode := c.Postgres.PostgresqlSSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	dataSource := fmt.Sprintf("host=%s port=%s user=%s dbname=%s sslmode=%s password=%s",
 		c.Postgres.PostgresqlHost,
 		c.Postgres.PostgresqlPort,
 		c.Postgres.PostgresqlUser,
 		c.Postgres.PostgresqlDbname,
+		sslMode,
 		c.Postgres.PostgresqlPassword,
 	)
 
 	cfg, err := pgxpool.ParseConfig(dataSource)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%s:%w", op, err)
 	}
-	cfg.MaxConns = int32(maxConns)
-	cfg.MinConns = minConns
-	cfg.MaxConnLifetime = (connsMaxLifetime * time.Second)
-	cfg.MaxConnIdleTime = (connMaxIdleTime * time.Second)
-	cfg.HealthCheckPeriod = (healthCheckPeriod * time.Second)
 
-	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	pool := c.Postgres.Pool
+
+	cfg.MaxConns = pool.MaxConns
+	cfg.MinConns = pool.MinConns
+	cfg.MaxConnLifetime = pool.MaxConnLifetime
+	cfg.MaxConnIdleTime = pool.MaxConnIdleTime
+	cfg.HealthCheckPeriod = pool.HealthCheckPeriod
+	cfg.ConnConfig.ConnectTimeout = pool.ConnectTimeout
+	cfg.ConnConfig.Tracer = newQueryTracer(c.Postgres.PostgresqlDbname)
+	cfg.BeforeAcquire = scopeConn
+
+	db, err := pgxpool.NewWithConfig(context.Background(), cfg)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%s:%w", op, err)
 	}
 
-	if err = pool.Ping(context.Background()); err != nil {
-		return nil, err
+	ctx, cancel := context.WithTimeout(context.Background(), pool.ConnectTimeout)
+	defer cancel()
+
+	if err = db.Ping(ctx); err != nil {
+		db.Close()
+
+		return nil, fmt.Errorf("%s:%w", op, errors.Join(ErrCannotConnectToDB, err))
 	}
-	return pool, nil
+	return db, nil
 }
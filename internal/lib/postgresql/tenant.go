@@ -0,0 +1,35 @@
+package postgresinit
+
+import (
+	"context"
+
+	"sso/internal/lib/tenant"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	setTenant = `SELECT set_config('sso.tenant_id', $1, false)`
+
+	// tenantKey keeps setting of connection in its custom data, so it is changed only when tenant differs
+	tenantKey = "sso.tenant_id"
+)
+
+// scopeConn sets tenant of acquiring context to connection before it serves queries,
+// row level security policies read it. New connections have no tenant, so they see no tenant rows
+func scopeConn(ctx context.Context, conn *pgx.Conn) bool {
+	setting := tenant.Setting(ctx)
+
+	data := conn.PgConn().CustomData()
+	if current, _ := data[tenantKey].(string); current == setting {
+		return true
+	}
+
+	if _, err := conn.Exec(ctx, setTenant, setting); err != nil {
+		// connection with unknown setting must not serve queries of another tenant, pool drops it
+		return false
+	}
+	data[tenantKey] = setting
+
+	return true
+}
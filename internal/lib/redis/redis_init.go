@@ -4,6 +4,7 @@ import (
 	"sso/internal/config"
 	"time"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -27,5 +28,8 @@ func NewRedisClient(cfg *config.Config) *redis.Client {
 		DB:           cfg.Redis.DB,
 	})
 
+	// hook only fails on invalid options, client works without spans then
+	_ = redisotel.InstrumentTracing(client)
+
 	return client
 }
@@ -0,0 +1,75 @@
+// Package tenant carries organization which owns data of request through context.
+//
+// Repositories filter queries by natural keys and stamp inserts with tenant from context,
+// postgres row level security hides rows of other tenants from every query as a backstop
+package tenant
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// Default owns every user, app and role created before tenants existed
+const Default int64 = 1
+
+var ErrMissing = errors.New("tenant is not set")
+
+type contextKey struct{}
+
+// unscoped marks context which sees rows of every tenant
+const unscoped int64 = -1
+
+// With returns context scoped to tenant
+func With(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// Unscoped returns context which is not limited to one tenant, it is used only before tenant is
+// known, e.g. to resolve app of request, and by maintenance tools
+func Unscoped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, unscoped)
+}
+
+// FromContext returns tenant of context, false for unscoped context and context without tenant
+func FromContext(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(contextKey{}).(int64)
+	if !ok || id == unscoped {
+		return 0, false
+	}
+
+	return id, true
+}
+
+// Require returns tenant of context or ErrMissing
+func Require(ctx context.Context) (int64, error) {
+	id, ok := FromContext(ctx)
+	if !ok {
+		return 0, ErrMissing
+	}
+
+	return id, nil
+}
+
+// OrDefault maps zero id of records and tokens issued before tenants to Default
+func OrDefault(id int64) int64 {
+	if id == 0 {
+		return Default
+	}
+
+	return id
+}
+
+// Setting is value of sso.tenant_id postgres setting which row level security policies read:
+// tenant id, "all" for unscoped context and empty string when context has no tenant
+func Setting(ctx context.Context) string {
+	id, ok := ctx.Value(contextKey{}).(int64)
+	switch {
+	case !ok:
+		return ""
+	case id == unscoped:
+		return "all"
+	}
+
+	return strconv.FormatInt(id, 10)
+}
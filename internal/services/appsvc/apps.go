@@ -0,0 +1,218 @@
+package appsvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"sso/internal/domain/models"
+	"sso/internal/lib/appsecret"
+	"sso/internal/storage"
+)
+
+type Apps struct {
+	log      *slog.Logger
+	registry AppRegistry
+	// signing keys are kept after retirement for so long, longer access tokens would outlive their key
+	maxAccessTTL time.Duration
+}
+
+type AppRegistry interface {
+	CreateApp(ctx context.Context, app models.App, secretHash string) (models.App, error)
+	App(ctx context.Context, appID int) (models.App, error)
+	Apps(ctx context.Context) ([]models.App, error)
+	UpdateApp(ctx context.Context, app models.App) error
+	SetDisabled(ctx context.Context, appID int, disabled bool) error
+	DeleteApp(ctx context.Context, appID int) error
+	RotateSecret(ctx context.Context, appID int, secretHash string, overlap time.Duration) (models.AppSecret, error)
+}
+
+func New(log *slog.Logger, registry AppRegistry, maxAccessTTL time.Duration) *Apps {
+	return &Apps{
+		log:          log,
+		registry:     registry,
+		maxAccessTTL: maxAccessTTL,
+	}
+}
+
+var (
+	ErrOverlapTooLong   = errors.New("secret overlap is too long")
+	ErrAccessTTLTooLong = errors.New("access token ttl is too long")
+	ErrInvalidTokenTTL  = errors.New("token ttl is out of range")
+)
+
+const (
+	// DefaultSecretOverlap is used when rotation does not set overlap
+	DefaultSecretOverlap = 24 * time.Hour
+	MaxSecretOverlap     = 30 * 24 * time.Hour
+	// MaxTokenTTL bounds every token lifetime of app, access tokens are limited further by service config
+	MaxTokenTTL = 365 * 24 * time.Hour
+)
+
+// Register creates app and returns its plain secret, the secret is not stored and cannot be shown again
+func (a *Apps) Register(ctx context.Context, app models.App) (models.App, string, error) {
+	const op = "appsvc.Register"
+
+	log := a.log.With(
+		slog.String("op", op),
+		slog.String("name", app.Name),
+	)
+
+	if err := a.checkTokens(app.Tokens); err != nil {
+		return models.App{}, "", fmt.Errorf("%s:%w", op, err)
+	}
+
+	secret, hash, err := appsecret.Generate()
+	if err != nil {
+		return models.App{}, "", fmt.Errorf("%s:%w", op, err)
+	}
+
+	created, err := a.registry.CreateApp(ctx, app, hash)
+	if err != nil {
+		if errors.Is(err, storage.ErrAppExists) || errors.Is(err, storage.ErrKeyNotFound) {
+			log.Warn("app cannot be created", slog.Any("err", err))
+
+			return models.App{}, "", fmt.Errorf("%s:%w", op, err)
+		}
+		log.Error("failed to create app", slog.Any("err", err))
+
+		return models.App{}, "", fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("app registered", slog.Int("app_id", created.ID))
+
+	return created, secret, nil
+}
+
+func (a *Apps) App(ctx context.Context, appID int) (models.App, error) {
+	const op = "appsvc.App"
+
+	app, err := a.registry.App(ctx, appID)
+	if err != nil {
+		return models.App{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return app, nil
+}
+
+func (a *Apps) Apps(ctx context.Context) ([]models.App, error) {
+	const op = "appsvc.Apps"
+
+	apps, err := a.registry.Apps(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return apps, nil
+}
+
+// Update replaces registration data and token settings of app and returns updated app
+func (a *Apps) Update(ctx context.Context, app models.App) (models.App, error) {
+	const op = "appsvc.Update"
+
+	log := a.log.With(
+		slog.String("op", op),
+		slog.Int("app_id", app.ID),
+	)
+
+	if err := a.checkTokens(app.Tokens); err != nil {
+		return models.App{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	if err := a.registry.UpdateApp(ctx, app); err != nil {
+		log.Warn("failed to update app", slog.Any("err", err))
+
+		return models.App{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("app updated")
+
+	return a.App(ctx, app.ID)
+}
+
+// SetDisabled disables or enables app, tokens of disabled app are reported inactive by introspection
+func (a *Apps) SetDisabled(ctx context.Context, appID int, disabled bool) error {
+	const op = "appsvc.SetDisabled"
+
+	log := a.log.With(
+		slog.String("op", op),
+		slog.Int("app_id", appID),
+	)
+
+	if err := a.registry.SetDisabled(ctx, appID, disabled); err != nil {
+		log.Warn("failed to change app state", slog.Any("err", err))
+
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("app state changed", slog.Bool("disabled", disabled))
+
+	return nil
+}
+
+func (a *Apps) Delete(ctx context.Context, appID int) error {
+	const op = "appsvc.Delete"
+
+	log := a.log.With(
+		slog.String("op", op),
+		slog.Int("app_id", appID),
+	)
+
+	if err := a.registry.DeleteApp(ctx, appID); err != nil {
+		log.Warn("failed to delete app", slog.Any("err", err))
+
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("app deleted")
+
+	return nil
+}
+
+// RotateSecret generates new secret, previous one stays valid for overlap, so app can be redeployed
+// without downtime. Zero overlap revokes previous secret at once
+func (a *Apps) RotateSecret(ctx context.Context, appID int, overlap time.Duration) (string, models.AppSecret, error) {
+	const op = "appsvc.RotateSecret"
+
+	log := a.log.With(
+		slog.String("op", op),
+		slog.Int("app_id", appID),
+	)
+
+	if overlap < 0 || overlap > MaxSecretOverlap {
+		return "", models.AppSecret{}, fmt.Errorf("%s:%w", op, ErrOverlapTooLong)
+	}
+
+	secret, hash, err := appsecret.Generate()
+	if err != nil {
+		return "", models.AppSecret{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	saved, err := a.registry.RotateSecret(ctx, appID, hash, overlap)
+	if err != nil {
+		log.Warn("failed to rotate secret", slog.Any("err", err))
+
+		return "", models.AppSecret{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("app secret rotated", slog.Duration("overlap", overlap))
+
+	return secret, saved, nil
+}
+
+// checkTokens rejects negative and too long lifetimes, negative ones would issue already expired tokens
+// and access tokens must not outlive signing keys
+func (a *Apps) checkTokens(tokens models.AppTokenConfig) error {
+	for _, ttl := range []time.Duration{tokens.AccessTTL, tokens.RefreshTTL, tokens.IdleTimeout} {
+		if ttl < 0 || ttl > MaxTokenTTL {
+			return ErrInvalidTokenTTL
+		}
+	}
+	if a.maxAccessTTL > 0 && tokens.AccessTTL > a.maxAccessTTL {
+		return ErrAccessTTLTooLong
+	}
+
+	return nil
+}
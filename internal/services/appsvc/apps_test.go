@@ -0,0 +1,55 @@
+package appsvc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"sso/internal/domain/models"
+)
+
+func TestCheckTokens(t *testing.T) {
+	apps := &Apps{maxAccessTTL: 24 * time.Hour}
+
+	tests := []struct {
+		name   string
+		tokens models.AppTokenConfig
+		want   error
+	}{
+		{name: "defaults", tokens: models.AppTokenConfig{}},
+		{
+			name: "in range",
+			tokens: models.AppTokenConfig{
+				AccessTTL:   time.Hour,
+				RefreshTTL:  30 * 24 * time.Hour,
+				IdleTimeout: 7 * 24 * time.Hour,
+			},
+		},
+		{name: "access ttl at limit", tokens: models.AppTokenConfig{AccessTTL: 24 * time.Hour}},
+		{name: "access ttl above limit", tokens: models.AppTokenConfig{AccessTTL: 25 * time.Hour}, want: ErrAccessTTLTooLong},
+		{name: "negative access ttl", tokens: models.AppTokenConfig{AccessTTL: -time.Second}, want: ErrInvalidTokenTTL},
+		{name: "negative refresh ttl", tokens: models.AppTokenConfig{RefreshTTL: -time.Second}, want: ErrInvalidTokenTTL},
+		{name: "negative idle timeout", tokens: models.AppTokenConfig{IdleTimeout: -time.Second}, want: ErrInvalidTokenTTL},
+		{name: "refresh ttl too long", tokens: models.AppTokenConfig{RefreshTTL: MaxTokenTTL + time.Second}, want: ErrInvalidTokenTTL},
+		{name: "idle timeout too long", tokens: models.AppTokenConfig{IdleTimeout: MaxTokenTTL + time.Second}, want: ErrInvalidTokenTTL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := apps.checkTokens(tt.tokens); !errors.Is(err, tt.want) {
+				t.Errorf("checkTokens() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTokensWithoutAccessLimit(t *testing.T) {
+	apps := &Apps{}
+
+	if err := apps.checkTokens(models.AppTokenConfig{AccessTTL: MaxTokenTTL}); err != nil {
+		t.Errorf("checkTokens() = %v, want nil", err)
+	}
+	if err := apps.checkTokens(models.AppTokenConfig{AccessTTL: MaxTokenTTL + time.Second}); !errors.Is(err, ErrInvalidTokenTTL) {
+		t.Errorf("checkTokens() = %v, want %v", err, ErrInvalidTokenTTL)
+	}
+}
@@ -5,12 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"sso/internal/domain/models"
+	"sso/internal/dto"
+	"sso/internal/lib/appsecret"
+	"sso/internal/lib/clientinfo"
+	"sso/internal/lib/metric"
+	"sso/internal/lib/tenant"
 	"sso/internal/storage"
 	"time"
-
-	"github.com/opentracing/opentracing-go"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type Auth struct {
@@ -20,15 +23,64 @@ type Auth struct {
 	aProvide      appProvide
 	tokenProvider tokenProvider
 	refreshSaver  refreshSaver
-	tokenTTL      time.Duration
+	lRecorder     loginRecorder
+	sessions      sessionState
+	grants        grantProvider
+	hasher        passwordHasher
+	metrics       authMetrics
+	// default lifetime of access tokens, refresh tokens live refreshFactor times longer
+	tokenTTL  time.Duration
+	tokenOpts TokenOptions
+}
+
+// TokenOptions tell what is embedded to access token besides identity of user
+type TokenOptions struct {
+	Roles       bool
+	Permissions bool
+	// MaxSize is limit of encoded token in bytes, permissions and then roles are left out
+	// of token which does not fit, zero means no limit
+	MaxSize int
+	// MaxAccessTTL caps access token lifetime set by apps, signing keys are not kept
+	// longer after retirement, zero means no limit
+	MaxAccessTTL time.Duration
+}
+
+type passwordHasher interface {
+	Hash(password string) ([]byte, error)
+	Compare(hash []byte, password string) error
+}
+
+type authMetrics interface {
+	IncLogin(outcome string, appID uint64)
+	IncRegistration()
+	IncTokenIssued(tokenType string)
+}
+
+type loginRecorder interface {
+	SaveLoginAttempt(ctx context.Context, attempt models.LoginAttempt) error
 }
 
 type tokenProvider interface {
-	NewToken(user models.User, app models.App, duration time.Duration) (string, error)
+	NewToken(user models.User, app models.App, duration time.Duration, grant models.TokenGrant) (string, error)
+	NewRefreshToken(user models.User, app models.App, duration time.Duration) (string, error)
+	ParseClaims(ctx context.Context, token string) (models.TokenClaims, error)
+}
+
+// sessionState tells whether tokens of user are still trusted
+type sessionState interface {
+	IsUsrBanned(ctx context.Context, userID int64) (*dto.IsBannedRespStruct, error)
+	TokensRevokedAt(ctx context.Context, userID int64) (time.Time, error)
+}
+
+// grantProvider resolves roles and permissions of user in app
+type grantProvider interface {
+	UserRoles(ctx context.Context, userID int64, appID uint64) ([]string, error)
+	PermissionNames(ctx context.Context) ([]string, error)
+	GrantedPermissions(ctx context.Context, userID int64, appID uint64, names []string) ([]string, error)
 }
 
 type refreshSaver interface {
-	SaveRefresh(ctx context.Context, token string, userid int64, duration time.Duration) error
+	SaveRefresh(ctx context.Context, token string, userid int64, appID int, duration, idle time.Duration) error
 }
 
 type userSaver interface {
@@ -57,7 +109,13 @@ func New(
 	aProvide appProvide,
 	tProvide tokenProvider,
 	rSaver refreshSaver,
+	lRecorder loginRecorder,
+	sessions sessionState,
+	grants grantProvider,
+	hasher passwordHasher,
+	metrics authMetrics,
 	tokenTTL time.Duration,
+	tokenOpts TokenOptions,
 ) *Auth {
 	return &Auth{
 		log:           log,
@@ -66,7 +124,13 @@ func New(
 		aProvide:      aProvide,
 		tokenProvider: tProvide,
 		refreshSaver:  rSaver,
+		lRecorder:     lRecorder,
+		sessions:      sessions,
+		grants:        grants,
+		hasher:        hasher,
+		metrics:       metrics,
 		tokenTTL:      tokenTTL,
+		tokenOpts:     tokenOpts,
 	}
 }
 
@@ -74,13 +138,19 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrInvalidAppID       = errors.New("invalid app id")
 	ErrUserAlreadyExists  = errors.New("user already registered")
+	ErrUserBanned         = errors.New("user is banned")
+	ErrInvalidAppSecret   = errors.New("invalid app credentials")
+	ErrTokenTooLarge      = errors.New("requested scopes do not fit into token")
+	ErrGrantNotAllowed    = errors.New("grant type is not allowed for app")
+	ErrAppDisabled        = errors.New("app is disabled")
 )
 
-func (a *Auth) RegisterNewUser(ctx context.Context, username string, email string, pass string) (int64, error) {
-	const op = "Auth.Register"
+// refreshFactor gives 16.6 days refresh tokens for default 1h access tokens
+const refreshFactor = 400
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
+// RegisterNewUser creates user in tenant of app, users registered without app belong to default tenant
+func (a *Auth) RegisterNewUser(ctx context.Context, username string, email string, pass string, appID uint64) (int64, error) {
+	const op = "Auth.Register"
 
 	log := a.log.With(
 		slog.String("attempting to register new user ", op),
@@ -89,7 +159,24 @@ func (a *Auth) RegisterNewUser(ctx context.Context, username string, email strin
 
 	log.Info("register new user")
 
-	hshpass, err := bcrypt.GenerateFromPassword([]byte(pass), bcrypt.DefaultCost)
+	tenantID := tenant.Default
+	if appID != 0 {
+		app, err := a.aProvide.App(ctx, appID)
+		if err != nil {
+			log.Warn("failed to get app", slog.Any("err", err))
+
+			return 0, fmt.Errorf("%s:%w", op, ErrInvalidAppID)
+		}
+		if app.Disabled {
+			log.Warn("registration to disabled app")
+
+			return 0, fmt.Errorf("%s:%w", op, ErrAppDisabled)
+		}
+		tenantID = app.TenantID
+	}
+	ctx = tenant.With(ctx, tenantID)
+
+	hshpass, err := a.hasher.Hash(pass)
 	if err != nil {
 		return 0, fmt.Errorf("error while hashing password")
 	}
@@ -107,16 +194,16 @@ func (a *Auth) RegisterNewUser(ctx context.Context, username string, email strin
 	}
 
 	log.Info("user successfully registered")
+	a.metrics.IncRegistration()
 
 	return id, nil
 }
 
-func (a *Auth) Login(ctx context.Context, email string, pass string, appID uint64) (string, error) {
+// Login issues access token of user in app, requested scopes are permission names and only those
+// which user is allowed in app are granted
+func (a *Auth) Login(ctx context.Context, email string, pass string, appID uint64, scopes []string) (string, error) {
 	const op = "Auth.Login"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
 	log := a.log.With(
 		slog.String("op", op),
 		slog.String("user starts login proccess to service", email),
@@ -124,10 +211,48 @@ func (a *Auth) Login(ctx context.Context, email string, pass string, appID uint6
 
 	log.Info("attempting to login user")
 
+	client := clientinfo.FromContext(ctx)
+	attempt := models.LoginAttempt{
+		Email:     email,
+		AppID:     appID,
+		Reason:    models.LoginInternal,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+	}
+	defer func() {
+		a.recordLogin(ctx, log, attempt)
+		a.metrics.IncLogin(attempt.Reason, appID)
+	}()
+
+	app, err := a.aProvide.App(ctx, appID)
+	if err != nil {
+		log.Error("failed to get app id", slog.Any("err", err))
+		attempt.Reason = models.LoginInvalidApp
+
+		return "", fmt.Errorf("%s:%w", op, ErrInvalidAppID)
+	}
+	if app.Disabled {
+		log.Warn("login to disabled app")
+		attempt.Reason = models.LoginAppDisabled
+
+		return "", fmt.Errorf("%s:%w", op, ErrAppDisabled)
+	}
+
+	// user is looked up in tenant of app, history and grants are written inside it too
+	ctx = tenant.With(ctx, app.TenantID)
+
+	if !app.Tokens.AllowsGrant(models.GrantPassword) {
+		log.Warn("app does not allow password grant")
+		attempt.Reason = models.LoginGrantDenied
+
+		return "", fmt.Errorf("%s:%w", op, ErrGrantNotAllowed)
+	}
+
 	user, err := a.uProvide.User(ctx, email)
 	if err != nil {
 		if errors.Is(err, storage.ErrUserNotFound) {
 			log.Warn("user not found", slog.Any("err", err))
+			attempt.Reason = models.LoginUnknownUser
 
 			return "", fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
 		}
@@ -135,48 +260,207 @@ func (a *Auth) Login(ctx context.Context, email string, pass string, appID uint6
 
 		return "", fmt.Errorf("%s:%w", op, ErrInvalidCredentials)
 	}
+	attempt.UserID = int64(user.ID)
 
-	if err := bcrypt.CompareHashAndPassword(user.HashedPass, []byte(pass)); err != nil {
+	if err := a.hasher.Compare(user.HashedPass, pass); err != nil {
 		log.Info("invalid credentials")
+		attempt.Reason = models.LoginBadPassword
 
 		return "", fmt.Errorf("%s:%w", op, ErrInvalidCredentials)
 	}
 
+	if user.Account_locked {
+		log.Warn("banned user tried to login")
+		attempt.Reason = models.LoginBanned
+
+		return "", fmt.Errorf("%s:%w", op, ErrUserBanned)
+	}
+
+	token, err := a.issue(ctx, log, user, app, scopes)
+	if err != nil {
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+
+	attempt.Success = true
+	attempt.Reason = models.LoginSuccess
+
+	return token, nil
+}
+
+// SessionToken issues new tokens to user who is already signed in to app, e.g. after password change
+// has revoked every previous token. User has to belong to tenant of app
+func (a *Auth) SessionToken(ctx context.Context, user models.User, appID uint64, scopes []string) (string, error) {
+	const op = "Auth.SessionToken"
+
+	log := a.log.With(
+		slog.String("op", op),
+		slog.Int("userID", user.ID),
+	)
+
 	app, err := a.aProvide.App(ctx, appID)
 	if err != nil {
-		log.Error("failed to get app id", slog.Any("err", err))
+		log.Warn("failed to get app", slog.Any("err", err))
 
 		return "", fmt.Errorf("%s:%w", op, ErrInvalidAppID)
 	}
-	refrToken, err := a.tokenProvider.NewToken(user, app, a.tokenTTL*400) //16.6 days
-	if err != nil {
-		a.log.Info("failed to create new refresh token")
+	if app.Disabled {
+		return "", fmt.Errorf("%s:%w", op, ErrAppDisabled)
+	}
+	if app.TenantID != tenant.OrDefault(user.TenantID) {
+		log.Warn("user and app belong to different tenants")
 
-		return "", fmt.Errorf("%s:%w", op, err)
+		return "", fmt.Errorf("%s:%w", op, ErrInvalidAppID)
 	}
 
-	if err := a.refreshSaver.SaveRefresh(ctx, refrToken, int64(user.ID), a.tokenTTL*400); err != nil {
-		a.log.Info("failed to save refresh token")
+	ctx = tenant.With(ctx, app.TenantID)
 
+	token, err := a.issue(ctx, log, user, app, scopes)
+	if err != nil {
 		return "", fmt.Errorf("%s:%w", op, err)
 	}
 
-	token, err := a.tokenProvider.NewToken(user, app, a.tokenTTL)
+	return token, nil
+}
+
+// issue saves refresh token when app allows refresh grant and returns access token with grant of scopes
+func (a *Auth) issue(ctx context.Context, log *slog.Logger, user models.User, app models.App, scopes []string) (string, error) {
+	// apps without refresh grant get only short lived access tokens
+	if app.Tokens.AllowsGrant(models.GrantRefreshToken) {
+		refreshTTL := a.refreshTTL(app)
+
+		refrToken, err := a.tokenProvider.NewRefreshToken(user, app, refreshTTL)
+		if err != nil {
+			log.Info("failed to create new refresh token")
+
+			return "", err
+		}
+
+		if err := a.refreshSaver.SaveRefresh(ctx, refrToken, int64(user.ID), app.ID, refreshTTL, app.Tokens.IdleTimeout); err != nil {
+			log.Info("failed to save refresh token")
+
+			return "", err
+		}
+		a.metrics.IncTokenIssued(metric.TokenRefresh)
+	}
+
+	grant, err := a.grant(ctx, int64(user.ID), uint64(app.ID), scopes)
 	if err != nil {
-		a.log.Info("failed to create new token ")
+		log.Error("failed to resolve grant", slog.Any("err", err))
 
-		return "", fmt.Errorf("%s:%w", op, err)
+		return "", err
 	}
+
+	token, err := a.accessToken(log, user, app, grant)
+	if err != nil {
+		log.Info("failed to create new token ")
+
+		return "", err
+	}
+	a.metrics.IncTokenIssued(metric.TokenAccess)
+
 	return token, nil
 }
 
+// grant resolves requested scopes and claims which are embedded by options
+func (a *Auth) grant(ctx context.Context, userID int64, appID uint64, scopes []string) (models.TokenGrant, error) {
+	var (
+		grant models.TokenGrant
+		err   error
+	)
+
+	if len(scopes) > 0 {
+		scopes = slices.Compact(slices.Sorted(slices.Values(scopes)))
+
+		grant.Scopes, err = a.grants.GrantedPermissions(ctx, userID, appID, scopes)
+		if err != nil {
+			return models.TokenGrant{}, err
+		}
+	}
+
+	if a.tokenOpts.Roles {
+		grant.Roles, err = a.grants.UserRoles(ctx, userID, appID)
+		if err != nil {
+			return models.TokenGrant{}, err
+		}
+		if grant.Roles == nil {
+			grant.Roles = []string{}
+		}
+	}
+
+	if a.tokenOpts.Permissions {
+		names, err := a.grants.PermissionNames(ctx)
+		if err != nil {
+			return models.TokenGrant{}, err
+		}
+		// permissions checked by Permissions service are embedded even when catalog has only wildcards of them
+		names = append(names, models.PermDeleteUser, models.PermUpdateUser, models.PermVideoDownload, models.PermChangeOptions)
+		names = slices.Compact(slices.Sorted(slices.Values(names)))
+
+		grant.Permissions, err = a.grants.GrantedPermissions(ctx, userID, appID, names)
+		if err != nil {
+			return models.TokenGrant{}, err
+		}
+	}
+
+	return grant, nil
+}
+
+// accessToken signs token with grant, when token exceeds size cap permissions and then roles
+// are left out, resource servers ask Permissions service for them then. Scopes are never dropped
+func (a *Auth) accessToken(log *slog.Logger, user models.User, app models.App, grant models.TokenGrant) (string, error) {
+	for {
+		token, err := a.tokenProvider.NewToken(user, app, a.accessTTL(app), grant)
+		if err != nil {
+			return "", err
+		}
+		if a.tokenOpts.MaxSize <= 0 || len(token) <= a.tokenOpts.MaxSize {
+			return token, nil
+		}
+
+		switch {
+		case grant.Permissions != nil:
+			log.Warn("permissions are left out of token", slog.Int("size", len(token)))
+			grant.Permissions = nil
+		case grant.Roles != nil:
+			log.Warn("roles are left out of token", slog.Int("size", len(token)))
+			grant.Roles = nil
+		default:
+			return "", ErrTokenTooLarge
+		}
+	}
+}
+
+func (a *Auth) accessTTL(app models.App) time.Duration {
+	if app.Tokens.AccessTTL > 0 {
+		if a.tokenOpts.MaxAccessTTL > 0 {
+			return min(app.Tokens.AccessTTL, a.tokenOpts.MaxAccessTTL)
+		}
+
+		return app.Tokens.AccessTTL
+	}
+
+	return a.tokenTTL
+}
+
+func (a *Auth) refreshTTL(app models.App) time.Duration {
+	if app.Tokens.RefreshTTL > 0 {
+		return app.Tokens.RefreshTTL
+	}
+
+	return a.tokenTTL * refreshFactor
+}
+
+// recordLogin saves attempt to login history, failure to save never breaks login itself
+func (a *Auth) recordLogin(ctx context.Context, log *slog.Logger, attempt models.LoginAttempt) {
+	if err := a.lRecorder.SaveLoginAttempt(ctx, attempt); err != nil {
+		log.Warn("failed to record login attempt", slog.Any("err", err))
+	}
+}
+
 // IMPLEMENT CACHING
 func (a *Auth) IsAdmin(ctx context.Context, userID int64) (bool, error) {
 	const op = "Auth.IsAdmin"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
 	log := a.log.With(
 		slog.String("op", op),
 		slog.Int64("userID", userID),
@@ -196,3 +480,177 @@ func (a *Auth) IsAdmin(ctx context.Context, userID int64) (bool, error) {
 
 	return isAdmin, nil
 }
+
+// AuthenticateApp checks credentials of backend app and returns its tenant, apps without secret cannot authenticate
+func (a *Auth) AuthenticateApp(ctx context.Context, appID int64, secret string) (int64, error) {
+	const op = "Auth.AuthenticateApp"
+
+	log := a.log.With(
+		slog.String("op", op),
+		slog.Int64("appID", appID),
+	)
+
+	app, err := a.aProvide.App(ctx, uint64(appID))
+	if err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			log.Warn("app not found")
+
+			return 0, fmt.Errorf("%s:%w", op, ErrInvalidAppSecret)
+		}
+
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	if app.Disabled {
+		log.Warn("disabled app tried to authenticate")
+
+		return 0, fmt.Errorf("%s:%w", op, ErrInvalidAppSecret)
+	}
+
+	hashes := make([]string, 0, len(app.Secrets))
+	for _, appSecret := range app.Secrets {
+		hashes = append(hashes, appSecret.Hash)
+	}
+	if !appsecret.Matches(hashes, secret) {
+		log.Warn("invalid app secret")
+
+		return 0, fmt.Errorf("%s:%w", op, ErrInvalidAppSecret)
+	}
+
+	return app.TenantID, nil
+}
+
+// AppTenant returns tenant of app which was identified by client certificate, disabled apps have no tenant
+func (a *Auth) AppTenant(ctx context.Context, appID int64) (int64, error) {
+	const op = "Auth.AppTenant"
+
+	app, err := a.aProvide.App(ctx, uint64(appID))
+	if err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return 0, fmt.Errorf("%s:%w", op, ErrInvalidAppID)
+		}
+
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	if app.Disabled {
+		return 0, fmt.Errorf("%s:%w", op, ErrAppDisabled)
+	}
+
+	return app.TenantID, nil
+}
+
+// Introspect tells whether token is still active, invalid, expired and revoked tokens are
+// reported as inactive, error is returned only when state of token cannot be checked
+func (a *Auth) Introspect(ctx context.Context, token string) (models.Introspection, error) {
+	const op = "Auth.Introspect"
+
+	log := a.log.With(slog.String("op", op))
+
+	claims, err := a.tokenProvider.ParseClaims(ctx, token)
+	if err != nil {
+		log.Debug("token is not valid", slog.Any("err", err))
+
+		return models.Introspection{}, nil
+	}
+	// resource servers introspect bearer tokens, refresh token must not pass for one
+	if claims.Type != models.TokenTypeAccess {
+		log.Info("refresh token is introspected")
+
+		return models.Introspection{}, nil
+	}
+
+	log = log.With(slog.Int64("userID", claims.UserID))
+
+	// apps see only tokens of their own tenant
+	tenantID := tenant.OrDefault(claims.TenantID)
+	if caller, ok := tenant.FromContext(ctx); ok && caller != tenantID {
+		log.Info("token of another tenant")
+
+		return models.Introspection{}, nil
+	}
+	ctx = tenant.With(ctx, tenantID)
+
+	app, err := a.aProvide.App(ctx, uint64(claims.AppID))
+	if err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			log.Info("token of deleted app")
+
+			return models.Introspection{}, nil
+		}
+
+		return models.Introspection{}, fmt.Errorf("%s:%w", op, err)
+	}
+	if app.Disabled {
+		log.Info("token of disabled app")
+
+		return models.Introspection{}, nil
+	}
+	if app.TenantID != tenantID {
+		log.Warn("tenant of token differs from tenant of its app")
+
+		return models.Introspection{}, nil
+	}
+
+	active, err := a.sessionActive(ctx, log, claims)
+	if err != nil {
+		return models.Introspection{}, fmt.Errorf("%s:%w", op, err)
+	}
+	if !active {
+		return models.Introspection{}, nil
+	}
+
+	claims.TenantID = tenantID
+
+	return models.Introspection{Active: true, Claims: claims}, nil
+}
+
+// SessionActive tells interceptor whether validated token may still be used: its user exists,
+// is not banned and has not revoked tokens after the token was issued, e.g. by password change.
+// Context has to be scoped to tenant of token
+func (a *Auth) SessionActive(ctx context.Context, claims models.TokenClaims) (bool, error) {
+	const op = "Auth.SessionActive"
+
+	log := a.log.With(
+		slog.String("op", op),
+		slog.Int64("userID", claims.UserID),
+	)
+
+	active, err := a.sessionActive(ctx, log, claims)
+	if err != nil {
+		return false, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return active, nil
+}
+
+func (a *Auth) sessionActive(ctx context.Context, log *slog.Logger, claims models.TokenClaims) (bool, error) {
+	ban, err := a.sessions.IsUsrBanned(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Info("token of deleted user")
+
+			return false, nil
+		}
+
+		return false, err
+	}
+	if ban.IsBanned {
+		log.Info("token of banned user")
+
+		return false, nil
+	}
+
+	revokedAt, err := a.sessions.TokensRevokedAt(ctx, claims.UserID)
+	if err != nil {
+		return false, err
+	}
+	// iat has seconds precision, so tokens issued in the same second as revocation stay active
+	if !revokedAt.IsZero() && claims.IssuedAt.Before(revokedAt.Truncate(time.Second)) {
+		log.Info("token was revoked")
+
+		return false, nil
+	}
+
+	return true, nil
+}
@@ -0,0 +1,177 @@
+package authsvc_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"sso/internal/domain/models"
+	"sso/internal/dto"
+	"sso/internal/services/authsvc"
+	"sso/internal/services/profilesvc"
+	"sso/internal/storage"
+)
+
+const userID = 7
+
+// fakeUser keeps session state of one user the way postgres repositories do
+type fakeUser struct {
+	hash      []byte
+	banned    bool
+	revokedAt time.Time
+}
+
+func (u *fakeUser) IsUsrBanned(_ context.Context, id int64) (*dto.IsBannedRespStruct, error) {
+	if id != userID {
+		return nil, storage.ErrUserNotFound
+	}
+
+	return &dto.IsBannedRespStruct{IsBanned: u.banned}, nil
+}
+
+func (u *fakeUser) TokensRevokedAt(_ context.Context, _ int64) (time.Time, error) {
+	return u.revokedAt, nil
+}
+
+// ban does what statrepo.BanUser does: ban row is saved and tokens are revoked
+func (u *fakeUser) ban() {
+	u.banned = true
+	u.revokedAt = time.Now()
+}
+
+func (u *fakeUser) Profile(_ context.Context, id int64) (models.Profile, error) {
+	return models.Profile{UserID: id, TenantID: 1, Email: "user@example.com"}, nil
+}
+
+func (u *fakeUser) PasswordHash(_ context.Context, _ int64) ([]byte, error) {
+	return u.hash, nil
+}
+
+// SetPassword does what profilerepo.SetPassword does
+func (u *fakeUser) SetPassword(_ context.Context, _ int64, hash []byte) error {
+	u.hash = hash
+	u.revokedAt = time.Now()
+
+	return nil
+}
+
+func (u *fakeUser) UpdateProfile(context.Context, int64, string, string) error { return nil }
+
+func (u *fakeUser) SaveEmailChange(context.Context, models.EmailChange) error { return nil }
+
+func (u *fakeUser) EmailChange(context.Context, int64) (models.EmailChange, error) {
+	return models.EmailChange{}, storage.ErrEmailChangeMissing
+}
+
+func (u *fakeUser) ConfirmEmailChange(context.Context, int64, string) error { return nil }
+
+type plainHasher struct{}
+
+func (plainHasher) Hash(password string) ([]byte, error) { return []byte(password), nil }
+
+func (plainHasher) Compare(hash []byte, password string) error {
+	if !bytes.Equal(hash, []byte(password)) {
+		return errors.New("mismatch")
+	}
+
+	return nil
+}
+
+type stubIssuer struct{}
+
+func (stubIssuer) SessionToken(context.Context, models.User, uint64, []string) (string, error) {
+	return "new-token", nil
+}
+
+func TestSessionActive(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// change is made to session state after token was issued
+		change func(t *testing.T, user *fakeUser, profiles *profilesvc.Profiles)
+		// issued is moment token was issued relative to the change
+		issued time.Duration
+		want   bool
+	}{
+		{
+			name:   "untouched session",
+			change: func(*testing.T, *fakeUser, *profilesvc.Profiles) {},
+			issued: -time.Minute,
+			want:   true,
+		},
+		{
+			name: "old token after ChangePassword",
+			change: func(t *testing.T, _ *fakeUser, profiles *profilesvc.Profiles) {
+				if _, err := profiles.ChangePassword(ctx, userID, 1, nil, "old-password", "new-password"); err != nil {
+					t.Fatalf("ChangePassword: %v", err)
+				}
+			},
+			issued: -time.Minute,
+			want:   false,
+		},
+		{
+			name: "token issued after ChangePassword",
+			change: func(t *testing.T, _ *fakeUser, profiles *profilesvc.Profiles) {
+				if _, err := profiles.ChangePassword(ctx, userID, 1, nil, "old-password", "new-password"); err != nil {
+					t.Fatalf("ChangePassword: %v", err)
+				}
+			},
+			issued: time.Second,
+			want:   true,
+		},
+		{
+			name: "old token after BanUser",
+			change: func(_ *testing.T, user *fakeUser, _ *profilesvc.Profiles) {
+				user.ban()
+			},
+			issued: -time.Minute,
+			want:   false,
+		},
+		{
+			name: "token issued after BanUser",
+			change: func(_ *testing.T, user *fakeUser, _ *profilesvc.Profiles) {
+				user.ban()
+			},
+			issued: time.Second,
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &fakeUser{hash: []byte("old-password")}
+			auth := authsvc.New(log, nil, nil, nil, nil, nil, nil, user, nil, nil, nil, time.Hour, authsvc.TokenOptions{})
+			profiles := profilesvc.New(log, user, plainHasher{}, stubIssuer{}, nil, time.Hour)
+
+			tt.change(t, user, profiles)
+
+			claims := models.TokenClaims{UserID: userID, IssuedAt: time.Now().Add(tt.issued)}
+			got, err := auth.SessionActive(ctx, claims)
+			if err != nil {
+				t.Fatalf("SessionActive: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SessionActive = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionActiveDeletedUser(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	auth := authsvc.New(log, nil, nil, nil, nil, nil, nil, &fakeUser{}, nil, nil, nil, time.Hour, authsvc.TokenOptions{})
+
+	active, err := auth.SessionActive(context.Background(), models.TokenClaims{UserID: userID + 1, IssuedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("SessionActive: %v", err)
+	}
+	if active {
+		t.Error("token of deleted user is active")
+	}
+}
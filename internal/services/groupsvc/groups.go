@@ -0,0 +1,252 @@
+package groupsvc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"sso/internal/domain/models"
+)
+
+type Groups struct {
+	log          *slog.Logger
+	groups       GroupStore
+	cacheCleaner PermCacheCleaner
+}
+
+type GroupStore interface {
+	CreateGroup(ctx context.Context, group models.Group) (models.Group, error)
+	Group(ctx context.Context, groupID int64) (models.Group, error)
+	Groups(ctx context.Context) ([]models.Group, error)
+	UpdateGroup(ctx context.Context, group models.Group) error
+	DeleteGroup(ctx context.Context, groupID int64) error
+	AddMember(ctx context.Context, groupID int64, userID int64) error
+	RemoveMember(ctx context.Context, groupID int64, userID int64) error
+	BindRole(ctx context.Context, groupID int64, role string, appID uint64) error
+	UnbindRole(ctx context.Context, groupID int64, role string, appID uint64) error
+	Members(ctx context.Context, groupID int64, nested bool) ([]models.GroupMember, error)
+	UserGroups(ctx context.Context, userID int64) ([]models.UserGroup, error)
+}
+
+// PermCacheCleaner drops cached permission decisions after roles of users have been changed
+type PermCacheCleaner interface {
+	FlushPermissions(ctx context.Context) error
+}
+
+// New returns a new instance of groups service, cacheCleaner can be nil
+func New(
+	log *slog.Logger,
+	groups GroupStore,
+	cacheCleaner PermCacheCleaner,
+) *Groups {
+	return &Groups{
+		log:          log,
+		groups:       groups,
+		cacheCleaner: cacheCleaner,
+	}
+}
+
+func (g *Groups) Create(ctx context.Context, group models.Group) (models.Group, error) {
+	const op = "groupsvc.Create"
+
+	log := g.log.With(
+		slog.String("op", op),
+		slog.String("name", group.Name),
+	)
+
+	created, err := g.groups.CreateGroup(ctx, group)
+	if err != nil {
+		log.Warn("failed to create group", slog.Any("err", err))
+
+		return models.Group{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("group created", slog.Int64("group_id", created.ID))
+
+	return created, nil
+}
+
+func (g *Groups) Group(ctx context.Context, groupID int64) (models.Group, error) {
+	const op = "groupsvc.Group"
+
+	group, err := g.groups.Group(ctx, groupID)
+	if err != nil {
+		return models.Group{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return group, nil
+}
+
+func (g *Groups) Groups(ctx context.Context) ([]models.Group, error) {
+	const op = "groupsvc.Groups"
+
+	groups, err := g.groups.Groups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return groups, nil
+}
+
+// Update replaces name, description and parent of group and returns updated group,
+// moving group changes roles which its members inherit
+func (g *Groups) Update(ctx context.Context, group models.Group) (models.Group, error) {
+	const op = "groupsvc.Update"
+
+	log := g.log.With(
+		slog.String("op", op),
+		slog.Int64("group_id", group.ID),
+	)
+
+	if err := g.groups.UpdateGroup(ctx, group); err != nil {
+		log.Warn("failed to update group", slog.Any("err", err))
+
+		return models.Group{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("group updated", slog.Int64("parent_id", group.ParentID))
+	g.flush(ctx, log)
+
+	return g.Group(ctx, group.ID)
+}
+
+func (g *Groups) Delete(ctx context.Context, groupID int64) error {
+	const op = "groupsvc.Delete"
+
+	log := g.log.With(
+		slog.String("op", op),
+		slog.Int64("group_id", groupID),
+	)
+
+	if err := g.groups.DeleteGroup(ctx, groupID); err != nil {
+		log.Warn("failed to delete group", slog.Any("err", err))
+
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("group deleted")
+	g.flush(ctx, log)
+
+	return nil
+}
+
+func (g *Groups) AddMember(ctx context.Context, groupID int64, userID int64) error {
+	const op = "groupsvc.AddMember"
+
+	log := g.log.With(
+		slog.String("op", op),
+		slog.Int64("group_id", groupID),
+		slog.Int64("user_id", userID),
+	)
+
+	if err := g.groups.AddMember(ctx, groupID, userID); err != nil {
+		log.Warn("failed to add member", slog.Any("err", err))
+
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("member added")
+	g.flush(ctx, log)
+
+	return nil
+}
+
+func (g *Groups) RemoveMember(ctx context.Context, groupID int64, userID int64) error {
+	const op = "groupsvc.RemoveMember"
+
+	log := g.log.With(
+		slog.String("op", op),
+		slog.Int64("group_id", groupID),
+		slog.Int64("user_id", userID),
+	)
+
+	if err := g.groups.RemoveMember(ctx, groupID, userID); err != nil {
+		log.Warn("failed to remove member", slog.Any("err", err))
+
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("member removed")
+	g.flush(ctx, log)
+
+	return nil
+}
+
+// BindRole grants role to every member of group and of its subgroups, app 0 means every app
+func (g *Groups) BindRole(ctx context.Context, groupID int64, role string, appID uint64) error {
+	const op = "groupsvc.BindRole"
+
+	log := g.log.With(
+		slog.String("op", op),
+		slog.Int64("group_id", groupID),
+		slog.String("role", role),
+		slog.Uint64("app_id", appID),
+	)
+
+	if err := g.groups.BindRole(ctx, groupID, role, appID); err != nil {
+		log.Warn("failed to bind role", slog.Any("err", err))
+
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("role bound to group")
+	g.flush(ctx, log)
+
+	return nil
+}
+
+func (g *Groups) UnbindRole(ctx context.Context, groupID int64, role string, appID uint64) error {
+	const op = "groupsvc.UnbindRole"
+
+	log := g.log.With(
+		slog.String("op", op),
+		slog.Int64("group_id", groupID),
+		slog.String("role", role),
+		slog.Uint64("app_id", appID),
+	)
+
+	if err := g.groups.UnbindRole(ctx, groupID, role, appID); err != nil {
+		log.Warn("failed to unbind role", slog.Any("err", err))
+
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("role unbound from group")
+	g.flush(ctx, log)
+
+	return nil
+}
+
+func (g *Groups) Members(ctx context.Context, groupID int64, nested bool) ([]models.GroupMember, error) {
+	const op = "groupsvc.Members"
+
+	members, err := g.groups.Members(ctx, groupID, nested)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return members, nil
+}
+
+func (g *Groups) UserGroups(ctx context.Context, userID int64) ([]models.UserGroup, error) {
+	const op = "groupsvc.UserGroups"
+
+	groups, err := g.groups.UserGroups(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return groups, nil
+}
+
+// flush drops cached permissions, change is already saved, so failure is only logged
+// and stale decisions live until cache ttl
+func (g *Groups) flush(ctx context.Context, log *slog.Logger) {
+	if g.cacheCleaner == nil {
+		return
+	}
+
+	if err := g.cacheCleaner.FlushPermissions(ctx); err != nil {
+		log.Warn("failed to flush permissions cache", slog.Any("err", err))
+	}
+}
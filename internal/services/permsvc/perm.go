@@ -6,9 +6,9 @@ import (
 	"fmt"
 	"log/slog"
 	"sso/internal/domain/models"
+	"sso/internal/lib/metric"
 	"sso/internal/storage"
 
-	"github.com/opentracing/opentracing-go"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -18,6 +18,11 @@ type Permissions struct {
 	deleteUserPermit  DltUserPermProvider
 	optionsUserPermit OptionsUserProvider
 	redisRepo         NewRedisPermRepo
+	metrics           CheckMetrics
+}
+
+type CheckMetrics interface {
+	IncPermissionCheck(permission, decision, tier string)
 }
 
 type NewRedisPermRepo interface {
@@ -44,12 +49,16 @@ func New(
 	downloadPermit DownloadPermProvider,
 	deleteUserPermit DltUserPermProvider,
 	optionsUserPermit OptionsUserProvider,
+	redisRepo NewRedisPermRepo,
+	metrics CheckMetrics,
 ) *Permissions {
 	return &Permissions{
 		log:               log,
 		downloadPermit:    downloadPermit,
 		deleteUserPermit:  deleteUserPermit,
 		optionsUserPermit: optionsUserPermit,
+		redisRepo:         redisRepo,
+		metrics:           metrics,
 	}
 }
 
@@ -65,164 +74,100 @@ const (
 	DeletePermDuration   = 600
 )
 
+// permissionKey builds cache key of resolved permission.
+//
+// Key holds the checked permission name, not the grant that matched it,
+// so wildcard and deny resolution results are cached per permission
+func permissionKey(permName string, userID int64, appID uint64) string {
+	return fmt.Sprintf("permission:%s:%d:%d", permName, userID, appID)
+}
+
 func (p *Permissions) CheckDwnldPermission(ctx context.Context, userID int64, appID uint64) (bool, error) {
 	const op = "permsvc.DownloadPermission"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
-	key := fmt.Sprintf("dwnldpermission: %d:%d", userID, appID)
-
-	log := p.log.With(
-		slog.String("getting permission for download", op),
-		slog.Int64("userid", userID),
-	)
-
-	cachedPerms, err := p.redisRepo.PermissionCtx(ctx, key)
-	if err != nil && !errors.Is(err, redis.Nil) {
-		p.log.Error("%s:%w", op, err)
-	}
-	if cachedPerms != nil {
-		return cachedPerms.Perm, nil
-	}
-
-	permission, err := p.downloadPermit.DownloadPermission(ctx, userID, appID)
-	if err != nil {
-		if errors.Is(err, storage.ErrAppNotFound) {
-			log.Warn("cannot execute that option, seems thats forbidden")
-
-			return false, fmt.Errorf("%s:%w", op, ErrForbiddenForUser)
-		}
-		log.Warn("failed to get permission")
-
-		return false, fmt.Errorf("%s:%w", op, storage.ErrAppNotFound)
-	}
-
-	if err := p.redisRepo.SetPermCtx(ctx, key, DownloadPermDuration, permission); err != nil {
-		p.log.Error("%s:%w", op, err)
-	}
-
-	return permission.Perm, nil
-
+	return p.checkPermission(ctx, op, models.PermVideoDownload, userID, appID, DownloadPermDuration,
+		p.downloadPermit.DownloadPermission)
 }
 
 func (p *Permissions) CheckDltUsrPermit(ctx context.Context, userID int64, appID uint64) (bool, error) {
 	const op = "permsvc.DeletePermission"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
-	key := fmt.Sprintf("dltpermission: %d:%d", userID, appID)
-
-	log := p.log.With(
-		slog.String("getting permission for download", op),
-		slog.Int64("userid", userID),
-	)
-	cachedPerms, err := p.redisRepo.PermissionCtx(ctx, key)
-	if err != nil && !errors.Is(err, redis.Nil) {
-		p.log.Error("%s:%w", op, err)
-	}
-	if cachedPerms != nil {
-		return cachedPerms.Perm, nil
-	}
-
-	permission, err := p.deleteUserPermit.DeleteUsrPermission(ctx, userID, appID)
-	if err != nil {
-		if errors.Is(err, storage.ErrAppNotFound) {
-			log.Warn("cannot execute that option, seems thats forbidden")
-
-			return false, fmt.Errorf("%s:%w", op, ErrForbiddenForUser)
-		}
-		log.Warn("failed to get permission")
-
-		return false, fmt.Errorf("%s:%w", op, storage.ErrAppNotFound)
-	}
-
-	if err := p.redisRepo.SetPermCtx(ctx, key, DeletePermDuration, permission); err != nil {
-		p.log.Error("%s:%w", op, err)
-	}
-
-	return permission.Perm, nil
+	return p.checkPermission(ctx, op, models.PermDeleteUser, userID, appID, DeletePermDuration,
+		p.deleteUserPermit.DeleteUsrPermission)
 }
 
-// HERE
 func (p *Permissions) CheckUpdUsrPermit(ctx context.Context, userID int64, appID uint64) (bool, error) {
 	const op = "permsvc.UpdatePermission"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
-	key := fmt.Sprintf("updpermission: %d:%d", userID, appID)
-
-	log := p.log.With(
-		slog.String("getting permission for update", op),
-		slog.Int64("userid", userID),
-	)
-
-	cachedPerms, err := p.redisRepo.PermissionCtx(ctx, key)
-	if err != nil && !errors.Is(err, redis.Nil) {
-		p.log.Error("%s:%w", op, err)
-	}
-	if cachedPerms != nil {
-		return cachedPerms.Perm, nil
-	}
-
-	permission, err := p.optionsUserPermit.UpdateUsrPermission(ctx, userID, appID)
-	if err != nil {
-		if errors.Is(err, storage.ErrAppNotFound) {
-			log.Warn("cannot execute that option, seems thats forbidden")
-
-			return false, fmt.Errorf("%s:%w", op, ErrForbiddenForUser)
-		}
-		log.Warn("failed to get permission")
-
-		return false, fmt.Errorf("%s:%w", op, storage.ErrAppNotFound)
-	}
-
-	if err := p.redisRepo.SetPermCtx(ctx, key, UpdatePermDuration, permission); err != nil {
-		p.log.Error("%s:%w", op, err)
-	}
-
-	return permission.Perm, nil
+	return p.checkPermission(ctx, op, models.PermUpdateUser, userID, appID, UpdatePermDuration,
+		p.optionsUserPermit.UpdateUsrPermission)
 }
 
-// HERE
 func (p *Permissions) CheckChgOptPerm(ctx context.Context, userID int64, appID uint64) (bool, error) {
 	const op = "permsvc.ChangeOptionPermission"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
+	return p.checkPermission(ctx, op, models.PermChangeOptions, userID, appID, ChangePermDuration,
+		p.optionsUserPermit.ChangeOptionPermission)
+}
+
+type permissionFetcher func(ctx context.Context, userID int64, appID uint64) (models.Permission, error)
 
-	key := fmt.Sprintf("chgpermission: %d:%d", userID, appID)
+// checkPermission answers from cache and falls back to fetch, fetched decision is cached for seconds
+func (p *Permissions) checkPermission(
+	ctx context.Context,
+	op string,
+	permName string,
+	userID int64,
+	appID uint64,
+	seconds int,
+	fetch permissionFetcher,
+) (bool, error) {
+	key := permissionKey(permName, userID, appID)
 
 	log := p.log.With(
-		slog.String("getting permission for change options", op),
+		slog.String("op", op),
+		slog.String("permission", permName),
 		slog.Int64("userid", userID),
+		slog.Uint64("appid", appID),
 	)
 
 	cachedPerms, err := p.redisRepo.PermissionCtx(ctx, key)
 	if err != nil && !errors.Is(err, redis.Nil) {
-		p.log.Error("%s:%w", op, err)
+		log.Error("failed to read permission cache", slog.Any("err", err))
 	}
 	if cachedPerms != nil {
+		p.metrics.IncPermissionCheck(permName, decision(cachedPerms.Perm), metric.TierCache)
+
 		return cachedPerms.Perm, nil
 	}
 
-	permission, err := p.optionsUserPermit.ChangeOptionPermission(ctx, userID, appID)
+	permission, err := fetch(ctx, userID, appID)
 	if err != nil {
+		p.metrics.IncPermissionCheck(permName, metric.DecisionError, metric.TierDatabase)
+
 		if errors.Is(err, storage.ErrAppNotFound) {
-			log.Warn("cannot execute that option, seems thats forbidden")
+			log.Warn("app not found")
 
-			return false, fmt.Errorf("%s:%w", op, ErrForbiddenForUser)
+			return false, fmt.Errorf("%s:%w", op, storage.ErrAppNotFound)
 		}
-		log.Warn("failed to get permission")
+		log.Error("failed to get permission", slog.Any("err", err))
 
-		return false, fmt.Errorf("%s:%w", op, storage.ErrAppNotFound)
+		return false, fmt.Errorf("%s:%w", op, ErrInternal)
 	}
 
-	if err := p.redisRepo.SetPermCtx(ctx, key, ChangePermDuration, permission); err != nil {
-		p.log.Error("%s:%w", op, err)
+	if err := p.redisRepo.SetPermCtx(ctx, key, seconds, permission); err != nil {
+		log.Error("failed to cache permission", slog.Any("err", err))
 	}
 
+	p.metrics.IncPermissionCheck(permName, decision(permission.Perm), metric.TierDatabase)
+
 	return permission.Perm, nil
 }
+
+func decision(allowed bool) string {
+	if allowed {
+		return metric.DecisionAllow
+	}
+
+	return metric.DecisionDeny
+}
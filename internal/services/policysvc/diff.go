@@ -0,0 +1,126 @@
+package policysvc
+
+import (
+	"sort"
+	"sso/internal/domain/models"
+)
+
+// diffPolicy returns changes that turn current policy into desired one.
+//
+// Changes are ordered so that every statement can be executed:
+// roles and permissions are created before they are referenced,
+// and references are removed before roles and permissions are dropped
+func diffPolicy(current, desired models.Policy) []models.PolicyChange {
+	var changes []models.PolicyChange
+
+	currentRoles := make(map[string]models.PolicyRole, len(current.Roles))
+	for _, role := range current.Roles {
+		currentRoles[role.Name] = role
+	}
+	desiredRoles := make(map[string]bool, len(desired.Roles))
+	for _, role := range desired.Roles {
+		desiredRoles[role.Name] = true
+
+		stored, ok := currentRoles[role.Name]
+		switch {
+		case !ok:
+			changes = append(changes, models.PolicyChange{Op: models.PolicyAdd, Kind: models.PolicyKindRole, Role: role})
+		case stored.Description != role.Description:
+			changes = append(changes, models.PolicyChange{Op: models.PolicyUpdate, Kind: models.PolicyKindRole, Role: role})
+		}
+	}
+	for _, role := range current.Roles {
+		if !desiredRoles[role.Name] {
+			changes = append(changes, models.PolicyChange{Op: models.PolicyRemove, Kind: models.PolicyKindRole, Role: role})
+		}
+	}
+
+	currentPerms := toSet(current.Permissions)
+	desiredPerms := toSet(desired.Permissions)
+	for perm := range desiredPerms {
+		if !currentPerms[perm] {
+			changes = append(changes, models.PolicyChange{Op: models.PolicyAdd, Kind: models.PolicyKindPermission, Permission: perm})
+		}
+	}
+	for perm := range currentPerms {
+		if !desiredPerms[perm] {
+			changes = append(changes, models.PolicyChange{Op: models.PolicyRemove, Kind: models.PolicyKindPermission, Permission: perm})
+		}
+	}
+
+	type rolePermKey struct{ role, perm string }
+	currentRolePerms := make(map[rolePermKey]models.PolicyRolePermission, len(current.RolePermissions))
+	for _, rp := range current.RolePermissions {
+		currentRolePerms[rolePermKey{rp.Role, rp.Permission}] = rp
+	}
+	desiredRolePerms := make(map[rolePermKey]bool, len(desired.RolePermissions))
+	for _, rp := range desired.RolePermissions {
+		key := rolePermKey{rp.Role, rp.Permission}
+		desiredRolePerms[key] = true
+
+		stored, ok := currentRolePerms[key]
+		switch {
+		case !ok:
+			changes = append(changes, models.PolicyChange{Op: models.PolicyAdd, Kind: models.PolicyKindRolePermission, RolePermission: rp})
+		case stored.Effect != rp.Effect:
+			changes = append(changes, models.PolicyChange{Op: models.PolicyUpdate, Kind: models.PolicyKindRolePermission, RolePermission: rp})
+		}
+	}
+	for _, rp := range current.RolePermissions {
+		if !desiredRolePerms[rolePermKey{rp.Role, rp.Permission}] {
+			changes = append(changes, models.PolicyChange{Op: models.PolicyRemove, Kind: models.PolicyKindRolePermission, RolePermission: rp})
+		}
+	}
+
+	currentBindings := make(map[models.PolicyBinding]bool, len(current.Bindings))
+	for _, b := range current.Bindings {
+		currentBindings[b] = true
+	}
+	desiredBindings := make(map[models.PolicyBinding]bool, len(desired.Bindings))
+	for _, b := range desired.Bindings {
+		desiredBindings[b] = true
+		if !currentBindings[b] {
+			changes = append(changes, models.PolicyChange{Op: models.PolicyAdd, Kind: models.PolicyKindBinding, Binding: b})
+		}
+	}
+	for _, b := range current.Bindings {
+		if !desiredBindings[b] {
+			changes = append(changes, models.PolicyChange{Op: models.PolicyRemove, Kind: models.PolicyKindBinding, Binding: b})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if ri, rj := changeStage(changes[i]), changeStage(changes[j]); ri != rj {
+			return ri < rj
+		}
+		return changes[i].String() < changes[j].String()
+	})
+
+	return changes
+}
+
+// changeStage is the position of change in the apply order
+func changeStage(c models.PolicyChange) int {
+	remove := c.Op == models.PolicyRemove
+
+	switch c.Kind {
+	case models.PolicyKindRole, models.PolicyKindPermission:
+		if remove {
+			return 3
+		}
+		return 0
+	default:
+		if remove {
+			return 1
+		}
+		return 2
+	}
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
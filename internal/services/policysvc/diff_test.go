@@ -0,0 +1,136 @@
+package policysvc
+
+import (
+	"reflect"
+	"testing"
+
+	"sso/internal/domain/models"
+)
+
+func changeStrings(changes []models.PolicyChange) []string {
+	out := make([]string, 0, len(changes))
+	for _, c := range changes {
+		out = append(out, c.String())
+	}
+
+	return out
+}
+
+func TestDiffPolicy(t *testing.T) {
+	current := models.Policy{
+		Roles: []models.PolicyRole{
+			{Name: "viewer", Description: "can watch"},
+			{Name: "legacy"},
+		},
+		Permissions: []string{"video:watch", "video:download", "old:perm"},
+		RolePermissions: []models.PolicyRolePermission{
+			{Role: "viewer", Permission: "video:watch", Effect: models.EffectAllow},
+			{Role: "viewer", Permission: "video:download", Effect: models.EffectAllow},
+			{Role: "legacy", Permission: "old:perm", Effect: models.EffectAllow},
+		},
+		Bindings: []models.PolicyBinding{
+			{User: "ann@example.com", Role: "viewer", AppID: 1},
+			{User: "bob@example.com", Role: "legacy", AppID: models.AllApps},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		current models.Policy
+		desired models.Policy
+		want    []string
+	}{
+		{
+			name:    "same policy has no changes",
+			current: current,
+			desired: current,
+			want:    []string{},
+		},
+		{
+			name:    "empty to empty",
+			current: models.Policy{},
+			desired: models.Policy{},
+			want:    []string{},
+		},
+		{
+			name:    "new objects are created before they are referenced",
+			current: models.Policy{},
+			desired: models.Policy{
+				Roles:           []models.PolicyRole{{Name: "viewer"}},
+				Permissions:     []string{"video:watch"},
+				RolePermissions: []models.PolicyRolePermission{{Role: "viewer", Permission: "video:watch", Effect: models.EffectAllow}},
+				Bindings:        []models.PolicyBinding{{User: "ann@example.com", Role: "viewer", AppID: 1}},
+			},
+			want: []string{
+				"+ permission video:watch",
+				"+ role viewer",
+				"+ binding ann@example.com -> viewer (app 1)",
+				"+ role_permission viewer -> video:watch (allow)",
+			},
+		},
+		{
+			name:    "references are removed before objects are dropped",
+			current: current,
+			desired: models.Policy{
+				Roles:       []models.PolicyRole{{Name: "viewer", Description: "can watch"}},
+				Permissions: []string{"video:watch", "video:download"},
+				RolePermissions: []models.PolicyRolePermission{
+					{Role: "viewer", Permission: "video:watch", Effect: models.EffectAllow},
+					{Role: "viewer", Permission: "video:download", Effect: models.EffectAllow},
+				},
+				Bindings: []models.PolicyBinding{{User: "ann@example.com", Role: "viewer", AppID: 1}},
+			},
+			want: []string{
+				"- binding bob@example.com -> legacy (app 0)",
+				"- role_permission legacy -> old:perm (allow)",
+				"- permission old:perm",
+				"- role legacy",
+			},
+		},
+		{
+			name:    "changed description and effect are updates",
+			current: current,
+			desired: models.Policy{
+				Roles:       []models.PolicyRole{{Name: "viewer", Description: "can watch videos"}, {Name: "legacy"}},
+				Permissions: current.Permissions,
+				RolePermissions: []models.PolicyRolePermission{
+					{Role: "viewer", Permission: "video:watch", Effect: models.EffectAllow},
+					{Role: "viewer", Permission: "video:download", Effect: models.EffectDeny},
+					{Role: "legacy", Permission: "old:perm", Effect: models.EffectAllow},
+				},
+				Bindings: current.Bindings,
+			},
+			want: []string{
+				"~ role viewer (can watch videos)",
+				"~ role_permission viewer -> video:download (deny)",
+			},
+		},
+		{
+			name:    "binding moved to another app",
+			current: current,
+			desired: models.Policy{
+				Roles:           current.Roles,
+				Permissions:     current.Permissions,
+				RolePermissions: current.RolePermissions,
+				Bindings: []models.PolicyBinding{
+					{User: "ann@example.com", Role: "viewer", AppID: 2},
+					{User: "bob@example.com", Role: "legacy", AppID: models.AllApps},
+				},
+			},
+			want: []string{
+				"- binding ann@example.com -> viewer (app 1)",
+				"+ binding ann@example.com -> viewer (app 2)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := changeStrings(diffPolicy(tt.current, tt.desired))
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffPolicy() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package policysvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sso/internal/domain/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Policy struct {
+	log          *slog.Logger
+	provider     PolicyProvider
+	cacheCleaner PermCacheCleaner
+}
+
+type PolicyProvider interface {
+	Policy(ctx context.Context) (models.Policy, error)
+	ApplyPolicyChanges(ctx context.Context, changes []models.PolicyChange) error
+}
+
+// PermCacheCleaner drops cached permission decisions after policy has been changed
+type PermCacheCleaner interface {
+	FlushPermissions(ctx context.Context) error
+}
+
+// New returns a new instance of policy service, cacheCleaner can be nil
+func New(
+	log *slog.Logger,
+	provider PolicyProvider,
+	cacheCleaner PermCacheCleaner,
+) *Policy {
+	return &Policy{
+		log:          log,
+		provider:     provider,
+		cacheCleaner: cacheCleaner,
+	}
+}
+
+var (
+	ErrInvalidPolicy = errors.New("invalid policy document")
+)
+
+// Export returns stored RBAC model as yaml document
+func (p *Policy) Export(ctx context.Context) ([]byte, error) {
+	const op = "policysvc.Export"
+
+	log := p.log.With(slog.String("op", op))
+
+	policy, err := p.provider.Policy(ctx)
+	if err != nil {
+		log.Error("failed to read policy", slog.Any("err", err))
+
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	document, err := yaml.Marshal(policy)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return document, nil
+}
+
+// Import makes stored RBAC model equal to yaml document and returns applied changes.
+//
+// With dryRun changes are only calculated. Import of the same document twice
+// changes nothing the second time
+func (p *Policy) Import(ctx context.Context, document []byte, dryRun bool) ([]models.PolicyChange, error) {
+	const op = "policysvc.Import"
+
+	log := p.log.With(
+		slog.String("op", op),
+		slog.Bool("dry_run", dryRun),
+	)
+
+	var desired models.Policy
+	if err := yaml.Unmarshal(document, &desired); err != nil {
+		return nil, fmt.Errorf("%s:%w: %w", op, ErrInvalidPolicy, err)
+	}
+
+	if err := validatePolicy(desired); err != nil {
+		return nil, fmt.Errorf("%s:%w: %w", op, ErrInvalidPolicy, err)
+	}
+
+	current, err := p.provider.Policy(ctx)
+	if err != nil {
+		log.Error("failed to read policy", slog.Any("err", err))
+
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	changes := diffPolicy(current, desired)
+
+	log.Info("policy diff calculated", slog.Int("changes", len(changes)))
+
+	if dryRun || len(changes) == 0 {
+		return changes, nil
+	}
+
+	if err := p.provider.ApplyPolicyChanges(ctx, changes); err != nil {
+		log.Error("failed to apply policy", slog.Any("err", err))
+
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	if p.cacheCleaner != nil {
+		if err := p.cacheCleaner.FlushPermissions(ctx); err != nil {
+			log.Warn("failed to flush permissions cache", slog.Any("err", err))
+		}
+	}
+
+	log.Info("policy applied")
+
+	return changes, nil
+}
+
+func validatePolicy(policy models.Policy) error {
+	roles := make(map[string]bool, len(policy.Roles))
+	for _, role := range policy.Roles {
+		if role.Name == "" {
+			return errors.New("role without name")
+		}
+		if roles[role.Name] {
+			return fmt.Errorf("role %q declared twice", role.Name)
+		}
+		roles[role.Name] = true
+	}
+
+	perms := make(map[string]bool, len(policy.Permissions))
+	for _, perm := range policy.Permissions {
+		if perm == "" {
+			return errors.New("empty permission name")
+		}
+		perms[perm] = true
+	}
+
+	for _, rp := range policy.RolePermissions {
+		if !roles[rp.Role] {
+			return fmt.Errorf("role_permission refers to unknown role %q", rp.Role)
+		}
+		if !perms[rp.Permission] {
+			return fmt.Errorf("role_permission refers to unknown permission %q", rp.Permission)
+		}
+		if rp.Effect != models.EffectAllow && rp.Effect != models.EffectDeny {
+			return fmt.Errorf("role_permission %s -> %s has invalid effect %q", rp.Role, rp.Permission, rp.Effect)
+		}
+	}
+
+	for _, b := range policy.Bindings {
+		if b.User == "" {
+			return errors.New("binding without user")
+		}
+		if !roles[b.Role] {
+			return fmt.Errorf("binding refers to unknown role %q", b.Role)
+		}
+	}
+
+	return nil
+}
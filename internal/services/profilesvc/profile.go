@@ -0,0 +1,274 @@
+package profilesvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"sso/internal/domain/models"
+	"sso/internal/lib/appsecret"
+	"sso/internal/storage"
+)
+
+type Profiles struct {
+	log      *slog.Logger
+	profiles ProfileStore
+	hasher   PasswordHasher
+	sessions SessionIssuer
+	mail     MailSender
+	// how long code which confirms new email is valid
+	codeTTL time.Duration
+}
+
+type ProfileStore interface {
+	Profile(ctx context.Context, userID int64) (models.Profile, error)
+	UpdateProfile(ctx context.Context, userID int64, username string, avatar string) error
+	PasswordHash(ctx context.Context, userID int64) ([]byte, error)
+	SetPassword(ctx context.Context, userID int64, hash []byte) error
+	SaveEmailChange(ctx context.Context, change models.EmailChange) error
+	EmailChange(ctx context.Context, userID int64) (models.EmailChange, error)
+	ConfirmEmailChange(ctx context.Context, userID int64, email string) error
+}
+
+type PasswordHasher interface {
+	Hash(password string) ([]byte, error)
+	Compare(hash []byte, password string) error
+}
+
+// SessionIssuer gives new tokens to user whose previous tokens were revoked
+type SessionIssuer interface {
+	SessionToken(ctx context.Context, user models.User, appID uint64, scopes []string) (string, error)
+}
+
+type MailSender interface {
+	Send(ctx context.Context, to string, subject string, body string) error
+}
+
+// New returns a new instance of profile service
+func New(
+	log *slog.Logger,
+	profiles ProfileStore,
+	hasher PasswordHasher,
+	sessions SessionIssuer,
+	mail MailSender,
+	codeTTL time.Duration,
+) *Profiles {
+	return &Profiles{
+		log:      log,
+		profiles: profiles,
+		hasher:   hasher,
+		sessions: sessions,
+		mail:     mail,
+		codeTTL:  codeTTL,
+	}
+}
+
+var (
+	ErrInvalidPassword = errors.New("invalid current password")
+	ErrSameEmail       = errors.New("new email is the current one")
+	ErrInvalidCode     = errors.New("invalid or expired email code")
+)
+
+func (p *Profiles) Profile(ctx context.Context, userID int64) (models.Profile, error) {
+	const op = "profilesvc.Profile"
+
+	profile, err := p.profiles.Profile(ctx, userID)
+	if err != nil {
+		return models.Profile{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return profile, nil
+}
+
+// Update replaces username and avatar of user and returns updated profile
+func (p *Profiles) Update(ctx context.Context, userID int64, username string, avatar string) (models.Profile, error) {
+	const op = "profilesvc.Update"
+
+	log := p.log.With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID),
+	)
+
+	if err := p.profiles.UpdateProfile(ctx, userID, username, avatar); err != nil {
+		log.Warn("failed to update profile", slog.Any("err", err))
+
+		return models.Profile{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("profile updated")
+
+	return p.Profile(ctx, userID)
+}
+
+// ChangeEmail sends code to new address, email of user is not changed until the code is confirmed.
+// Current password is required, so stolen token alone cannot move account to another address
+func (p *Profiles) ChangeEmail(ctx context.Context, userID int64, email string, password string) (time.Time, error) {
+	const op = "profilesvc.ChangeEmail"
+
+	log := p.log.With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID),
+	)
+
+	profile, err := p.profiles.Profile(ctx, userID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s:%w", op, err)
+	}
+	if profile.Email == email {
+		return time.Time{}, fmt.Errorf("%s:%w", op, ErrSameEmail)
+	}
+
+	if err := p.checkPassword(ctx, userID, password); err != nil {
+		log.Info("email change with invalid password")
+
+		return time.Time{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	code, hash, err := appsecret.Generate()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	change := models.EmailChange{
+		UserID:    userID,
+		NewEmail:  email,
+		CodeHash:  hash,
+		ExpiresAt: time.Now().Add(p.codeTTL),
+	}
+	if err := p.profiles.SaveEmailChange(ctx, change); err != nil {
+		if errors.Is(err, storage.ErrUserExists) {
+			log.Info("email is taken by another user")
+
+			return time.Time{}, fmt.Errorf("%s:%w", op, err)
+		}
+		log.Error("failed to save email change", slog.Any("err", err))
+
+		return time.Time{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	body := fmt.Sprintf(
+		"Hello, %s!\n\nUse this code to confirm your new email address:\n\n%s\n\nThe code is valid until %s. "+
+			"If you did not request the change, ignore this message.\n",
+		profile.Username, code, change.ExpiresAt.UTC().Format(time.RFC1123),
+	)
+	if err := p.mail.Send(ctx, email, "Confirm your new email address", body); err != nil {
+		log.Error("failed to send email code", slog.Any("err", err))
+
+		return time.Time{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("email change requested")
+
+	return change.ExpiresAt, nil
+}
+
+// ConfirmEmail replaces email of user with address which code was sent to
+func (p *Profiles) ConfirmEmail(ctx context.Context, userID int64, code string) (models.Profile, error) {
+	const op = "profilesvc.ConfirmEmail"
+
+	log := p.log.With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID),
+	)
+
+	change, err := p.profiles.EmailChange(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrEmailChangeMissing) {
+			return models.Profile{}, fmt.Errorf("%s:%w", op, ErrInvalidCode)
+		}
+
+		return models.Profile{}, fmt.Errorf("%s:%w", op, err)
+	}
+	if time.Now().After(change.ExpiresAt) || !appsecret.Matches([]string{change.CodeHash}, code) {
+		log.Info("invalid email code")
+
+		return models.Profile{}, fmt.Errorf("%s:%w", op, ErrInvalidCode)
+	}
+
+	if err := p.profiles.ConfirmEmailChange(ctx, userID, change.NewEmail); err != nil {
+		log.Warn("failed to change email", slog.Any("err", err))
+
+		return models.Profile{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("email changed")
+
+	return p.Profile(ctx, userID)
+}
+
+// ChangePassword saves new password and revokes every session of user. Caller gets new token
+// for the app of its session, so only other sessions have to log in again. Empty token without
+// error means password is changed, but caller has to log in again as well
+func (p *Profiles) ChangePassword(
+	ctx context.Context,
+	userID int64,
+	appID uint64,
+	scopes []string,
+	current string,
+	password string,
+) (string, error) {
+	const op = "profilesvc.ChangePassword"
+
+	log := p.log.With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID),
+	)
+
+	if err := p.checkPassword(ctx, userID, current); err != nil {
+		log.Info("password change with invalid current password")
+
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+
+	hash, err := p.hasher.Hash(password)
+	if err != nil {
+		log.Error("failed to hash password", slog.Any("err", err))
+
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+
+	if err := p.profiles.SetPassword(ctx, userID, hash); err != nil {
+		log.Error("failed to save password", slog.Any("err", err))
+
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("password changed, sessions revoked")
+
+	// password is already changed, so failures below do not fail the change itself
+	profile, err := p.profiles.Profile(ctx, userID)
+	if err != nil {
+		log.Warn("failed to get profile for new token", slog.Any("err", err))
+
+		return "", nil
+	}
+
+	token, err := p.sessions.SessionToken(ctx, models.User{
+		ID:       int(profile.UserID),
+		TenantID: profile.TenantID,
+		Username: profile.Username,
+		Email:    profile.Email,
+	}, appID, scopes)
+	if err != nil {
+		log.Warn("failed to issue new token", slog.Any("err", err))
+
+		return "", nil
+	}
+
+	return token, nil
+}
+
+func (p *Profiles) checkPassword(ctx context.Context, userID int64, password string) error {
+	hash, err := p.profiles.PasswordHash(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := p.hasher.Compare(hash, password); err != nil {
+		return ErrInvalidPassword
+	}
+
+	return nil
+}
@@ -5,68 +5,147 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
+	"sso/internal/domain/models"
 	"sso/internal/dto"
 	"sso/internal/storage"
 	"time"
-
-	"github.com/opentracing/opentracing-go"
 )
 
 type Status struct {
 	log        *slog.Logger
 	userStatus UserStatus
+	banManager BanManager
 }
 
 type UserStatus interface {
 	IsUsrBanned(ctx context.Context, userID int64) (*dto.IsBannedRespStruct, error)
 	LastUsrLogin(ctx context.Context, userID int64) (time.Time, error)
 	CurrentUsrRole(ctx context.Context, userID int64) (*dto.CurrentRoleRespStruct, error)
+	LoginHistory(ctx context.Context, filter dto.LoginHistoryFilter) ([]models.LoginAttempt, error)
+	UserRoles(ctx context.Context, userID int64) ([]dto.UserRole, error)
+}
+
+type BanManager interface {
+	BanUsr(ctx context.Context, ban models.Ban) (banID int64, err error)
+	UnbanUsr(ctx context.Context, userID int64, unbannedBy int64, reason string) error
 }
 
 func New(
 	log *slog.Logger,
 	userStatus UserStatus,
+	banManager BanManager,
 ) *Status {
 	return &Status{
 		log:        log,
 		userStatus: userStatus,
+		banManager: banManager,
 	}
 }
 
 var zeroTime time.Time
 
-func (s *Status) CheckIsUserBanned(ctx context.Context, userID int64) (bool, error) {
-	const op = "statsvc.CheckIsUserBanned"
+var (
+	ErrBanExpired = errors.New("ban expiry is in the past")
+)
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
+const (
+	DefaultHistoryPageSize = 20
+	MaxHistoryPageSize     = 100
+)
+
+const (
+	accountActive = "account is active"
+	accountBanned = "account is banned"
+)
+
+func (s *Status) IsBanned(ctx context.Context, userID int64) (*dto.IsBannedRespStruct, error) {
+	const op = "statsvc.IsBanned"
 
 	log := s.log.With(
 		slog.String("op", op),
 		slog.Int64("checking if user had banned", userID),
 	)
 
-	IsBanned, err := s.userStatus.IsUsrBanned(ctx, int64(userID))
+	isBanned, err := s.userStatus.IsUsrBanned(ctx, userID)
 	if err != nil {
-		if errors.Is(err, storage.ErrAppNotFound) {
-			log.Warn("error happend", slog.Any("%w", err))
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Warn("user not found", slog.Any("err", err))
 
-			return false, fmt.Errorf("invalid app id %w", storage.ErrAppNotFound)
+			return nil, fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
 		}
 		log.Warn("error", slog.Any("err", err))
 
-		return false, fmt.Errorf("%s:%w", op, storage.ErrAppNotFound)
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	isBanned.Message = accountActive
+	if isBanned.IsBanned {
+		isBanned.Message = accountBanned
 	}
 
-	return IsBanned.IsBanned, nil
+	return isBanned, nil
+}
+
+// BanUser bans user, revokes his sessions and returns id of the ban record
+func (s *Status) BanUser(ctx context.Context, ban models.Ban) (int64, error) {
+	const op = "statsvc.BanUser"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int64("user_id", ban.UserID),
+		slog.Int64("banned_by", ban.BannedBy),
+	)
+
+	if !ban.ExpiresAt.IsZero() && ban.ExpiresAt.Before(time.Now()) {
+		return 0, fmt.Errorf("%s:%w", op, ErrBanExpired)
+	}
+
+	banID, err := s.banManager.BanUsr(ctx, ban)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Warn("user not found", slog.Any("err", err))
+
+			return 0, fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
+		}
+		log.Error("failed to ban user", slog.Any("err", err))
+
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("user banned", slog.Int64("ban_id", banID), slog.Time("expires_at", ban.ExpiresAt))
+
+	return banID, nil
+}
+
+func (s *Status) UnbanUser(ctx context.Context, userID int64, unbannedBy int64, reason string) error {
+	const op = "statsvc.UnbanUser"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID),
+		slog.Int64("unbanned_by", unbannedBy),
+	)
+
+	if err := s.banManager.UnbanUsr(ctx, userID, unbannedBy, reason); err != nil {
+		if errors.Is(err, storage.ErrUserNotBanned) {
+			log.Warn("user is not banned")
+
+			return fmt.Errorf("%s:%w", op, storage.ErrUserNotBanned)
+		}
+		log.Error("failed to unban user", slog.Any("err", err))
+
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	log.Info("user unbanned")
+
+	return nil
 }
 
 func (s *Status) LastLogin(ctx context.Context, userID int64) (time.Time, error) {
 	const op = "statsvc.LastLogin"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
 	log := s.log.With(
 		slog.String("op", op),
 		slog.Int64("getting last login of user", userID),
@@ -90,9 +169,6 @@ func (s *Status) LastLogin(ctx context.Context, userID int64) (time.Time, error)
 func (s *Status) CurrentUserRole(ctx context.Context, userID int64) (*dto.CurrentRoleRespStruct, error) {
 	const op = "statsvc.CurrentUserRole"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
 	log := s.log.With(
 		slog.String("op", op),
 		slog.Int64("getting user role of user", userID),
@@ -103,11 +179,11 @@ func (s *Status) CurrentUserRole(ctx context.Context, userID int64) (*dto.Curren
 		if errors.Is(err, storage.ErrUserNotFound) {
 			log.Warn("user doesnt exist", slog.Any("err", err))
 
-			return nil, fmt.Errorf("%s:%w", op, storage.ErrDoesntAllowed)
+			return nil, fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
 		}
 		log.Warn("cannot get user", slog.Any("err", err))
 
-		return nil, fmt.Errorf("%s:%w", op, storage.ErrAppNotFound)
+		return nil, fmt.Errorf("%s:%w", op, err)
 	}
 
 	return &dto.CurrentRoleRespStruct{
@@ -116,3 +192,146 @@ func (s *Status) CurrentUserRole(ctx context.Context, userID int64) (*dto.Curren
 	}, nil
 
 }
+
+// LoginHistory returns page of login attempts and id to pass as BeforeID for the next page,
+// next id is zero on the last page
+func (s *Status) LoginHistory(ctx context.Context, filter dto.LoginHistoryFilter) ([]models.LoginAttempt, int64, error) {
+	const op = "statsvc.LoginHistory"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int64("getting login history of user", filter.UserID),
+	)
+
+	pageSize := filter.PageSize
+	switch {
+	case pageSize <= 0:
+		pageSize = DefaultHistoryPageSize
+	case pageSize > MaxHistoryPageSize:
+		pageSize = MaxHistoryPageSize
+	}
+
+	// one extra row tells if there is a next page
+	filter.PageSize = pageSize + 1
+
+	attempts, err := s.userStatus.LoginHistory(ctx, filter)
+	if err != nil {
+		log.Warn("error while getting login history", slog.Any("err", err))
+
+		return nil, 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	var next int64
+	if len(attempts) > pageSize {
+		attempts = attempts[:pageSize]
+		next = attempts[pageSize-1].ID
+	}
+
+	return attempts, next, nil
+}
+
+// Roles returns every role of user grouped by app.
+//
+// Roles bound in every app are returned in group with app id 0 and are also
+// taken into account in effective permissions of other apps
+func (s *Status) Roles(ctx context.Context, userID int64) ([]dto.AppRolesStruct, error) {
+	const op = "statsvc.Roles"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int64("getting roles of user", userID),
+	)
+
+	roles, err := s.userStatus.UserRoles(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Warn("user doesnt exist", slog.Any("err", err))
+
+			return nil, fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
+		}
+		log.Warn("cannot get user roles", slog.Any("err", err))
+
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	var (
+		apps   []dto.AppRolesStruct
+		global []dto.UserRole
+	)
+	for _, role := range roles {
+		if role.AppID == models.AllApps {
+			global = append(global, role)
+		}
+
+		if len(apps) == 0 || apps[len(apps)-1].AppID != role.AppID {
+			apps = append(apps, dto.AppRolesStruct{AppID: role.AppID})
+		}
+		apps[len(apps)-1].Roles = append(apps[len(apps)-1].Roles, role)
+	}
+
+	for i := range apps {
+		appRoles := apps[i].Roles
+		if apps[i].AppID != models.AllApps {
+			appRoles = append(appRoles, global...)
+		}
+		apps[i].EffectivePermissions, apps[i].ExcludedPermissions = effectivePermissions(appRoles)
+	}
+
+	return apps, nil
+}
+
+// effectivePermissions returns allowed permission names, which are not overridden by any deny,
+// and denies which narrow wildcards among them, so together they give what permission checks decide
+func effectivePermissions(roles []dto.UserRole) (effective []string, excluded []string) {
+	var denies []models.PermissionRule
+	for _, role := range roles {
+		for _, name := range role.DeniedPermissions {
+			denies = append(denies, models.PermissionRule{Name: name, Effect: models.EffectDeny})
+		}
+	}
+
+	seen := make(map[string]bool)
+	effective = []string{}
+	var allows []models.PermissionRule
+
+	for _, role := range roles {
+	next:
+		for _, name := range role.Permissions {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			for _, deny := range denies {
+				if deny.Covers(name) {
+					continue next
+				}
+			}
+
+			effective = append(effective, name)
+			allows = append(allows, models.PermissionRule{Name: name, Effect: models.EffectAllow})
+		}
+	}
+
+	excluded = []string{}
+	seen = make(map[string]bool)
+	for _, deny := range denies {
+		if seen[deny.Name] {
+			continue
+		}
+		seen[deny.Name] = true
+
+		for _, allow := range allows {
+			if allow.Covers(deny.Name) {
+				excluded = append(excluded, deny.Name)
+
+				break
+			}
+		}
+	}
+
+	sort.Strings(effective)
+	sort.Strings(excluded)
+
+	return effective, excluded
+}
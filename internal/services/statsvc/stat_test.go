@@ -0,0 +1,108 @@
+package statsvc
+
+import (
+	"reflect"
+	"testing"
+
+	"sso/internal/dto"
+)
+
+func TestEffectivePermissions(t *testing.T) {
+	tests := []struct {
+		name         string
+		roles        []dto.UserRole
+		wantEffect   []string
+		wantExcluded []string
+	}{
+		{
+			name:         "no roles",
+			wantEffect:   []string{},
+			wantExcluded: []string{},
+		},
+		{
+			name: "allows of several roles are merged",
+			roles: []dto.UserRole{
+				{Role: "viewer", Permissions: []string{"video:watch", "video:list"}},
+				{Role: "uploader", Permissions: []string{"video:upload", "video:watch"}},
+			},
+			wantEffect:   []string{"video:list", "video:upload", "video:watch"},
+			wantExcluded: []string{},
+		},
+		{
+			name: "exact deny removes allow of another role",
+			roles: []dto.UserRole{
+				{Role: "viewer", Permissions: []string{"video:watch", "video:download"}},
+				{Role: "restricted", DeniedPermissions: []string{"video:download"}},
+			},
+			wantEffect:   []string{"video:watch"},
+			wantExcluded: []string{},
+		},
+		{
+			name: "wildcard deny removes family",
+			roles: []dto.UserRole{
+				{Role: "editor", Permissions: []string{"video:upload", "video:*", "audio:upload"}},
+				{Role: "restricted", DeniedPermissions: []string{"video:*"}},
+			},
+			wantEffect:   []string{"audio:upload"},
+			wantExcluded: []string{},
+		},
+		{
+			name: "deny inside wildcard allow is reported as exception",
+			roles: []dto.UserRole{
+				{Role: "editor", Permissions: []string{"video:*"}},
+				{Role: "restricted", DeniedPermissions: []string{"video:download"}},
+			},
+			wantEffect:   []string{"video:*"},
+			wantExcluded: []string{"video:download"},
+		},
+		{
+			name: "family deny inside global wildcard",
+			roles: []dto.UserRole{
+				{Role: "owner", Permissions: []string{"*"}},
+				{Role: "restricted", DeniedPermissions: []string{"billing:*", "billing:refund"}},
+			},
+			wantEffect:   []string{"*"},
+			wantExcluded: []string{"billing:*", "billing:refund"},
+		},
+		{
+			name: "global deny wins over everything",
+			roles: []dto.UserRole{
+				{Role: "owner", Permissions: []string{"*", "video:watch"}},
+				{Role: "blocked", DeniedPermissions: []string{"*"}},
+			},
+			wantEffect:   []string{},
+			wantExcluded: []string{},
+		},
+		{
+			name: "deny outside of allows is not reported",
+			roles: []dto.UserRole{
+				{Role: "editor", Permissions: []string{"video:*"}},
+				{Role: "restricted", DeniedPermissions: []string{"audio:download", "audio:download"}},
+			},
+			wantEffect:   []string{"video:*"},
+			wantExcluded: []string{},
+		},
+		{
+			name: "repeated deny is reported once",
+			roles: []dto.UserRole{
+				{Role: "editor", Permissions: []string{"video:*"}, DeniedPermissions: []string{"video:download"}},
+				{Role: "restricted", DeniedPermissions: []string{"video:download"}},
+			},
+			wantEffect:   []string{"video:*"},
+			wantExcluded: []string{"video:download"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			effective, excluded := effectivePermissions(tt.roles)
+
+			if !reflect.DeepEqual(effective, tt.wantEffect) {
+				t.Errorf("effective = %v, want %v", effective, tt.wantEffect)
+			}
+			if !reflect.DeepEqual(excluded, tt.wantExcluded) {
+				t.Errorf("excluded = %v, want %v", excluded, tt.wantExcluded)
+			}
+		})
+	}
+}
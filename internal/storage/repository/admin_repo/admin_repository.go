@@ -0,0 +1,142 @@
+package adminrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sso/internal/lib/tenant"
+	"sso/internal/storage"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AdminRepository keeps operations used to bootstrap environment, users, roles and admins of tenant of context
+type AdminRepository struct {
+	db *pgxpool.Pool
+}
+
+func New(db *pgxpool.Pool) *AdminRepository {
+	return &AdminRepository{
+		db: db,
+	}
+}
+
+// UserInfo is a row of users list
+type UserInfo struct {
+	ID        int64
+	Username  string
+	Email     string
+	IsAdmin   bool
+	CreatedAt time.Time
+}
+
+func (a *AdminRepository) Users(ctx context.Context) ([]UserInfo, error) {
+	const op = "admin_repository.Users"
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	rows, err := a.db.Query(ctx, selectUsers, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	var (
+		users []UserInfo
+		user  UserInfo
+	)
+	_, err = pgx.ForEachRow(rows, []any{&user.ID, &user.Username, &user.Email, &user.IsAdmin, &user.CreatedAt}, func() error {
+		users = append(users, user)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return users, nil
+}
+
+// SetPassword replaces password hash of user with given email
+func (a *AdminRepository) SetPassword(ctx context.Context, email string, hashedpassw []byte) error {
+	const op = "admin_repository.SetPassword"
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	tag, err := a.db.Exec(ctx, updatePassword, email, hashedpassw, tenantID)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
+	}
+
+	return nil
+}
+
+// GrantRole binds role to user in given app, app 0 means every app
+func (a *AdminRepository) GrantRole(ctx context.Context, email string, role string, appID int64) error {
+	const op = "admin_repository.GrantRole"
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	err = pgx.BeginFunc(ctx, a.db, func(tx pgx.Tx) error {
+		var userID, roleID int64
+
+		if err := tx.QueryRow(ctx, selectUserID, email, tenantID).Scan(&userID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return storage.ErrUserNotFound
+			}
+			return err
+		}
+
+		if err := tx.QueryRow(ctx, selectRoleID, role, tenantID).Scan(&roleID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return storage.ErrRoleNotFound
+			}
+			return err
+		}
+
+		_, err := tx.Exec(ctx, insertUserRole, userID, roleID, appID)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+// SetAdmin marks or unmarks user as admin
+func (a *AdminRepository) SetAdmin(ctx context.Context, email string, isAdmin bool) error {
+	const op = "admin_repository.SetAdmin"
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	tag, err := a.db.Exec(ctx, upsertAdmin, email, isAdmin, tenantID)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
+	}
+
+	return nil
+}
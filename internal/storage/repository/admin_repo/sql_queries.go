@@ -0,0 +1,36 @@
+package adminrepo
+
+const (
+	selectUsers = `
+	SELECT u.user_id, u.username, u.email, COALESCE(ia.is_admin, FALSE), u.created_at
+	FROM users u
+	LEFT JOIN is_admin ia ON u.user_id = ia.user_id
+	WHERE u.tenant_id = $1
+	ORDER BY u.user_id
+	`
+
+	updatePassword = `
+	UPDATE users SET hashedpassw = $2
+	WHERE tenant_id = $3 AND email = $1
+	`
+
+	selectUserID = `
+	SELECT user_id FROM users WHERE tenant_id = $2 AND email = $1
+	`
+
+	selectRoleID = `
+	SELECT role_id FROM roles WHERE tenant_id = $2 AND role_name = $1
+	`
+
+	insertUserRole = `
+	INSERT INTO user_roles(user_id, role_id, app_id)
+	VALUES ($1, $2, $3)
+	ON CONFLICT DO NOTHING
+	`
+
+	upsertAdmin = `
+	INSERT INTO is_admin(user_id, is_admin)
+	SELECT user_id, $2 FROM users WHERE tenant_id = $3 AND email = $1
+	ON CONFLICT (user_id) DO UPDATE SET is_admin = EXCLUDED.is_admin
+	`
+)
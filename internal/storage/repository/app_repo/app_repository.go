@@ -0,0 +1,305 @@
+package apprepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sso/internal/domain/models"
+	"sso/internal/lib/tenant"
+	"sso/internal/storage"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AppRepository struct {
+	db *pgxpool.Pool
+}
+
+// New creates repository on top of shared connection pool
+func New(db *pgxpool.Pool) *AppRepository {
+	return &AppRepository{
+		db: db,
+	}
+}
+
+// CreateApp saves app of tenant of context with its token settings and first secret in one transaction,
+// zero app.ID means next free id
+func (a *AppRepository) CreateApp(ctx context.Context, app models.App, secretHash string) (models.App, error) {
+	const op = "app_repository.CreateApp"
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return models.App{}, fmt.Errorf("%s:%w", op, err)
+	}
+	app.TenantID = tenantID
+
+	err = pgx.BeginFunc(ctx, a.db, func(tx pgx.Tx) error {
+		explicitID := app.ID != 0
+
+		err := tx.QueryRow(ctx, insertApp,
+			app.ID, app.Name, app.Description, app.LogoURL, nonNil(app.RedirectURIs), nonNil(app.AllowedOrigins),
+			app.Tokens.AccessTTL, app.Tokens.RefreshTTL, app.Tokens.IdleTimeout, nonNil(app.Tokens.GrantTypes),
+			app.Tokens.Audience, app.Tokens.SigningKeyID, tenantID,
+		).Scan(&app.ID, &app.CreatedAt, &app.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		if explicitID {
+			if _, err := tx.Exec(ctx, advanceAppSequence, app.ID); err != nil {
+				return err
+			}
+		}
+
+		secret := models.AppSecret{Hash: secretHash}
+		if err := tx.QueryRow(ctx, insertAppSecret, app.ID, secretHash).Scan(&secret.ID, &secret.CreatedAt); err != nil {
+			return err
+		}
+		app.Secrets = []models.AppSecret{secret}
+
+		return nil
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+
+			return models.App{}, fmt.Errorf("%s:%w", op, storage.ErrAppExists)
+		}
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+
+			return models.App{}, fmt.Errorf("%s:%w", op, storage.ErrKeyNotFound)
+		}
+
+		return models.App{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return app, nil
+}
+
+// App returns app with its valid secrets
+func (a *AppRepository) App(ctx context.Context, appID int) (models.App, error) {
+	const op = "app_repository.App"
+
+	rows, err := a.db.Query(ctx, selectApp, appID)
+	if err != nil {
+		return models.App{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	app, err := pgx.CollectExactlyOneRow(rows, scanApp)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.App{}, fmt.Errorf("%s:%w", op, storage.ErrAppNotFound)
+		}
+
+		return models.App{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	app.Secrets, err = a.secrets(ctx, appID)
+	if err != nil {
+		return models.App{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return app, nil
+}
+
+// Apps returns every app of tenant of context without secrets
+func (a *AppRepository) Apps(ctx context.Context) ([]models.App, error) {
+	const op = "app_repository.Apps"
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	rows, err := a.db.Query(ctx, selectApps, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	apps, err := pgx.CollectRows(rows, scanApp)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return apps, nil
+}
+
+// UpdateApp replaces registration data and token settings of app, secrets and state are not touched
+func (a *AppRepository) UpdateApp(ctx context.Context, app models.App) error {
+	const op = "app_repository.UpdateApp"
+
+	tag, err := a.db.Exec(ctx, updateApp,
+		app.ID, app.Name, app.Description, app.LogoURL, nonNil(app.RedirectURIs), nonNil(app.AllowedOrigins),
+		app.Tokens.AccessTTL, app.Tokens.RefreshTTL, app.Tokens.IdleTimeout, nonNil(app.Tokens.GrantTypes),
+		app.Tokens.Audience, app.Tokens.SigningKeyID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+
+			return fmt.Errorf("%s:%w", op, storage.ErrAppExists)
+		}
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+
+			return fmt.Errorf("%s:%w", op, storage.ErrKeyNotFound)
+		}
+
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s:%w", op, storage.ErrAppNotFound)
+	}
+
+	return nil
+}
+
+// SetAppTokens replaces token settings of app, zero values reset them to defaults of service
+func (a *AppRepository) SetAppTokens(ctx context.Context, appID int, cfg models.AppTokenConfig) error {
+	const op = "app_repository.SetAppTokens"
+
+	tag, err := a.db.Exec(ctx, updateAppTokens,
+		appID, cfg.AccessTTL, cfg.RefreshTTL, cfg.IdleTimeout, nonNil(cfg.GrantTypes), cfg.Audience, cfg.SigningKeyID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+
+			return fmt.Errorf("%s:%w", op, storage.ErrKeyNotFound)
+		}
+
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s:%w", op, storage.ErrAppNotFound)
+	}
+
+	return nil
+}
+
+func (a *AppRepository) SetDisabled(ctx context.Context, appID int, disabled bool) error {
+	const op = "app_repository.SetDisabled"
+
+	tag, err := a.db.Exec(ctx, updateAppDisabled, appID, disabled)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s:%w", op, storage.ErrAppNotFound)
+	}
+
+	return nil
+}
+
+// DeleteApp removes app with its secrets, role bindings and refresh tokens,
+// so app which later gets the same id does not inherit them
+func (a *AppRepository) DeleteApp(ctx context.Context, appID int) error {
+	const op = "app_repository.DeleteApp"
+
+	err := pgx.BeginFunc(ctx, a.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, deleteAppRoles, appID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, deleteAppRefreshTokens, appID); err != nil {
+			return err
+		}
+
+		tag, err := tx.Exec(ctx, deleteApp, appID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return storage.ErrAppNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+// RotateSecret saves new secret, current secret stays valid for overlap
+func (a *AppRepository) RotateSecret(ctx context.Context, appID int, secretHash string, overlap time.Duration) (models.AppSecret, error) {
+	const op = "app_repository.RotateSecret"
+
+	secret := models.AppSecret{Hash: secretHash}
+
+	err := pgx.BeginFunc(ctx, a.db, func(tx pgx.Tx) error {
+		// parallel rotations of one app are serialized, otherwise both could keep their secret
+		var id int
+		if err := tx.QueryRow(ctx, lockApp, appID).Scan(&id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return storage.ErrAppNotFound
+			}
+
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, deleteExpiringSecrets, appID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, expireCurrentSecrets, appID, overlap); err != nil {
+			return err
+		}
+
+		return tx.QueryRow(ctx, insertAppSecret, appID, secretHash).Scan(&secret.ID, &secret.CreatedAt)
+	})
+	if err != nil {
+		return models.AppSecret{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return secret, nil
+}
+
+func (a *AppRepository) secrets(ctx context.Context, appID int) ([]models.AppSecret, error) {
+	rows, err := a.db.Query(ctx, selectAppSecrets, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		secrets   []models.AppSecret
+		secret    models.AppSecret
+		expiresAt *time.Time
+	)
+	_, err = pgx.ForEachRow(rows, []any{&secret.ID, &secret.Hash, &secret.CreatedAt, &expiresAt}, func() error {
+		secret.ExpiresAt = time.Time{}
+		if expiresAt != nil {
+			secret.ExpiresAt = *expiresAt
+		}
+		secrets = append(secrets, secret)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+func scanApp(row pgx.CollectableRow) (models.App, error) {
+	var app models.App
+
+	err := row.Scan(
+		&app.ID, &app.TenantID, &app.Name, &app.Description, &app.LogoURL, &app.RedirectURIs, &app.AllowedOrigins, &app.Disabled,
+		&app.Tokens.AccessTTL, &app.Tokens.RefreshTTL, &app.Tokens.IdleTimeout,
+		&app.Tokens.GrantTypes, &app.Tokens.Audience, &app.Tokens.SigningKeyID,
+		&app.CreatedAt, &app.UpdatedAt,
+	)
+
+	return app, err
+}
+
+// nonNil keeps NOT NULL array columns from nil slices, pgx encodes them as NULL
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+
+	return list
+}
@@ -0,0 +1,123 @@
+package apprepo
+
+const (
+	appColumns = `
+	app_id, tenant_id, name, description, logo_url, redirect_uris, allowed_origins, disabled,
+	COALESCE(access_ttl, INTERVAL '0'), COALESCE(refresh_ttl, INTERVAL '0'), COALESCE(idle_timeout, INTERVAL '0'),
+	grant_types, COALESCE(audience, ''), COALESCE(signing_kid, ''), created_at, updated_at
+	`
+
+	selectApp = `
+	SELECT` + appColumns + `
+	FROM apps
+	WHERE app_id = $1
+	`
+
+	selectApps = `
+	SELECT` + appColumns + `
+	FROM apps
+	WHERE tenant_id = $1
+	ORDER BY app_id
+	`
+
+	insertApp = `
+	INSERT INTO apps(
+	app_id,
+	tenant_id,
+	name,
+	description,
+	logo_url,
+	redirect_uris,
+	allowed_origins,
+	access_ttl,
+	refresh_ttl,
+	idle_timeout,
+	grant_types,
+	audience,
+	signing_kid) VALUES (
+	COALESCE(NULLIF($1, 0), nextval('apps_app_id_seq')), $13, $2, $3, $4, $5, $6,
+	NULLIF($7::interval, INTERVAL '0'), NULLIF($8::interval, INTERVAL '0'), NULLIF($9::interval, INTERVAL '0'),
+	$10, NULLIF($11, ''), NULLIF($12, '')
+	)
+	RETURNING app_id, created_at, updated_at
+	`
+
+	// apps created with explicit id move sequence past it, so generated ids do not collide with them
+	advanceAppSequence = `
+	SELECT setval('apps_app_id_seq', GREATEST($1, (SELECT last_value FROM apps_app_id_seq)))
+	`
+
+	updateApp = `
+	UPDATE apps SET
+	name = $2,
+	description = $3,
+	logo_url = $4,
+	redirect_uris = $5,
+	allowed_origins = $6,
+	access_ttl = NULLIF($7::interval, INTERVAL '0'),
+	refresh_ttl = NULLIF($8::interval, INTERVAL '0'),
+	idle_timeout = NULLIF($9::interval, INTERVAL '0'),
+	grant_types = $10,
+	audience = NULLIF($11, ''),
+	signing_kid = NULLIF($12, ''),
+	updated_at = NOW()
+	WHERE app_id = $1
+	`
+
+	updateAppTokens = `
+	UPDATE apps SET
+	access_ttl = NULLIF($2::interval, INTERVAL '0'),
+	refresh_ttl = NULLIF($3::interval, INTERVAL '0'),
+	idle_timeout = NULLIF($4::interval, INTERVAL '0'),
+	grant_types = $5,
+	audience = NULLIF($6, ''),
+	signing_kid = NULLIF($7, ''),
+	updated_at = NOW()
+	WHERE app_id = $1
+	`
+
+	updateAppDisabled = `
+	UPDATE apps SET disabled = $2, updated_at = NOW()
+	WHERE app_id = $1
+	`
+
+	lockApp = `
+	SELECT app_id FROM apps WHERE app_id = $1 FOR UPDATE
+	`
+
+	deleteAppRoles = `
+	DELETE FROM user_roles WHERE app_id = $1
+	`
+
+	deleteAppRefreshTokens = `
+	DELETE FROM refresh_tokens WHERE app_id = $1
+	`
+
+	deleteApp = `
+	DELETE FROM apps WHERE app_id = $1
+	`
+
+	selectAppSecrets = `
+	SELECT id, secret_hash, created_at, expires_at
+	FROM app_secrets
+	WHERE app_id = $1 AND (expires_at IS NULL OR expires_at > NOW())
+	ORDER BY id
+	`
+
+	insertAppSecret = `
+	INSERT INTO app_secrets(app_id, secret_hash)
+	VALUES ($1, $2)
+	RETURNING id, created_at
+	`
+
+	// secrets which are already in overlap window are dropped, so at most two secrets are valid
+	deleteExpiringSecrets = `
+	DELETE FROM app_secrets
+	WHERE app_id = $1 AND expires_at IS NOT NULL
+	`
+
+	expireCurrentSecrets = `
+	UPDATE app_secrets SET expires_at = NOW() + $2::interval
+	WHERE app_id = $1 AND expires_at IS NULL
+	`
+)
@@ -7,7 +7,6 @@ import (
 	"log/slog"
 	"sso/internal/domain/models"
 
-	"github.com/opentracing/opentracing-go"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -24,9 +23,6 @@ func NewAuthRedisRepo(redisClient *redis.Client, log *slog.Logger) *authRedisRep
 func (a *authRedisRepository) IsAdmCache(ctx context.Context, key string) (*models.IsAdmin, error) {
 	const op = "au_repository.redis_auth_repo.IsAdmCache"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
 	authBytes, err := a.redisClient.Get(ctx, key).Bytes()
 	if err != nil {
 		if err == redis.Nil {
@@ -3,17 +3,27 @@ package userrepository
 const (
 	createUserQuery = `
 	INSERT INTO users(
+    tenant_id,
     username,
     email,
     hashedpassw,
     avatar) VALUES (
-	$1, $2, $3, COALESCE(NULLIF($4, ''), null)
-	) RETURNING user_id, created_at, last_login`
+	$5, $1, $2, $3, NULLIF($4, '')
+	) RETURNING user_id`
+
+	userExistsQuery = `
+	SELECT EXISTS(SELECT 1 FROM users WHERE tenant_id = $3 AND (username = $1 OR email = $2))
+	`
 
 	selectUserQuery = `
-	SELECT user_id, email, hashedpassw, role
-	FROM users
-	WHERE email = $1
+	SELECT u.user_id, u.tenant_id, u.username, u.email, u.hashedpassw,
+	u.account_locked OR EXISTS(
+		SELECT 1 FROM user_bans b
+		WHERE b.user_id = u.user_id AND b.unbanned_at IS NULL
+		AND (b.expires_at IS NULL OR b.expires_at > NOW())
+	) AS account_locked
+	FROM users u
+	WHERE u.tenant_id = $2 AND u.email = $1
 	`
 
 	selectIsUserAdmin = `
@@ -25,7 +35,13 @@ const (
 	`
 
 	appSelectQuery = `
-	SELECT app_id, name
+	SELECT app_id, tenant_id, name, disabled,
+	ARRAY(
+		SELECT secret_hash FROM app_secrets s
+		WHERE s.app_id = apps.app_id AND (s.expires_at IS NULL OR s.expires_at > NOW())
+	),
+	COALESCE(access_ttl, INTERVAL '0'), COALESCE(refresh_ttl, INTERVAL '0'), COALESCE(idle_timeout, INTERVAL '0'),
+	grant_types, COALESCE(audience, ''), COALESCE(signing_kid, '')
 	FROM apps
 	WHERE app_id = $1
 	`
@@ -34,10 +50,28 @@ const (
 	INSERT INTO refresh_tokens(
 	token,
 	user_id,
-	expires_at) VALUES (
-	$1, $2, $3
+	app_id,
+	expires_at,
+	idle_expires_at) VALUES (
+	$1, $2, NULLIF($3, 0), NOW() + $4::interval, NOW() + NULLIF($5::interval, INTERVAL '0')
 	)
+	`
+
+	insertLoginAttempt = `
+	INSERT INTO login_history(
+	user_id,
+	email,
+	app_id,
+	success,
+	reason,
+	ip,
+	user_agent) VALUES (
+	NULLIF($1, 0), $2, NULLIF($3, 0), $4, $5, NULLIF($6, ''), NULLIF($7, '')
 	)
 	`
-	//TODO: imlement user repository, add more queries, update protobuf, solve problem with migrator.go
+
+	updateLastLogin = `
+	UPDATE users SET last_login = NOW()
+	WHERE user_id = $1
+	`
 )
@@ -8,40 +8,29 @@ import (
 	"time"
 
 	"sso/internal/domain/models"
+	"sso/internal/lib/tenant"
 	"sso/internal/storage"
 
 	"github.com/jackc/pgconn"
 	_ "github.com/jackc/pgx/stdlib"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/opentracing/opentracing-go"
 )
 
 type UserRepository struct {
 	db *pgxpool.Pool
 }
 
-func New(dsn string) (*UserRepository, error) {
-	const op = "internal.storage.postgres.auth_storage.au_repository"
-
-	db, err := pgxpool.New(context.Background(), dsn)
-	if err != nil {
-		return nil, fmt.Errorf("%s:%w", op, err)
-	}
-	if db == nil {
-		return nil, fmt.Errorf("%s:%w", op, err)
-	}
-
+// New creates repository on top of shared connection pool
+func New(db *pgxpool.Pool) *UserRepository {
 	return &UserRepository{
 		db: db,
-	}, nil
+	}
 }
 
 func (u *UserRepository) GetConn(ctx context.Context) (*pgxpool.Conn, error) {
 	const op = "au_repository.SaveUser"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
 	conn, err := u.db.Acquire(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("%s:%w", op, err)
@@ -54,11 +43,14 @@ const (
 	zeroIntValue = 0
 )
 
+// SaveUser creates user in tenant of context, username and email are unique inside tenant
 func (u *UserRepository) SaveUser(ctx context.Context, username string, email string, hashedpassw []byte) (int64, error) {
 	const op = "au_repository.SaveUser"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return zeroIntValue, fmt.Errorf("%s:%w", op, err)
+	}
 
 	conn, err := u.GetConn(ctx)
 	if err != nil {
@@ -67,7 +59,7 @@ func (u *UserRepository) SaveUser(ctx context.Context, username string, email st
 	defer conn.Release()
 
 	var exist bool
-	err = conn.QueryRow(ctx, selectUserQuery, username, email).Scan(&exist)
+	err = conn.QueryRow(ctx, userExistsQuery, username, email, tenantID).Scan(&exist)
 	if err != nil {
 		return 0, fmt.Errorf("%s: failed to check database", op)
 	}
@@ -76,7 +68,7 @@ func (u *UserRepository) SaveUser(ctx context.Context, username string, email st
 	}
 
 	var id int64
-	err = conn.QueryRow(ctx, createUserQuery, username, email, hashedpassw).Scan(&id)
+	err = conn.QueryRow(ctx, createUserQuery, username, email, hashedpassw, "", tenantID).Scan(&id)
 	if err != nil {
 		if pgerr, ok := err.(*pgconn.PgError); ok && pgerr.Code == "23505" {
 			return 0, fmt.Errorf("%s:%w", op, storage.ErrUserExists)
@@ -87,11 +79,14 @@ func (u *UserRepository) SaveUser(ctx context.Context, username string, email st
 	return id, nil
 }
 
+// User returns user with email in tenant of context
 func (u *UserRepository) User(ctx context.Context, email string) (models.User, error) {
 	const op = "au_repository.User"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return models.User{}, fmt.Errorf("%s:%w", op, err)
+	}
 
 	conn, err := u.GetConn(ctx)
 	if err != nil {
@@ -99,10 +94,10 @@ func (u *UserRepository) User(ctx context.Context, email string) (models.User, e
 	}
 	defer conn.Release()
 
-	row := conn.QueryRow(ctx, selectUserQuery, email)
+	row := conn.QueryRow(ctx, selectUserQuery, email, tenantID)
 
 	var user models.User
-	err = row.Scan(&user.ID, &user.Email, &user.HashedPass, &user.Role)
+	err = row.Scan(&user.ID, &user.TenantID, &user.Username, &user.Email, &user.HashedPass, &user.Account_locked)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.User{}, fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
@@ -117,9 +112,6 @@ func (u *UserRepository) User(ctx context.Context, email string) (models.User, e
 func (u *UserRepository) IsUsrAdmin(ctx context.Context, user_id int64) (bool, error) {
 	const op = "userrepository.IsAdmin"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
 	conn, err := u.GetConn(ctx)
 	if err != nil {
 		return false, fmt.Errorf("%s:%w", op, err)
@@ -140,13 +132,11 @@ func (u *UserRepository) IsUsrAdmin(ctx context.Context, user_id int64) (bool, e
 	return is_admin, nil
 }
 
+// App resolves app of request in any tenant, tenant of request is not known until app is found
 func (u *UserRepository) App(ctx context.Context, id uint64) (models.App, error) {
 	const op = "userrepository.App"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
-	conn, err := u.GetConn(ctx)
+	conn, err := u.GetConn(tenant.Unscoped(ctx))
 	if err != nil {
 		return models.App{}, err
 	}
@@ -155,8 +145,15 @@ func (u *UserRepository) App(ctx context.Context, id uint64) (models.App, error)
 
 	row := conn.QueryRow(ctx, appSelectQuery, id)
 
-	var app models.App
-	err = row.Scan(&app.ID, &app.Name, &app.Secret)
+	var (
+		app    models.App
+		hashes []string
+	)
+	err = row.Scan(
+		&app.ID, &app.TenantID, &app.Name, &app.Disabled, &hashes,
+		&app.Tokens.AccessTTL, &app.Tokens.RefreshTTL, &app.Tokens.IdleTimeout,
+		&app.Tokens.GrantTypes, &app.Tokens.Audience, &app.Tokens.SigningKeyID,
+	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.App{}, fmt.Errorf("%s:%w", op, storage.ErrAppNotFound)
@@ -165,15 +162,18 @@ func (u *UserRepository) App(ctx context.Context, id uint64) (models.App, error)
 		return models.App{}, fmt.Errorf("%s:%w", op, err)
 	}
 
+	// only valid secrets are selected, their metadata is not needed to authenticate app
+	for _, hash := range hashes {
+		app.Secrets = append(app.Secrets, models.AppSecret{Hash: hash})
+	}
+
 	return app, nil
 }
 
-func (u *UserRepository) SaveRefresh(ctx context.Context, token string, userid int64, duration time.Duration) error {
+// SaveRefresh saves refresh token of user in app, zero idle means token never expires from inactivity
+func (u *UserRepository) SaveRefresh(ctx context.Context, token string, userid int64, appID int, duration, idle time.Duration) error {
 	const op = "userrepository.SaveRefresh"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
 	conn, err := u.GetConn(ctx)
 	if err != nil {
 		return fmt.Errorf("%s:%w", op, err)
@@ -181,7 +181,7 @@ func (u *UserRepository) SaveRefresh(ctx context.Context, token string, userid i
 
 	defer conn.Release()
 
-	_, err = conn.Exec(ctx, saveRefreshQuery, token, userid, duration)
+	_, err = conn.Exec(ctx, saveRefreshQuery, token, userid, appID, duration, idle)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 
@@ -194,4 +194,37 @@ func (u *UserRepository) SaveRefresh(ctx context.Context, token string, userid i
 	return nil
 }
 
-//TODO: ADD LOGIN, RECOVERY. IMPLEMENT MIGRATOR.GO, UPDATE PROTOFILES
+// SaveLoginAttempt writes attempt to login history, successful attempt also updates last login of user
+func (u *UserRepository) SaveLoginAttempt(ctx context.Context, attempt models.LoginAttempt) error {
+	const op = "userrepository.SaveLoginAttempt"
+
+	err := pgx.BeginFunc(ctx, u.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, insertLoginAttempt,
+			attempt.UserID,
+			attempt.Email,
+			attempt.AppID,
+			attempt.Success,
+			attempt.Reason,
+			attempt.IP,
+			attempt.UserAgent,
+		)
+		if err != nil {
+			return err
+		}
+
+		if !attempt.Success {
+			return nil
+		}
+
+		_, err = tx.Exec(ctx, updateLastLogin, attempt.UserID)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+//TODO: ADD RECOVERY
@@ -0,0 +1,372 @@
+package grouprepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sso/internal/domain/models"
+	"sso/internal/lib/tenant"
+	"sso/internal/storage"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type GroupRepository struct {
+	db *pgxpool.Pool
+}
+
+// New creates repository on top of shared connection pool
+func New(db *pgxpool.Pool) *GroupRepository {
+	return &GroupRepository{
+		db: db,
+	}
+}
+
+// CreateGroup saves group in tenant of context, zero ParentID makes top level group
+func (g *GroupRepository) CreateGroup(ctx context.Context, group models.Group) (models.Group, error) {
+	const op = "group_repository.CreateGroup"
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return models.Group{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	err = pgx.BeginFunc(ctx, g.db, func(tx pgx.Tx) error {
+		// foreign key is checked without row level security, so parent of another tenant is rejected here
+		if group.ParentID != 0 {
+			if err := requireGroup(ctx, tx, group.ParentID); err != nil {
+				return err
+			}
+		}
+
+		return tx.QueryRow(ctx, insertGroup, tenantID, group.Name, group.Description, group.ParentID).
+			Scan(&group.ID, &group.CreatedAt)
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+
+			return models.Group{}, fmt.Errorf("%s:%w", op, storage.ErrGroupExists)
+		}
+
+		return models.Group{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return group, nil
+}
+
+// Group returns group with roles bound to it
+func (g *GroupRepository) Group(ctx context.Context, groupID int64) (models.Group, error) {
+	const op = "group_repository.Group"
+
+	rows, err := g.db.Query(ctx, selectGroup, groupID)
+	if err != nil {
+		return models.Group{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	group, err := pgx.CollectExactlyOneRow(rows, scanGroup)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Group{}, fmt.Errorf("%s:%w", op, storage.ErrGroupNotFound)
+		}
+
+		return models.Group{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	rows, err = g.db.Query(ctx, selectGroupRoles, groupID)
+	if err != nil {
+		return models.Group{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	var role models.GroupRole
+	_, err = pgx.ForEachRow(rows, []any{&role.Role, &role.AppID}, func() error {
+		group.Roles = append(group.Roles, role)
+
+		return nil
+	})
+	if err != nil {
+		return models.Group{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return group, nil
+}
+
+// Groups returns every group of tenant of context without roles
+func (g *GroupRepository) Groups(ctx context.Context) ([]models.Group, error) {
+	const op = "group_repository.Groups"
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	rows, err := g.db.Query(ctx, selectGroups, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	groups, err := pgx.CollectRows(rows, scanGroup)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return groups, nil
+}
+
+// UpdateGroup replaces name, description and parent of group, group cannot be moved under itself
+// or under one of its subgroups
+func (g *GroupRepository) UpdateGroup(ctx context.Context, group models.Group) error {
+	const op = "group_repository.UpdateGroup"
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	err = pgx.BeginFunc(ctx, g.db, func(tx pgx.Tx) error {
+		if group.ParentID != 0 {
+			if _, err := tx.Exec(ctx, lockGroupTree, groupLock, tenantID); err != nil {
+				return err
+			}
+			if err := requireGroup(ctx, tx, group.ParentID); err != nil {
+				return err
+			}
+
+			var cycle bool
+			if err := tx.QueryRow(ctx, isAncestor, group.ID, group.ParentID).Scan(&cycle); err != nil {
+				return err
+			}
+			if cycle {
+				return storage.ErrGroupCycle
+			}
+		}
+
+		tag, err := tx.Exec(ctx, updateGroup, group.ID, group.Name, group.Description, group.ParentID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return storage.ErrGroupNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+
+			return fmt.Errorf("%s:%w", op, storage.ErrGroupExists)
+		}
+
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteGroup removes group with its memberships and role bindings, subgroups have to be moved or deleted first
+func (g *GroupRepository) DeleteGroup(ctx context.Context, groupID int64) error {
+	const op = "group_repository.DeleteGroup"
+
+	tag, err := g.db.Exec(ctx, deleteGroup, groupID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+
+			return fmt.Errorf("%s:%w", op, storage.ErrGroupHasSubgroups)
+		}
+
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s:%w", op, storage.ErrGroupNotFound)
+	}
+
+	return nil
+}
+
+func (g *GroupRepository) AddMember(ctx context.Context, groupID int64, userID int64) error {
+	const op = "group_repository.AddMember"
+
+	err := pgx.BeginFunc(ctx, g.db, func(tx pgx.Tx) error {
+		if err := requireGroup(ctx, tx, groupID); err != nil {
+			return err
+		}
+
+		var exists bool
+		if err := tx.QueryRow(ctx, userExists, userID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return storage.ErrUserNotFound
+		}
+
+		_, err := tx.Exec(ctx, insertMember, groupID, userID)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+// RemoveMember removes direct membership, ErrUserNotFound means user is not member of group
+func (g *GroupRepository) RemoveMember(ctx context.Context, groupID int64, userID int64) error {
+	const op = "group_repository.RemoveMember"
+
+	tag, err := g.db.Exec(ctx, deleteMember, groupID, userID)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
+	}
+
+	return nil
+}
+
+// BindRole binds role to group in app, app 0 means every app
+func (g *GroupRepository) BindRole(ctx context.Context, groupID int64, role string, appID uint64) error {
+	const op = "group_repository.BindRole"
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	err = pgx.BeginFunc(ctx, g.db, func(tx pgx.Tx) error {
+		if err := requireGroup(ctx, tx, groupID); err != nil {
+			return err
+		}
+
+		var roleID int64
+		if err := tx.QueryRow(ctx, selectRoleID, role, tenantID).Scan(&roleID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return storage.ErrRoleNotFound
+			}
+
+			return err
+		}
+
+		_, err := tx.Exec(ctx, insertGroupRole, groupID, roleID, appID)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+// UnbindRole removes binding of role, ErrRoleNotFound means role is not bound to group in app
+func (g *GroupRepository) UnbindRole(ctx context.Context, groupID int64, role string, appID uint64) error {
+	const op = "group_repository.UnbindRole"
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	tag, err := g.db.Exec(ctx, deleteGroupRole, groupID, role, appID, tenantID)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s:%w", op, storage.ErrRoleNotFound)
+	}
+
+	return nil
+}
+
+// Members returns direct members of group, with nested also members of its subgroups
+func (g *GroupRepository) Members(ctx context.Context, groupID int64, nested bool) ([]models.GroupMember, error) {
+	const op = "group_repository.Members"
+
+	var exists bool
+	if err := g.db.QueryRow(ctx, groupExists, groupID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s:%w", op, storage.ErrGroupNotFound)
+	}
+
+	rows, err := g.db.Query(ctx, selectMembers, groupID, nested)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	var (
+		members []models.GroupMember
+		member  models.GroupMember
+	)
+	_, err = pgx.ForEachRow(rows, []any{&member.UserID, &member.Username, &member.Email, &member.GroupID}, func() error {
+		members = append(members, member)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return members, nil
+}
+
+// UserGroups returns groups which user was added to and their ancestors
+func (g *GroupRepository) UserGroups(ctx context.Context, userID int64) ([]models.UserGroup, error) {
+	const op = "group_repository.UserGroups"
+
+	var exists bool
+	if err := g.db.QueryRow(ctx, userExists, userID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
+	}
+
+	rows, err := g.db.Query(ctx, selectUserGroups, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	var (
+		groups []models.UserGroup
+		group  models.UserGroup
+	)
+	_, err = pgx.ForEachRow(rows, []any{&group.ID, &group.Name, &group.ParentID, &group.Direct}, func() error {
+		groups = append(groups, group)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return groups, nil
+}
+
+// requireGroup checks that group is visible in tenant of transaction
+func requireGroup(ctx context.Context, tx pgx.Tx, groupID int64) error {
+	var exists bool
+	if err := tx.QueryRow(ctx, groupExists, groupID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return storage.ErrGroupNotFound
+	}
+
+	return nil
+}
+
+func scanGroup(row pgx.CollectableRow) (models.Group, error) {
+	var group models.Group
+
+	err := row.Scan(&group.ID, &group.Name, &group.Description, &group.ParentID, &group.CreatedAt)
+
+	return group, err
+}
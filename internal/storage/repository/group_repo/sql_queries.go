@@ -0,0 +1,134 @@
+package grouprepo
+
+// groupLock serializes changes of group tree inside tenant, parallel moves could build a cycle otherwise
+const groupLock = 7265722
+
+const (
+	groupColumns = `
+	group_id, name, COALESCE(description, ''), COALESCE(parent_id, 0), created_at
+	`
+
+	selectGroup = `
+	SELECT` + groupColumns + `
+	FROM groups
+	WHERE group_id = $1
+	`
+
+	selectGroups = `
+	SELECT` + groupColumns + `
+	FROM groups
+	WHERE tenant_id = $1
+	ORDER BY name
+	`
+
+	selectGroupRoles = `
+	SELECT r.role_name, gr.app_id
+	FROM group_roles gr
+	JOIN roles r ON gr.role_id = r.role_id
+	WHERE gr.group_id = $1
+	ORDER BY gr.app_id, r.role_name
+	`
+
+	groupExists = `
+	SELECT EXISTS(SELECT 1 FROM groups WHERE group_id = $1)
+	`
+
+	userExists = `
+	SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)
+	`
+
+	selectRoleID = `
+	SELECT role_id FROM roles WHERE tenant_id = $2 AND role_name = $1
+	`
+
+	lockGroupTree = `
+	SELECT pg_advisory_xact_lock($1, $2)
+	`
+
+	// parent would become descendant of group when group is one of ancestors of parent
+	isAncestor = `
+	WITH RECURSIVE ancestors(group_id) AS (
+		SELECT $2::int
+		UNION
+		SELECT g.parent_id FROM groups g
+		JOIN ancestors a ON g.group_id = a.group_id
+		WHERE g.parent_id IS NOT NULL
+	)
+	SELECT EXISTS(SELECT 1 FROM ancestors WHERE group_id = $1)
+	`
+
+	insertGroup = `
+	INSERT INTO groups(tenant_id, name, description, parent_id)
+	VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, 0))
+	RETURNING group_id, created_at
+	`
+
+	updateGroup = `
+	UPDATE groups SET
+	name = $2,
+	description = NULLIF($3, ''),
+	parent_id = NULLIF($4, 0)
+	WHERE group_id = $1
+	`
+
+	deleteGroup = `
+	DELETE FROM groups WHERE group_id = $1
+	`
+
+	insertMember = `
+	INSERT INTO group_members(group_id, user_id)
+	VALUES ($1, $2)
+	ON CONFLICT DO NOTHING
+	`
+
+	deleteMember = `
+	DELETE FROM group_members gm
+	USING groups g
+	WHERE gm.group_id = g.group_id
+	AND gm.group_id = $1 AND gm.user_id = $2
+	`
+
+	insertGroupRole = `
+	INSERT INTO group_roles(group_id, role_id, app_id)
+	VALUES ($1, $2, $3)
+	ON CONFLICT DO NOTHING
+	`
+
+	deleteGroupRole = `
+	DELETE FROM group_roles gr
+	USING groups g, roles r
+	WHERE gr.group_id = g.group_id AND gr.role_id = r.role_id
+	AND gr.group_id = $1 AND r.tenant_id = $4 AND r.role_name = $2 AND gr.app_id = $3
+	`
+
+	// members of subgroups are listed only with $2, every membership of user is a separate row
+	selectMembers = `
+	WITH RECURSIVE subgroups(group_id) AS (
+		SELECT group_id FROM groups WHERE group_id = $1
+		UNION
+		SELECT g.group_id FROM groups g
+		JOIN subgroups s ON g.parent_id = s.group_id
+	)
+	SELECT u.user_id, u.username, u.email, gm.group_id
+	FROM subgroups s
+	JOIN group_members gm ON gm.group_id = s.group_id
+	JOIN users u ON gm.user_id = u.user_id
+	WHERE $2 OR gm.group_id = $1
+	ORDER BY u.email, gm.group_id
+	`
+
+	selectUserGroups = `
+	WITH RECURSIVE user_groups(group_id, direct) AS (
+		SELECT group_id, TRUE FROM group_members WHERE user_id = $1
+		UNION
+		SELECT g.parent_id, FALSE FROM groups g
+		JOIN user_groups ug ON g.group_id = ug.group_id
+		WHERE g.parent_id IS NOT NULL
+	)
+	SELECT g.group_id, g.name, COALESCE(g.parent_id, 0), BOOL_OR(ug.direct)
+	FROM user_groups ug
+	JOIN groups g ON ug.group_id = g.group_id
+	GROUP BY g.group_id, g.name, g.parent_id
+	ORDER BY g.name
+	`
+)
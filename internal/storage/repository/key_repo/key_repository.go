@@ -0,0 +1,106 @@
+package keyrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sso/internal/domain/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type KeyRepository struct {
+	db *pgxpool.Pool
+}
+
+func New(db *pgxpool.Pool) *KeyRepository {
+	return &KeyRepository{
+		db: db,
+	}
+}
+
+// SigningKeys returns active key and keys retired less than grace ago, they still verify issued tokens
+func (k *KeyRepository) SigningKeys(ctx context.Context, grace time.Duration) ([]models.SigningKey, error) {
+	const op = "key_repository.SigningKeys"
+
+	keys, err := k.keys(ctx, selectSigningKeys, grace)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return keys, nil
+}
+
+// AllSigningKeys returns every key including long retired ones
+func (k *KeyRepository) AllSigningKeys(ctx context.Context) ([]models.SigningKey, error) {
+	const op = "key_repository.AllSigningKeys"
+
+	keys, err := k.keys(ctx, selectAllSigningKeys)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return keys, nil
+}
+
+// RotateKey retires current active key and makes given key active
+func (k *KeyRepository) RotateKey(ctx context.Context, key models.SigningKey) (models.SigningKey, error) {
+	const op = "key_repository.RotateKey"
+
+	err := pgx.BeginFunc(ctx, k.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, retireActiveKey); err != nil {
+			return err
+		}
+
+		return tx.QueryRow(ctx, insertSigningKey, key.ID, key.Secret).Scan(&key.CreatedAt)
+	})
+	if err != nil {
+		return models.SigningKey{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	key.Active = true
+
+	return key, nil
+}
+
+// CreateKey saves key which is not active, apps use it as their dedicated signing key
+func (k *KeyRepository) CreateKey(ctx context.Context, key models.SigningKey) (models.SigningKey, error) {
+	const op = "key_repository.CreateKey"
+
+	if err := k.db.QueryRow(ctx, insertDedicatedKey, key.ID, key.Secret).Scan(&key.CreatedAt); err != nil {
+		return models.SigningKey{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	key.Active = false
+
+	return key, nil
+}
+
+func (k *KeyRepository) keys(ctx context.Context, query string, args ...any) ([]models.SigningKey, error) {
+	rows, err := k.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		keys      []models.SigningKey
+		key       models.SigningKey
+		retiredAt *time.Time
+	)
+	_, err = pgx.ForEachRow(rows, []any{&key.ID, &key.Secret, &key.Active, &key.CreatedAt, &retiredAt}, func() error {
+		key.RetiredAt = time.Time{}
+		if retiredAt != nil {
+			key.RetiredAt = *retiredAt
+		}
+		keys = append(keys, key)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
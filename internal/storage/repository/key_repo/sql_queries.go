@@ -0,0 +1,33 @@
+package keyrepo
+
+const (
+	selectSigningKeys = `
+	SELECT kid, secret, active, created_at, retired_at
+	FROM signing_keys
+	WHERE retired_at IS NULL OR retired_at > NOW() - $1::interval
+	ORDER BY created_at DESC
+	`
+
+	selectAllSigningKeys = `
+	SELECT kid, secret, active, created_at, retired_at
+	FROM signing_keys
+	ORDER BY created_at DESC
+	`
+
+	retireActiveKey = `
+	UPDATE signing_keys SET active = FALSE, retired_at = NOW()
+	WHERE active
+	`
+
+	insertDedicatedKey = `
+	INSERT INTO signing_keys(kid, secret, active)
+	VALUES ($1, $2, FALSE)
+	RETURNING created_at
+	`
+
+	insertSigningKey = `
+	INSERT INTO signing_keys(kid, secret, active)
+	VALUES ($1, $2, TRUE)
+	RETURNING created_at
+	`
+)
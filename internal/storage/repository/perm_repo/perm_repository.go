@@ -5,36 +5,29 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/lib/tenant"
 	"sso/internal/storage"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/opentracing/opentracing-go"
 )
 
 type PermRepository struct {
 	db *pgxpool.Pool
 }
 
-func New(dsn string) (*PermRepository, error) {
-	const op = "internal.permrepo.New"
-
-	db, err := pgxpool.New(context.Background(), dsn)
-	if err != nil {
-		return nil, fmt.Errorf("%s:%w", op, err)
-	}
+// New creates repository on top of shared connection pool
+func New(db *pgxpool.Pool) *PermRepository {
 	return &PermRepository{
 		db: db,
-	}, nil
+	}
 }
 
 // func to get connection from pool
 func (p *PermRepository) GetConn(ctx context.Context) (*pgxpool.Conn, error) {
 	const op = "perm_repository.GetConn"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
 	conn, err := p.db.Acquire(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("%s:%w", op, err)
@@ -45,14 +38,10 @@ func (p *PermRepository) GetConn(ctx context.Context) (*pgxpool.Conn, error) {
 }
 
 // check funcs
-func (p *PermRepository) UserPermissions(ctx context.Context, userID int64) (map[int]bool, error) {
+// UserPermissions returns rules of roles bound to user in app or in every app
+func (p *PermRepository) UserPermissions(ctx context.Context, userID int64, appID uint64) ([]models.PermissionRule, error) {
 	const op = "perm_repository.UsersPermissions"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
-	permissions := make(map[int]bool)
-
 	conn, err := p.GetConn(ctx)
 	if err != nil {
 
@@ -61,7 +50,7 @@ func (p *PermRepository) UserPermissions(ctx context.Context, userID int64) (map
 
 	defer conn.Release()
 
-	rows, err := conn.Query(ctx, getRolePermits, userID)
+	rows, err := conn.Query(ctx, getRolePermits, userID, appID)
 	if err != nil {
 
 		return nil, fmt.Errorf("%s:%w", op, err)
@@ -69,60 +58,105 @@ func (p *PermRepository) UserPermissions(ctx context.Context, userID int64) (map
 
 	defer rows.Close()
 
-	var permID int
-	_, err = pgx.ForEachRow(rows, []any{&permID}, func() error {
-		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
+	var (
+		rule  models.PermissionRule
+		rules []models.PermissionRule
+	)
+	_, err = pgx.ForEachRow(rows, []any{&rule.Name, &rule.Effect}, func() error {
+		rules = append(rules, rule)
+
+		return nil
+	})
 
-				return fmt.Errorf("%s:%w", op, storage.ErrAppNotFound)
-			}
+	if err != nil {
 
-			return fmt.Errorf("%s:%w", op, err)
-		}
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
 
-		permissions[permID] = true
+	return rules, nil
+}
 
-		return nil
-	})
+// UserRoles returns names of roles bound to user in app or in every app
+func (p *PermRepository) UserRoles(ctx context.Context, userID int64, appID uint64) ([]string, error) {
+	const op = "perm_repository.UserRoles"
 
+	roles, err := p.names(ctx, getUserRoles, userID, appID)
 	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
 
+	return roles, nil
+}
+
+// PermissionNames returns every concrete permission of tenant of context, wildcard grants are not permissions by themselves
+func (p *PermRepository) PermissionNames(ctx context.Context) ([]string, error) {
+	const op = "perm_repository.PermissionNames"
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	names, err := p.names(ctx, getPermissionNames, tenantID)
+	if err != nil {
 		return nil, fmt.Errorf("%s:%w", op, err)
 	}
 
-	return permissions, nil
+	return names, nil
 }
 
-func (p *PermRepository) CheckPerm(permMap map[int]bool, permID int) (bool, error) {
-	if _, ok := permMap[permID]; !ok {
+// GrantedPermissions returns those of names which user is allowed in app, deny rules win as in checks
+func (p *PermRepository) GrantedPermissions(ctx context.Context, userID int64, appID uint64, names []string) ([]string, error) {
+	const op = "perm_repository.GrantedPermissions"
 
-		return false, fmt.Errorf("taboo action")
+	rules, err := p.UserPermissions(ctx, userID, appID)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
 	}
 
-	return permMap[permID], nil
+	granted := make([]string, 0, len(names))
+	for _, name := range names {
+		if resolvePermission(rules, name) {
+			granted = append(granted, name)
+		}
+	}
+
+	return granted, nil
 }
 
-func (p *PermRepository) hasPermission(ctx context.Context, userID int64, permID int) (bool, error) {
-	const op = "perm_repository.hasPermission"
+func (p *PermRepository) names(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
 
-	permMap, err := p.UserPermissions(ctx, userID)
+	return names, nil
+}
+
+func (p *PermRepository) CheckPerm(rules []models.PermissionRule, permName string) bool {
+	return resolvePermission(rules, permName)
+}
+
+func (p *PermRepository) hasPermission(ctx context.Context, userID int64, appID uint64, permName string) (models.Permission, error) {
+	const op = "perm_repository.hasPermission"
+
+	rules, err := p.UserPermissions(ctx, userID, appID)
 	if err != nil {
-		return false, fmt.Errorf("%s:%w", op, err)
+		return models.Permission{}, fmt.Errorf("%s:%w", op, err)
 	}
 
-	return p.CheckPerm(permMap, permID)
+	return models.Permission{Perm: p.CheckPerm(rules, permName)}, nil
 }
 
 // APP CHECK FUNC
 func (p *PermRepository) appExists(ctx context.Context, appID uint64) (bool, error) {
 	const op = "perm_repository.appExists"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
 	conn, err := p.GetConn(ctx)
 	if err != nil {
 
@@ -146,79 +180,63 @@ func (p *PermRepository) appExists(ctx context.Context, appID uint64) (bool, err
 	return exists, nil
 }
 
-// PERMIT LAYER
-func (p *PermRepository) DeleteUsrPermission(ctx context.Context, appID uint64, userID int64) (bool, error) {
-	const op = "perm_repository.DeleteUsrPermission"
-
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
+// appPermission checks that app exists and resolves permission of user in it
+func (p *PermRepository) appPermission(ctx context.Context, userID int64, appID uint64, permName string) (models.Permission, error) {
+	const op = "perm_repository.appPermission"
 
-	_, err := p.appExists(ctx, appID)
+	exists, err := p.appExists(ctx, appID)
 	if err != nil {
-		return false, fmt.Errorf("%s:%w", op, err)
+		return models.Permission{}, fmt.Errorf("%s:%w", op, err)
+	}
+	if !exists {
+		return models.Permission{}, fmt.Errorf("%s:%w", op, storage.ErrAppNotFound)
 	}
 
-	perm, err := p.hasPermission(ctx, userID, 1)
-
+	perm, err := p.hasPermission(ctx, userID, appID, permName)
 	if err != nil {
-		return false, fmt.Errorf("%s:%w", op, err)
+		return models.Permission{}, fmt.Errorf("%s:%w", op, err)
 	}
+
 	return perm, nil
 }
 
-func (p *PermRepository) DownloadPermission(ctx context.Context, appID uint64, userID int64) (bool, error) {
-	const op = "perm_repository.DownloadPermission"
-
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
+// PERMIT LAYER
+func (p *PermRepository) DeleteUsrPermission(ctx context.Context, userID int64, appID uint64) (models.Permission, error) {
+	const op = "perm_repository.DeleteUsrPermission"
 
-	_, err := p.appExists(ctx, appID)
+	perm, err := p.appPermission(ctx, userID, appID, models.PermDeleteUser)
 	if err != nil {
-		return false, fmt.Errorf("%s:%w", op, err)
+		return models.Permission{}, fmt.Errorf("%s:%w", op, err)
 	}
+	return perm, nil
+}
 
-	perm, err := p.hasPermission(ctx, userID, 2)
+func (p *PermRepository) DownloadPermission(ctx context.Context, userID int64, appID uint64) (models.Permission, error) {
+	const op = "perm_repository.DownloadPermission"
 
+	perm, err := p.appPermission(ctx, userID, appID, models.PermVideoDownload)
 	if err != nil {
-		return false, fmt.Errorf("%s:%w", op, err)
+		return models.Permission{}, fmt.Errorf("%s:%w", op, err)
 	}
 	return perm, nil
 }
 
-func (p *PermRepository) UpdateUsrPermission(ctx context.Context, appID uint64, userID int64) (bool, error) {
+func (p *PermRepository) UpdateUsrPermission(ctx context.Context, userID int64, appID uint64) (models.Permission, error) {
 	const op = "perm_repository.UpdateUsrPermission"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
-	_, err := p.appExists(ctx, appID)
+	perm, err := p.appPermission(ctx, userID, appID, models.PermUpdateUser)
 	if err != nil {
-		return false, fmt.Errorf("%s:%w", op, err)
-	}
-
-	perm, err := p.hasPermission(ctx, userID, 3)
-
-	if err != nil {
-		return false, fmt.Errorf("%s:%w", op, err)
+		return models.Permission{}, fmt.Errorf("%s:%w", op, err)
 	}
 	return perm, nil
 }
 
-func (p *PermRepository) ChangeOptionPermission(ctx context.Context, appID uint64, userID int64) (bool, error) {
+func (p *PermRepository) ChangeOptionPermission(ctx context.Context, userID int64, appID uint64) (models.Permission, error) {
 	const op = "perm_repository.ChangeOptionPermission"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
-	_, err := p.appExists(ctx, appID)
+	perm, err := p.appPermission(ctx, userID, appID, models.PermChangeOptions)
 	if err != nil {
-		return false, fmt.Errorf("%s:%w", op, err)
-	}
-
-	perm, err := p.hasPermission(ctx, userID, 4)
-
-	if err != nil {
-		return false, fmt.Errorf("%s:%w", op, err)
+		return models.Permission{}, fmt.Errorf("%s:%w", op, err)
 	}
 	return perm, nil
 }
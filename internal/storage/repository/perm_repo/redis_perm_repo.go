@@ -3,16 +3,20 @@ package permrepo
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sso/internal/domain/models"
-	gprcerrors "sso/internal/lib/gprc_errors"
 	"time"
 
-	"github.com/opentracing/opentracing-go"
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	permissionKeyPattern = "permission:*"
+	flushBatchSize       = 500
+)
+
 // redis repo struct
 type permRedisRepository struct {
 	redisClient *redis.Client
@@ -28,14 +32,11 @@ func NewRedisPermRepository(redisClient *redis.Client, key string, log *slog.Log
 func (p *permRedisRepository) PermissionCtx(ctx context.Context, key string) (*models.Permission, error) {
 	const op = "perm_repo.redis_perm_repo.PermissionCtx"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
 	permBytes, err := p.redisClient.Get(ctx, key).Bytes()
 	if err != nil {
-		if err != redis.Nil {
+		if errors.Is(err, redis.Nil) {
 
-			return nil, gprcerrors.ErrNotFound
+			return nil, fmt.Errorf("%s:%w", op, redis.Nil)
 		}
 
 		return nil, fmt.Errorf("%s:%w", op, err)
@@ -53,9 +54,6 @@ func (p *permRedisRepository) PermissionCtx(ctx context.Context, key string) (*m
 func (p *permRedisRepository) SetPermCtx(ctx context.Context, key string, seconds int, permission models.Permission) error {
 	const op = "redis_perm_repo.setpermctx"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
 	userBytes, err := json.Marshal(permission)
 	if err != nil {
 		return fmt.Errorf("%s:%w", op, err)
@@ -67,8 +65,35 @@ func (p *permRedisRepository) SetPermCtx(ctx context.Context, key string, second
 func (p *permRedisRepository) DelPermCtx(ctx context.Context, key string) error {
 	const op = "redis_perm_repo.delpermctx"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
 	return p.redisClient.Del(ctx, key).Err()
 }
+
+// FlushPermissions removes every cached permission decision
+func (p *permRedisRepository) FlushPermissions(ctx context.Context) error {
+	const op = "redis_perm_repo.flushpermissions"
+
+	iter := p.redisClient.Scan(ctx, 0, permissionKeyPattern, flushBatchSize).Iterator()
+
+	keys := make([]string, 0, flushBatchSize)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+
+		if len(keys) == flushBatchSize {
+			if err := p.redisClient.Del(ctx, keys...).Err(); err != nil {
+				return fmt.Errorf("%s:%w", op, err)
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	if len(keys) > 0 {
+		if err := p.redisClient.Del(ctx, keys...).Err(); err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package permrepo
+
+import (
+	"sso/internal/domain/models"
+)
+
+// resolvePermission decides if requested permission is allowed by rules.
+//
+// Deny always wins: one matching deny rule overrides any number of matching allows,
+// and permission without any matching rule is not allowed
+func resolvePermission(rules []models.PermissionRule, requested string) bool {
+	allowed := false
+
+	for _, rule := range rules {
+		if !rule.Covers(requested) {
+			continue
+		}
+
+		if rule.Effect == models.EffectDeny {
+			return false
+		}
+
+		allowed = true
+	}
+
+	return allowed
+}
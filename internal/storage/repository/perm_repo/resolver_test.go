@@ -0,0 +1,74 @@
+package permrepo
+
+import (
+	"testing"
+
+	"sso/internal/domain/models"
+)
+
+func allow(name string) models.PermissionRule {
+	return models.PermissionRule{Name: name, Effect: models.EffectAllow}
+}
+
+func deny(name string) models.PermissionRule {
+	return models.PermissionRule{Name: name, Effect: models.EffectDeny}
+}
+
+func TestResolvePermission(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     []models.PermissionRule
+		requested string
+		want      bool
+	}{
+		{name: "no rules", requested: "video:download", want: false},
+		{name: "exact allow", rules: []models.PermissionRule{allow("video:download")}, requested: "video:download", want: true},
+		{name: "unrelated allow", rules: []models.PermissionRule{allow("video:upload")}, requested: "video:download", want: false},
+		{name: "family allow", rules: []models.PermissionRule{allow("video:*")}, requested: "video:download", want: true},
+		{name: "global allow", rules: []models.PermissionRule{allow("*")}, requested: "delete_user", want: true},
+		{
+			name:      "exact deny wins over family allow",
+			rules:     []models.PermissionRule{allow("video:*"), deny("video:download")},
+			requested: "video:download",
+			want:      false,
+		},
+		{
+			name:      "deny of other action keeps family allow",
+			rules:     []models.PermissionRule{allow("video:*"), deny("video:download")},
+			requested: "video:upload",
+			want:      true,
+		},
+		{
+			name:      "family deny wins over exact allow",
+			rules:     []models.PermissionRule{allow("video:download"), deny("video:*")},
+			requested: "video:download",
+			want:      false,
+		},
+		{
+			name:      "deny wins regardless of order",
+			rules:     []models.PermissionRule{deny("video:download"), allow("video:download"), allow("*")},
+			requested: "video:download",
+			want:      false,
+		},
+		{
+			name:      "global deny wins over everything",
+			rules:     []models.PermissionRule{allow("*"), allow("delete_user"), deny("*")},
+			requested: "delete_user",
+			want:      false,
+		},
+		{
+			name:      "deny alone does not allow other permissions",
+			rules:     []models.PermissionRule{deny("video:download")},
+			requested: "video:upload",
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolvePermission(tt.rules, tt.requested); got != tt.want {
+				t.Errorf("resolvePermission(%q) = %v, want %v", tt.requested, got, tt.want)
+			}
+		})
+	}
+}
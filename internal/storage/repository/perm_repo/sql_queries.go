@@ -1,14 +1,47 @@
 package permrepo
 
 const (
-	getRolePermits = `
-    SELECT DISTINCT p.id AS permission_id
+	// effectiveRoles are roles granted to user $1 in app $2 directly and through groups,
+	// membership in subgroup grants roles of every ancestor group as well
+	effectiveRoles = `
+    WITH RECURSIVE user_groups(group_id) AS (
+        SELECT group_id FROM group_members WHERE user_id = $1
+        UNION
+        SELECT g.parent_id FROM groups g
+        JOIN user_groups ug ON g.group_id = ug.group_id
+        WHERE g.parent_id IS NOT NULL
+    ),
+    effective_roles(role_id) AS (
+        SELECT ur.role_id FROM user_roles ur
+        WHERE ur.user_id = $1 AND ur.app_id IN (0, $2)
+        UNION
+        SELECT gr.role_id FROM group_roles gr
+        JOIN user_groups ug ON gr.group_id = ug.group_id
+        WHERE gr.app_id IN (0, $2)
+    )
+    `
+
+	getRolePermits = effectiveRoles + `
+    SELECT DISTINCT p.perm_name, rp.effect
     FROM users u
-    JOIN user_roles ur ON u.userid = ur.userid
-    JOIN roles r ON ur.role_id = r.role_id
+    CROSS JOIN effective_roles er
+    JOIN roles r ON er.role_id = r.role_id
     JOIN role_permissions rp ON r.role_id = rp.role_id
     JOIN permissions p ON rp.permission_id = p.id
-    WHERE u.userid = $1
+    WHERE u.user_id = $1
+    `
+
+	getUserRoles = effectiveRoles + `
+    SELECT DISTINCT r.role_name
+    FROM effective_roles er
+    JOIN roles r ON er.role_id = r.role_id
+    ORDER BY r.role_name
+    `
+
+	getPermissionNames = `
+    SELECT perm_name FROM permissions
+    WHERE tenant_id = $1 AND perm_name NOT LIKE '%*'
+    ORDER BY perm_name
     `
 
 	appExists = `
@@ -0,0 +1,154 @@
+package policyrepo
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/lib/tenant"
+	"sso/internal/storage"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PolicyRepository struct {
+	db *pgxpool.Pool
+}
+
+func New(db *pgxpool.Pool) *PolicyRepository {
+	return &PolicyRepository{
+		db: db,
+	}
+}
+
+// Policy reads whole RBAC model of tenant of context
+func (p *PolicyRepository) Policy(ctx context.Context) (models.Policy, error) {
+	const op = "policy_repository.Policy"
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return models.Policy{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	var policy models.Policy
+
+	var role models.PolicyRole
+	if err := p.collect(ctx, selectRoles, tenantID, []any{&role.Name, &role.Description}, func() {
+		policy.Roles = append(policy.Roles, role)
+	}); err != nil {
+		return models.Policy{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	var permission string
+	if err := p.collect(ctx, selectPermissions, tenantID, []any{&permission}, func() {
+		policy.Permissions = append(policy.Permissions, permission)
+	}); err != nil {
+		return models.Policy{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	var rolePerm models.PolicyRolePermission
+	if err := p.collect(ctx, selectRolePermissions, tenantID, []any{&rolePerm.Role, &rolePerm.Permission, &rolePerm.Effect}, func() {
+		policy.RolePermissions = append(policy.RolePermissions, rolePerm)
+	}); err != nil {
+		return models.Policy{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	var binding models.PolicyBinding
+	if err := p.collect(ctx, selectBindings, tenantID, []any{&binding.User, &binding.Role, &binding.AppID}, func() {
+		policy.Bindings = append(policy.Bindings, binding)
+	}); err != nil {
+		return models.Policy{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return policy, nil
+}
+
+// collect runs query of tenant and calls add after every scanned row
+func (p *PolicyRepository) collect(ctx context.Context, query string, tenantID int64, scans []any, add func()) error {
+	rows, err := p.db.Query(ctx, query, tenantID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	_, err = pgx.ForEachRow(rows, scans, func() error {
+		add()
+
+		return nil
+	})
+
+	return err
+}
+
+// ApplyPolicyChanges executes changes in order inside one transaction, changes touch only tenant of context
+func (p *PolicyRepository) ApplyPolicyChanges(ctx context.Context, changes []models.PolicyChange) error {
+	const op = "policy_repository.ApplyPolicyChanges"
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	err = pgx.BeginFunc(ctx, p.db, func(tx pgx.Tx) error {
+		for _, change := range changes {
+			if err := applyChange(ctx, tx, tenantID, change); err != nil {
+				return fmt.Errorf("%s: %w", change, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+func applyChange(ctx context.Context, tx pgx.Tx, tenantID int64, change models.PolicyChange) error {
+	remove := change.Op == models.PolicyRemove
+
+	switch change.Kind {
+	case models.PolicyKindRole:
+		if remove {
+			_, err := tx.Exec(ctx, deleteRole, change.Role.Name, tenantID)
+			return err
+		}
+		_, err := tx.Exec(ctx, upsertRole, change.Role.Name, change.Role.Description, tenantID)
+		return err
+
+	case models.PolicyKindPermission:
+		if remove {
+			_, err := tx.Exec(ctx, deletePermission, change.Permission, tenantID)
+			return err
+		}
+		_, err := tx.Exec(ctx, insertPermission, change.Permission, tenantID)
+		return err
+
+	case models.PolicyKindRolePermission:
+		rp := change.RolePermission
+		if remove {
+			_, err := tx.Exec(ctx, deleteRolePermission, rp.Role, rp.Permission, tenantID)
+			return err
+		}
+		_, err := tx.Exec(ctx, upsertRolePermission, rp.Role, rp.Permission, rp.Effect, tenantID)
+		return err
+
+	case models.PolicyKindBinding:
+		b := change.Binding
+		if remove {
+			_, err := tx.Exec(ctx, deleteBinding, b.User, b.Role, b.AppID, tenantID)
+			return err
+		}
+		tag, err := tx.Exec(ctx, insertBinding, b.User, b.Role, b.AppID, tenantID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return storage.ErrUserNotFound
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown policy change kind %q", change.Kind)
+}
@@ -0,0 +1,91 @@
+package policyrepo
+
+const (
+	selectRoles = `
+	SELECT role_name, COALESCE(role_description, '')
+	FROM roles
+	WHERE tenant_id = $1
+	ORDER BY role_name
+	`
+
+	selectPermissions = `
+	SELECT perm_name
+	FROM permissions
+	WHERE tenant_id = $1
+	ORDER BY perm_name
+	`
+
+	selectRolePermissions = `
+	SELECT r.role_name, p.perm_name, rp.effect
+	FROM role_permissions rp
+	JOIN roles r ON rp.role_id = r.role_id
+	JOIN permissions p ON rp.permission_id = p.id
+	WHERE r.tenant_id = $1 AND p.tenant_id = $1
+	ORDER BY r.role_name, p.perm_name
+	`
+
+	selectBindings = `
+	SELECT u.email, r.role_name, ur.app_id
+	FROM user_roles ur
+	JOIN users u ON ur.user_id = u.user_id
+	JOIN roles r ON ur.role_id = r.role_id
+	WHERE u.tenant_id = $1 AND r.tenant_id = $1
+	ORDER BY u.email, r.role_name, ur.app_id
+	`
+
+	upsertRole = `
+	INSERT INTO roles(tenant_id, role_name, role_description)
+	VALUES ($3, $1, NULLIF($2, ''))
+	ON CONFLICT (tenant_id, role_name) DO UPDATE SET role_description = EXCLUDED.role_description
+	`
+
+	deleteRole = `
+	DELETE FROM roles
+	WHERE tenant_id = $2 AND role_name = $1
+	`
+
+	insertPermission = `
+	INSERT INTO permissions(tenant_id, perm_name)
+	VALUES ($2, $1)
+	ON CONFLICT (tenant_id, perm_name) DO NOTHING
+	`
+
+	deletePermission = `
+	DELETE FROM permissions
+	WHERE tenant_id = $2 AND perm_name = $1
+	`
+
+	upsertRolePermission = `
+	INSERT INTO role_permissions(role_id, permission_id, effect)
+	SELECT r.role_id, p.id, $3
+	FROM roles r, permissions p
+	WHERE r.tenant_id = $4 AND p.tenant_id = $4
+	AND r.role_name = $1 AND p.perm_name = $2
+	ON CONFLICT (role_id, permission_id) DO UPDATE SET effect = EXCLUDED.effect
+	`
+
+	deleteRolePermission = `
+	DELETE FROM role_permissions rp
+	USING roles r, permissions p
+	WHERE rp.role_id = r.role_id AND rp.permission_id = p.id
+	AND r.tenant_id = $3 AND p.tenant_id = $3
+	AND r.role_name = $1 AND p.perm_name = $2
+	`
+
+	insertBinding = `
+	INSERT INTO user_roles(user_id, role_id, app_id)
+	SELECT u.user_id, r.role_id, $3
+	FROM users u, roles r
+	WHERE u.tenant_id = $4 AND r.tenant_id = $4
+	AND u.email = $1 AND r.role_name = $2
+	ON CONFLICT DO NOTHING
+	`
+
+	deleteBinding = `
+	DELETE FROM user_roles ur
+	USING users u, roles r
+	WHERE ur.user_id = u.user_id AND ur.role_id = r.role_id
+	AND u.tenant_id = $4 AND r.tenant_id = $4
+	AND u.email = $1 AND r.role_name = $2 AND ur.app_id = $3
+	`
+)
@@ -0,0 +1,212 @@
+package profilerepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sso/internal/domain/models"
+	"sso/internal/lib/tenant"
+	"sso/internal/storage"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ProfileRepository changes users on their own behalf, user is always taken from token of request
+type ProfileRepository struct {
+	db *pgxpool.Pool
+}
+
+// New creates repository on top of shared connection pool
+func New(db *pgxpool.Pool) *ProfileRepository {
+	return &ProfileRepository{
+		db: db,
+	}
+}
+
+func (p *ProfileRepository) Profile(ctx context.Context, userID int64) (models.Profile, error) {
+	const op = "profile_repository.Profile"
+
+	var (
+		profile   models.Profile
+		lastLogin *time.Time
+	)
+	err := p.db.QueryRow(ctx, selectProfile, userID).Scan(
+		&profile.UserID,
+		&profile.TenantID,
+		&profile.Username,
+		&profile.Email,
+		&profile.Avatar,
+		&profile.PendingEmail,
+		&lastLogin,
+		&profile.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Profile{}, fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
+		}
+
+		return models.Profile{}, fmt.Errorf("%s:%w", op, err)
+	}
+	if lastLogin != nil {
+		profile.LastLogin = *lastLogin
+	}
+
+	return profile, nil
+}
+
+// UpdateProfile replaces username and avatar, username is unique inside tenant
+func (p *ProfileRepository) UpdateProfile(ctx context.Context, userID int64, username string, avatar string) error {
+	const op = "profile_repository.UpdateProfile"
+
+	tag, err := p.db.Exec(ctx, updateProfile, userID, username, avatar)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+
+			return fmt.Errorf("%s:%w", op, storage.ErrUserExists)
+		}
+
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
+	}
+
+	return nil
+}
+
+func (p *ProfileRepository) PasswordHash(ctx context.Context, userID int64) ([]byte, error) {
+	const op = "profile_repository.PasswordHash"
+
+	var hash []byte
+	if err := p.db.QueryRow(ctx, selectPasswordHash, userID).Scan(&hash); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
+		}
+
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return hash, nil
+}
+
+// SetPassword saves new password hash and revokes every session of user: refresh tokens are deleted
+// and access tokens issued before are reported inactive
+func (p *ProfileRepository) SetPassword(ctx context.Context, userID int64, hash []byte) error {
+	const op = "profile_repository.SetPassword"
+
+	err := pgx.BeginFunc(ctx, p.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, updatePassword, userID, hash)
+		if err != nil {
+			return err
+		}
+		// refresh_tokens has no tenant, user has to be visible before its tokens are deleted
+		if tag.RowsAffected() == 0 {
+			return storage.ErrUserNotFound
+		}
+
+		_, err = tx.Exec(ctx, deleteRefreshTokens, userID)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+// SaveEmailChange stores new address which waits for confirmation, address taken by another user
+// of tenant is rejected with ErrUserExists
+func (p *ProfileRepository) SaveEmailChange(ctx context.Context, change models.EmailChange) error {
+	const op = "profile_repository.SaveEmailChange"
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	err = pgx.BeginFunc(ctx, p.db, func(tx pgx.Tx) error {
+		var exists bool
+		if err := tx.QueryRow(ctx, userExists, change.UserID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return storage.ErrUserNotFound
+		}
+
+		var taken bool
+		if err := tx.QueryRow(ctx, emailTaken, change.NewEmail, tenantID).Scan(&taken); err != nil {
+			return err
+		}
+		if taken {
+			return storage.ErrUserExists
+		}
+
+		_, err := tx.Exec(ctx, upsertEmailChange, change.UserID, change.NewEmail, change.CodeHash, change.ExpiresAt)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+// EmailChange returns pending change of user, expired change is returned too
+func (p *ProfileRepository) EmailChange(ctx context.Context, userID int64) (models.EmailChange, error) {
+	const op = "profile_repository.EmailChange"
+
+	var change models.EmailChange
+	err := p.db.QueryRow(ctx, selectEmailChange, userID).Scan(
+		&change.UserID,
+		&change.NewEmail,
+		&change.CodeHash,
+		&change.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.EmailChange{}, fmt.Errorf("%s:%w", op, storage.ErrEmailChangeMissing)
+		}
+
+		return models.EmailChange{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return change, nil
+}
+
+// ConfirmEmailChange replaces email of user with confirmed address and forgets pending change
+func (p *ProfileRepository) ConfirmEmailChange(ctx context.Context, userID int64, email string) error {
+	const op = "profile_repository.ConfirmEmailChange"
+
+	err := pgx.BeginFunc(ctx, p.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, updateEmail, userID, email)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return storage.ErrUserNotFound
+		}
+
+		_, err = tx.Exec(ctx, deleteEmailChange, userID)
+
+		return err
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		// address could be taken by registration after change was requested
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+
+			return fmt.Errorf("%s:%w", op, storage.ErrUserExists)
+		}
+
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package profilerepo
+
+const (
+	// pending address is shown only while its code is valid
+	selectProfile = `
+	SELECT u.user_id, u.tenant_id, u.username, u.email, COALESCE(u.avatar, ''),
+	COALESCE(ec.new_email, ''), u.last_login, u.created_at
+	FROM users u
+	LEFT JOIN email_changes ec ON ec.user_id = u.user_id AND ec.expires_at > NOW()
+	WHERE u.user_id = $1
+	`
+
+	updateProfile = `
+	UPDATE users SET
+	username = $2,
+	avatar = NULLIF($3, '')
+	WHERE user_id = $1
+	`
+
+	selectPasswordHash = `
+	SELECT hashedpassw FROM users
+	WHERE user_id = $1
+	`
+
+	// tokens issued before new password are reported inactive by introspection
+	updatePassword = `
+	UPDATE users SET hashedpassw = $2, tokens_revoked_at = NOW()
+	WHERE user_id = $1
+	`
+
+	deleteRefreshTokens = `
+	DELETE FROM refresh_tokens
+	WHERE user_id = $1
+	`
+
+	userExists = `
+	SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)
+	`
+
+	emailTaken = `
+	SELECT EXISTS(SELECT 1 FROM users WHERE tenant_id = $2 AND email = $1)
+	`
+
+	// new request replaces previous one, so only the last sent code works
+	upsertEmailChange = `
+	INSERT INTO email_changes(user_id, new_email, code_hash, expires_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (user_id) DO UPDATE SET
+	new_email = EXCLUDED.new_email,
+	code_hash = EXCLUDED.code_hash,
+	expires_at = EXCLUDED.expires_at,
+	created_at = NOW()
+	`
+
+	// changes of users of other tenants are hidden by row level security
+	selectEmailChange = `
+	SELECT user_id, new_email, code_hash, expires_at
+	FROM email_changes
+	WHERE user_id = $1
+	`
+
+	updateEmail = `
+	UPDATE users SET email = $2
+	WHERE user_id = $1
+	`
+
+	deleteEmailChange = `
+	DELETE FROM email_changes
+	WHERE user_id = $1
+	`
+)
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	adminrepo "sso/internal/storage/repository/admin_repo"
+	apprepo "sso/internal/storage/repository/app_repo"
+	userrepository "sso/internal/storage/repository/auth_repo"
+	grouprepo "sso/internal/storage/repository/group_repo"
+	keyrepo "sso/internal/storage/repository/key_repo"
+	permrepo "sso/internal/storage/repository/perm_repo"
+	policyrepo "sso/internal/storage/repository/policy_repo"
+	profilerepo "sso/internal/storage/repository/profile_repo"
+	statrepo "sso/internal/storage/repository/stat_repo"
+	tenantrepo "sso/internal/storage/repository/tenant_repo"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repositories holds every postgres repository, all of them share one pool
+type Repositories struct {
+	Users       *userrepository.UserRepository
+	Permissions *permrepo.PermRepository
+	Status      *statrepo.StatRepository
+	Policy      *policyrepo.PolicyRepository
+	Keys        *keyrepo.KeyRepository
+	Admin       *adminrepo.AdminRepository
+	Apps        *apprepo.AppRepository
+	Tenants     *tenantrepo.TenantRepository
+	Groups      *grouprepo.GroupRepository
+	Profiles    *profilerepo.ProfileRepository
+}
+
+// New builds all repositories on top of given pool
+func New(db *pgxpool.Pool) *Repositories {
+	return &Repositories{
+		Users:       userrepository.New(db),
+		Permissions: permrepo.New(db),
+		Status:      statrepo.New(db),
+		Policy:      policyrepo.New(db),
+		Keys:        keyrepo.New(db),
+		Admin:       adminrepo.New(db),
+		Apps:        apprepo.New(db),
+		Tenants:     tenantrepo.New(db),
+		Groups:      grouprepo.New(db),
+		Profiles:    profilerepo.New(db),
+	}
+}
@@ -2,19 +2,107 @@ package statrepo
 
 const (
 	getIsUsrBanned = `
-	SELECT account_locked FROM users
-	WHERE userid = $1
+	SELECT u.account_locked, b.id IS NOT NULL, COALESCE(b.reason, ''), b.expires_at
+	FROM users u
+	LEFT JOIN LATERAL (
+		SELECT id, reason, expires_at FROM user_bans
+		WHERE user_id = u.user_id AND unbanned_at IS NULL
+		AND (expires_at IS NULL OR expires_at > NOW())
+		ORDER BY created_at DESC
+		LIMIT 1
+	) b ON TRUE
+	WHERE u.user_id = $1
 	`
 
 	getRolesByID = `
-	SELECT r.role_name u.username FROM users u
-	JOIN user_roles ur ON u.user_id = ur.userid
-	JOIN roles r ON ur.role_id = r.role_id
-	WHERE u.userid = $1
+	SELECT u.username, COALESCE(r.role_name, '') FROM users u
+	LEFT JOIN user_roles ur ON u.user_id = ur.user_id
+	LEFT JOIN roles r ON ur.role_id = r.role_id
+	WHERE u.user_id = $1
+	ORDER BY ur.app_id, r.role_name
+	LIMIT 1
 	`
 
 	getLastUserLogin = `
 	SELECT last_login FROM users
-	WHERE userid = $1
+	WHERE user_id = $1
+	`
+
+	userExists = `
+	SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)
+	`
+
+	insertBan = `
+	INSERT INTO user_bans(user_id, banned_by, reason, expires_at)
+	VALUES ($1, NULLIF($2, 0), $3, $4)
+	RETURNING id
+	`
+
+	// user_bans has no tenant, users of other tenants are hidden from subquery by row level security
+	liftActiveBans = `
+	UPDATE user_bans
+	SET unbanned_at = NOW(), unbanned_by = NULLIF($2, 0), unban_reason = NULLIF($3, '')
+	WHERE user_id = $1 AND unbanned_at IS NULL
+	AND (expires_at IS NULL OR expires_at > NOW())
+	AND user_id IN (SELECT user_id FROM users)
+	`
+
+	unlockAccount = `
+	UPDATE users SET account_locked = FALSE
+	WHERE user_id = $1 AND account_locked
+	`
+
+	deleteUserRefreshTokens = `
+	DELETE FROM refresh_tokens
+	WHERE user_id = $1
+	`
+
+	revokeUserTokens = `
+	UPDATE users SET tokens_revoked_at = NOW()
+	WHERE user_id = $1
+	`
+
+	getTokensRevokedAt = `
+	SELECT tokens_revoked_at FROM users
+	WHERE user_id = $1
+	`
+
+	getLoginHistory = `
+	SELECT id, success, reason, COALESCE(ip, ''), COALESCE(user_agent, ''), COALESCE(app_id, 0), created_at
+	FROM login_history
+	WHERE user_id = $1 AND user_id IN (SELECT user_id FROM users)
+	AND ($2::timestamptz IS NULL OR created_at >= $2)
+	AND ($3::timestamptz IS NULL OR created_at < $3)
+	AND ($4::bigint = 0 OR id < $4)
+	ORDER BY id DESC
+	LIMIT $5
+	`
+
+	// roles inherited through groups are listed with name of group which role is bound to,
+	// empty group means role is granted to user directly
+	getUserRoles = `
+	WITH RECURSIVE user_groups(group_id) AS (
+		SELECT group_id FROM group_members WHERE user_id = $1
+		UNION
+		SELECT g.parent_id FROM groups g
+		JOIN user_groups ug ON g.group_id = ug.group_id
+		WHERE g.parent_id IS NOT NULL
+	),
+	granted(app_id, role_id, group_name) AS (
+		SELECT app_id, role_id, '' FROM user_roles WHERE user_id = $1
+		UNION
+		SELECT gr.app_id, gr.role_id, g.name FROM group_roles gr
+		JOIN user_groups ug ON gr.group_id = ug.group_id
+		JOIN groups g ON gr.group_id = g.group_id
+	)
+	SELECT gt.app_id, r.role_name, COALESCE(r.role_description, ''), gt.group_name,
+	COALESCE(ARRAY_AGG(p.perm_name ORDER BY p.perm_name) FILTER (WHERE rp.effect = 'allow'), '{}'),
+	COALESCE(ARRAY_AGG(p.perm_name ORDER BY p.perm_name) FILTER (WHERE rp.effect = 'deny'), '{}')
+	FROM granted gt
+	JOIN roles r ON gt.role_id = r.role_id
+	LEFT JOIN role_permissions rp ON r.role_id = rp.role_id
+	LEFT JOIN permissions p ON rp.permission_id = p.id
+	GROUP BY gt.app_id, gt.group_name, r.role_id, r.role_name, r.role_description
+	ORDER BY gt.app_id, r.role_name, gt.group_name
 	`
 )
@@ -2,14 +2,12 @@ package statrepo
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/opentracing/opentracing-go"
 
 	"sso/internal/domain/models"
 	"sso/internal/dto"
@@ -20,25 +18,16 @@ type StatRepository struct {
 	db *pgxpool.Pool
 }
 
-func New(dsn string) (*StatRepository, error) {
-	const op = "usecase.stat_repo.New"
-
-	db, err := pgxpool.New(context.Background(), dsn)
-	if err != nil {
-		return nil, fmt.Errorf("%s:%w", op, err)
-	}
-
+// New creates repository on top of shared connection pool
+func New(db *pgxpool.Pool) *StatRepository {
 	return &StatRepository{
 		db: db,
-	}, nil
+	}
 }
 
 func (s *StatRepository) GetConn(ctx context.Context) (*pgxpool.Conn, error) {
 	const op = "usecase.stat_repo.GetConn"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
 	conn, err := s.db.Acquire(ctx)
 	if err != nil {
 
@@ -51,57 +40,154 @@ var (
 	zeroTimeValue time.Time
 )
 
-func (s *StatRepository) IsUsrBanned(ctx context.Context, userID int64) (dto.IsBannedRespStruct, error) {
+func (s *StatRepository) IsUsrBanned(ctx context.Context, userID int64) (*dto.IsBannedRespStruct, error) {
 	const op = "usecase.stat_repo.IsUsrBanned"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
 	conn, err := s.GetConn(ctx)
 	if err != nil {
 
-		return dto.IsBannedRespStruct{}, fmt.Errorf("%s:%w", op, err)
+		return nil, fmt.Errorf("%s:%w", op, err)
 	}
 
+	defer conn.Release()
+
 	row := conn.QueryRow(ctx, getIsUsrBanned, userID)
 
-	var user models.User
+	var (
+		user      models.User
+		banned    bool
+		reason    string
+		expiresAt *time.Time
+	)
+
+	err = row.Scan(&user.Account_locked, &banned, &reason, &expiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
+		}
+
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	resp := &dto.IsBannedRespStruct{
+		// active ban is told by its row, reason text may be empty
+		IsBanned: user.Account_locked || banned,
+		Reason:   reason,
+	}
+	if expiresAt != nil {
+		resp.ExpiresAt = *expiresAt
+	}
+
+	return resp, nil
+}
+
+// BanUsr saves ban to history and revokes all tokens of user in one transaction
+func (s *StatRepository) BanUsr(ctx context.Context, ban models.Ban) (int64, error) {
+	const op = "usecase.stat_repo.BanUsr"
+
+	var expiresAt *time.Time
+	if !ban.ExpiresAt.IsZero() {
+		expiresAt = &ban.ExpiresAt
+	}
+
+	var banID int64
+
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		var exists bool
+		if err := tx.QueryRow(ctx, userExists, ban.UserID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return storage.ErrUserNotFound
+		}
+
+		if err := tx.QueryRow(ctx, insertBan, ban.UserID, ban.BannedBy, ban.Reason, expiresAt).Scan(&banID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, deleteUserRefreshTokens, ban.UserID); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx, revokeUserTokens, ban.UserID)
+
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return banID, nil
+}
+
+// TokensRevokedAt returns moment when all tokens of user were revoked, zero when they never were
+func (s *StatRepository) TokensRevokedAt(ctx context.Context, userID int64) (time.Time, error) {
+	const op = "usecase.stat_repo.TokensRevokedAt"
 
-	err = row.Scan(&user.Account_locked)
+	var revokedAt *time.Time
+
+	err := s.db.QueryRow(ctx, getTokensRevokedAt, userID).Scan(&revokedAt)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return dto.IsBannedRespStruct{}, fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return zeroTimeValue, fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
 		}
 
-		return dto.IsBannedRespStruct{}, fmt.Errorf("%s:%w", op, err)
+		return zeroTimeValue, fmt.Errorf("%s:%w", op, err)
+	}
+
+	if revokedAt == nil {
+		return zeroTimeValue, nil
 	}
 
-	return dto.IsBannedRespStruct{
-		IsBanned: user.Account_locked,
-		Message:  "account status has been checked",
-	}, nil
+	return *revokedAt, nil
+}
+
+// UnbanUsr lifts every active ban of user and unlocks his account
+func (s *StatRepository) UnbanUsr(ctx context.Context, userID int64, unbannedBy int64, reason string) error {
+	const op = "usecase.stat_repo.UnbanUsr"
+
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		lifted, err := tx.Exec(ctx, liftActiveBans, userID, unbannedBy, reason)
+		if err != nil {
+			return err
+		}
+
+		unlocked, err := tx.Exec(ctx, unlockAccount, userID)
+		if err != nil {
+			return err
+		}
+
+		if lifted.RowsAffected() == 0 && unlocked.RowsAffected() == 0 {
+			return storage.ErrUserNotBanned
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
 
+	return nil
 }
 
 func (s *StatRepository) LastUsrLogin(ctx context.Context, userID int64) (time.Time, error) {
 	const op = "usecase.stat_repo.LastUsrLogin"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
 	conn, err := s.GetConn(ctx)
 	if err != nil {
 
 		return zeroTimeValue, fmt.Errorf("%s:%w", op, err)
 	}
 
+	defer conn.Release()
+
 	row := conn.QueryRow(ctx, getLastUserLogin, userID)
 
-	var user models.User
+	var lastLogin *time.Time
 
-	err = row.Scan(&user.Last_login)
+	err = row.Scan(&lastLogin)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, pgx.ErrNoRows) {
 
 			return zeroTimeValue, fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
 		}
@@ -109,45 +195,154 @@ func (s *StatRepository) LastUsrLogin(ctx context.Context, userID int64) (time.T
 		return zeroTimeValue, fmt.Errorf("%s:%w", op, err)
 	}
 
-	return user.Last_login, nil
+	if lastLogin == nil {
+
+		return zeroTimeValue, nil
+	}
+
+	return *lastLogin, nil
 
 }
 
-func (s *StatRepository) CurrentUsrRole(ctx context.Context, userID int64) (dto.CurrentRoleRespStruct, error) {
+// CurrentUsrRole returns username and the first role of user, use UserRoles to get all of them
+func (s *StatRepository) CurrentUsrRole(ctx context.Context, userID int64) (*dto.CurrentRoleRespStruct, error) {
 	const op = "usecase.stat_repo.CurrentUsrRole"
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
-	defer span.Finish()
-
 	conn, err := s.GetConn(ctx)
 	if err != nil {
 
-		return dto.CurrentRoleRespStruct{}, fmt.Errorf("%s:%w", op, err)
+		return nil, fmt.Errorf("%s:%w", op, err)
 	}
 
-	rows, err := conn.Query(ctx, getRolesByID, userID)
+	defer conn.Release()
 
 	var currentUsrRole dto.CurrentRoleRespStruct
 
-	_, err = pgx.ForEachRow(rows, []any{&currentUsrRole}, func() error {
-		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-
-				return fmt.Errorf("%s:%w", op, storage.ErrAppNotFound)
-			}
+	err = conn.QueryRow(ctx, getRolesByID, userID).Scan(&currentUsrRole.Username, &currentUsrRole.Role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
 
-			return fmt.Errorf("%s:%w", op, err)
-		}
-		currentUsrRole = dto.CurrentRoleRespStruct{
-			Username: currentUsrRole.Username,
-			Role:     currentUsrRole.Role,
+			return nil, fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
 		}
+
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return &currentUsrRole, nil
+}
+
+// LoginHistory returns page of login attempts of user, newest first
+func (s *StatRepository) LoginHistory(ctx context.Context, filter dto.LoginHistoryFilter) ([]models.LoginAttempt, error) {
+	const op = "usecase.stat_repo.LoginHistory"
+
+	conn, err := s.GetConn(ctx)
+	if err != nil {
+
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, getLoginHistory,
+		filter.UserID,
+		nullTime(filter.From),
+		nullTime(filter.To),
+		filter.BeforeID,
+		filter.PageSize,
+	)
+	if err != nil {
+
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	defer rows.Close()
+
+	var (
+		attempt  models.LoginAttempt
+		attempts []models.LoginAttempt
+	)
+	attempt.UserID = filter.UserID
+
+	_, err = pgx.ForEachRow(rows, []any{
+		&attempt.ID,
+		&attempt.Success,
+		&attempt.Reason,
+		&attempt.IP,
+		&attempt.UserAgent,
+		&attempt.AppID,
+		&attempt.CreatedAt,
+	}, func() error {
+		attempts = append(attempts, attempt)
+
+		return nil
+	})
+	if err != nil {
+
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return attempts, nil
+}
+
+// nullTime turns zero time into SQL NULL
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// UserRoles returns every role bound to user with its allowed and denied permissions
+func (s *StatRepository) UserRoles(ctx context.Context, userID int64) ([]dto.UserRole, error) {
+	const op = "usecase.stat_repo.UserRoles"
+
+	conn, err := s.GetConn(ctx)
+	if err != nil {
+
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	defer conn.Release()
+
+	var exists bool
+	if err := conn.QueryRow(ctx, userExists, userID).Scan(&exists); err != nil {
+
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	if !exists {
+
+		return nil, fmt.Errorf("%s:%w", op, storage.ErrUserNotFound)
+	}
+
+	rows, err := conn.Query(ctx, getUserRoles, userID)
+	if err != nil {
+
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	defer rows.Close()
+
+	var (
+		role  dto.UserRole
+		roles []dto.UserRole
+	)
+	_, err = pgx.ForEachRow(rows, []any{
+		&role.AppID,
+		&role.Role,
+		&role.Description,
+		&role.Group,
+		&role.Permissions,
+		&role.DeniedPermissions,
+	}, func() error {
+		roles = append(roles, role)
+
 		return nil
 	})
+	if err != nil {
 
-	return dto.CurrentRoleRespStruct{
-		Username: currentUsrRole.Username,
-		Role:     currentUsrRole.Role,
-	}, nil
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
 
+	return roles, nil
 }
@@ -0,0 +1,15 @@
+package tenantrepo
+
+const (
+	insertTenant = `
+	INSERT INTO tenants(name)
+	VALUES ($1)
+	RETURNING tenant_id, created_at
+	`
+
+	selectTenants = `
+	SELECT tenant_id, name, created_at
+	FROM tenants
+	ORDER BY tenant_id
+	`
+)
@@ -0,0 +1,66 @@
+package tenantrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TenantRepository manages organizations, tenants table itself is not scoped to tenant
+type TenantRepository struct {
+	db *pgxpool.Pool
+}
+
+// New creates repository on top of shared connection pool
+func New(db *pgxpool.Pool) *TenantRepository {
+	return &TenantRepository{
+		db: db,
+	}
+}
+
+func (t *TenantRepository) CreateTenant(ctx context.Context, name string) (models.Tenant, error) {
+	const op = "tenant_repository.CreateTenant"
+
+	tenant := models.Tenant{Name: name}
+
+	err := t.db.QueryRow(ctx, insertTenant, name).Scan(&tenant.ID, &tenant.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+
+			return models.Tenant{}, fmt.Errorf("%s:%w", op, storage.ErrTenantExists)
+		}
+
+		return models.Tenant{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return tenant, nil
+}
+
+func (t *TenantRepository) Tenants(ctx context.Context) ([]models.Tenant, error) {
+	const op = "tenant_repository.Tenants"
+
+	rows, err := t.db.Query(ctx, selectTenants)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	tenants, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Tenant, error) {
+		var tenant models.Tenant
+		err := row.Scan(&tenant.ID, &tenant.Name, &tenant.CreatedAt)
+
+		return tenant, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return tenants, nil
+}
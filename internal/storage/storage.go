@@ -8,4 +8,14 @@ var (
 	ErrAppNotFound        = errors.New("app not found")
 	ErrDoesntAllowed      = errors.New("doesnt allowed for this role")
 	ErrInvalidCredentials = errors.New("error invalid credentials")
+	ErrUserNotBanned      = errors.New("user is not banned")
+	ErrRoleNotFound       = errors.New("role not found")
+	ErrAppExists          = errors.New("app already exists")
+	ErrKeyNotFound        = errors.New("signing key not found")
+	ErrTenantExists       = errors.New("tenant already exists")
+	ErrGroupNotFound      = errors.New("group not found")
+	ErrGroupExists        = errors.New("group already exists")
+	ErrGroupCycle         = errors.New("group cannot be nested into itself")
+	ErrGroupHasSubgroups  = errors.New("group has subgroups")
+	ErrEmailChangeMissing = errors.New("email change not requested")
 )
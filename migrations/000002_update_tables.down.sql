@@ -1,3 +1,3 @@
-DROP TABLE IF EXISTS app;
+DROP TABLE IF EXISTS apps;
 
-DROP INDEX IF EXISTS idx_users_email;
\ No newline at end of file
+DROP INDEX IF EXISTS idx_users_email;
@@ -1,3 +1,3 @@
-DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS role_permissions;
 
-DROP TABLE IF EXISTS role_permissions;
\ No newline at end of file
+DROP TABLE IF EXISTS permission;
@@ -1,6 +1,6 @@
 CREATE TABLE IF NOT EXISTS refresh_tokens (
     id SERIAL PRIMARY KEY,
-    userid REFERENCES users(userid) ON DELETE CASCADE,
+    userid INT REFERENCES users(userid) ON DELETE CASCADE,
     token TEXT NOT NULL,
     expires_at TIMESTAMP NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
@@ -8,4 +8,4 @@ CREATE TABLE IF NOT EXISTS refresh_tokens (
 );
 
 ALTER TABLE apps
-DROP app_secret;
\ No newline at end of file
+DROP app_secret;
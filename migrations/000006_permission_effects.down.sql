@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_permission_perm_name;
+
+ALTER TABLE role_permissions
+DROP COLUMN IF EXISTS effect;
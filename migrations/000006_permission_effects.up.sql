@@ -0,0 +1,5 @@
+ALTER TABLE role_permissions
+ADD COLUMN IF NOT EXISTS effect VARCHAR(5) NOT NULL DEFAULT 'allow'
+CHECK (effect IN ('allow', 'deny'));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_permission_perm_name ON permission(perm_name);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_user_roles_app_id;
+
+DELETE FROM user_roles WHERE app_id <> 0;
+
+ALTER TABLE user_roles DROP CONSTRAINT IF EXISTS user_roles_pkey;
+ALTER TABLE user_roles ADD PRIMARY KEY (userid, role_id);
+
+ALTER TABLE user_roles
+DROP COLUMN IF EXISTS app_id;
@@ -0,0 +1,7 @@
+ALTER TABLE user_roles
+ADD COLUMN IF NOT EXISTS app_id INT NOT NULL DEFAULT 0;
+
+ALTER TABLE user_roles DROP CONSTRAINT IF EXISTS user_roles_pkey;
+ALTER TABLE user_roles ADD PRIMARY KEY (userid, role_id, app_id);
+
+CREATE INDEX IF NOT EXISTS idx_user_roles_app_id ON user_roles(app_id);
@@ -0,0 +1,6 @@
+ALTER TABLE users
+DROP COLUMN IF EXISTS tokens_revoked_at;
+
+DROP INDEX IF EXISTS idx_user_bans_userid;
+
+DROP TABLE IF EXISTS user_bans;
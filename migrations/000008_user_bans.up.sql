@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS user_bans(
+    id SERIAL PRIMARY KEY,
+    userid INT NOT NULL REFERENCES users(userid) ON DELETE CASCADE,
+    banned_by INT REFERENCES users(userid) ON DELETE SET NULL,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    unbanned_at TIMESTAMP WITH TIME ZONE,
+    unbanned_by INT REFERENCES users(userid) ON DELETE SET NULL,
+    unban_reason TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_bans_userid ON user_bans(userid);
+
+-- tokens issued before this moment are rejected, banning sets it
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS tokens_revoked_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_login_history_email;
+DROP INDEX IF EXISTS idx_login_history_userid_id;
+
+DROP TABLE IF EXISTS login_history;
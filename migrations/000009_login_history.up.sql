@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS login_history(
+    id BIGSERIAL PRIMARY KEY,
+    userid INT REFERENCES users(userid) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    app_id INT,
+    success BOOLEAN NOT NULL,
+    reason VARCHAR(64) NOT NULL,
+    ip VARCHAR(64),
+    user_agent TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_history_userid_id ON login_history(userid, id DESC);
+CREATE INDEX IF NOT EXISTS idx_login_history_email ON login_history(email);
@@ -0,0 +1,14 @@
+ALTER TABLE apps RENAME COLUMN name TO app_name;
+
+ALTER INDEX IF EXISTS idx_permissions_perm_name RENAME TO idx_permission_perm_name;
+ALTER TABLE permissions RENAME TO permission;
+
+ALTER INDEX IF EXISTS idx_login_history_user_id_id RENAME TO idx_login_history_userid_id;
+ALTER INDEX IF EXISTS idx_user_bans_user_id RENAME TO idx_user_bans_userid;
+
+ALTER TABLE login_history RENAME COLUMN user_id TO userid;
+ALTER TABLE user_bans RENAME COLUMN user_id TO userid;
+ALTER TABLE refresh_tokens RENAME COLUMN user_id TO userid;
+ALTER TABLE user_roles RENAME COLUMN user_id TO userid;
+ALTER TABLE is_admin RENAME COLUMN user_id TO userid;
+ALTER TABLE users RENAME COLUMN user_id TO userid;
@@ -0,0 +1,14 @@
+ALTER TABLE users RENAME COLUMN userid TO user_id;
+ALTER TABLE is_admin RENAME COLUMN userid TO user_id;
+ALTER TABLE user_roles RENAME COLUMN userid TO user_id;
+ALTER TABLE refresh_tokens RENAME COLUMN userid TO user_id;
+ALTER TABLE user_bans RENAME COLUMN userid TO user_id;
+ALTER TABLE login_history RENAME COLUMN userid TO user_id;
+
+ALTER INDEX IF EXISTS idx_user_bans_userid RENAME TO idx_user_bans_user_id;
+ALTER INDEX IF EXISTS idx_login_history_userid_id RENAME TO idx_login_history_user_id_id;
+
+ALTER TABLE permission RENAME TO permissions;
+ALTER INDEX IF EXISTS idx_permission_perm_name RENAME TO idx_permissions_perm_name;
+
+ALTER TABLE apps RENAME COLUMN app_name TO name;
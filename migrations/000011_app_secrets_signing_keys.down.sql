@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_signing_keys_active;
+
+DROP TABLE IF EXISTS signing_keys;
+
+ALTER TABLE apps
+DROP COLUMN IF EXISTS secret;
@@ -0,0 +1,12 @@
+ALTER TABLE apps
+ADD COLUMN IF NOT EXISTS secret TEXT;
+
+CREATE TABLE IF NOT EXISTS signing_keys(
+    kid VARCHAR(64) PRIMARY KEY,
+    secret TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    retired_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_signing_keys_active ON signing_keys(active) WHERE active;
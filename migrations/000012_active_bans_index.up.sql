@@ -0,0 +1,2 @@
+-- introspection and the auth interceptor look up active bans of user on every call
+CREATE INDEX IF NOT EXISTS idx_user_bans_active ON user_bans(user_id) WHERE unbanned_at IS NULL;
@@ -0,0 +1,11 @@
+ALTER TABLE refresh_tokens
+DROP COLUMN IF EXISTS idle_expires_at,
+DROP COLUMN IF EXISTS app_id;
+
+ALTER TABLE apps
+DROP COLUMN IF EXISTS signing_kid,
+DROP COLUMN IF EXISTS audience,
+DROP COLUMN IF EXISTS grant_types,
+DROP COLUMN IF EXISTS idle_timeout,
+DROP COLUMN IF EXISTS refresh_ttl,
+DROP COLUMN IF EXISTS access_ttl;
@@ -0,0 +1,11 @@
+ALTER TABLE apps
+ADD COLUMN IF NOT EXISTS access_ttl INTERVAL,
+ADD COLUMN IF NOT EXISTS refresh_ttl INTERVAL,
+ADD COLUMN IF NOT EXISTS idle_timeout INTERVAL,
+ADD COLUMN IF NOT EXISTS grant_types TEXT[] NOT NULL DEFAULT '{}',
+ADD COLUMN IF NOT EXISTS audience TEXT,
+ADD COLUMN IF NOT EXISTS signing_kid VARCHAR(64) REFERENCES signing_keys(kid) ON DELETE SET NULL;
+
+ALTER TABLE refresh_tokens
+ADD COLUMN IF NOT EXISTS app_id INT,
+ADD COLUMN IF NOT EXISTS idle_expires_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,14 @@
+-- hashed secrets cannot be restored, apps need new secrets after rollback
+ALTER TABLE apps
+ADD COLUMN IF NOT EXISTS secret TEXT;
+
+DROP TABLE IF EXISTS app_secrets;
+
+ALTER TABLE apps
+DROP COLUMN IF EXISTS updated_at,
+DROP COLUMN IF EXISTS created_at,
+DROP COLUMN IF EXISTS disabled,
+DROP COLUMN IF EXISTS allowed_origins,
+DROP COLUMN IF EXISTS redirect_uris,
+DROP COLUMN IF EXISTS logo_url,
+DROP COLUMN IF EXISTS description;
@@ -0,0 +1,27 @@
+ALTER TABLE apps
+ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS logo_url TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS redirect_uris TEXT[] NOT NULL DEFAULT '{}',
+ADD COLUMN IF NOT EXISTS allowed_origins TEXT[] NOT NULL DEFAULT '{}',
+ADD COLUMN IF NOT EXISTS disabled BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS app_secrets(
+    id SERIAL PRIMARY KEY,
+    app_id INT NOT NULL REFERENCES apps(app_id) ON DELETE CASCADE,
+    secret_hash TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_app_secrets_app_id ON app_secrets(app_id);
+
+-- plain secrets are replaced by their sha256, apps keep using the same secrets
+INSERT INTO app_secrets(app_id, secret_hash)
+SELECT app_id, encode(sha256(convert_to(secret, 'UTF8')), 'hex')
+FROM apps
+WHERE secret IS NOT NULL AND secret <> '';
+
+ALTER TABLE apps
+DROP COLUMN IF EXISTS secret;
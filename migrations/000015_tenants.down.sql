@@ -0,0 +1,38 @@
+DROP POLICY IF EXISTS tenant_isolation ON permissions;
+DROP POLICY IF EXISTS tenant_isolation ON roles;
+DROP POLICY IF EXISTS tenant_isolation ON apps;
+DROP POLICY IF EXISTS tenant_isolation ON users;
+
+ALTER TABLE permissions NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE roles NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE apps NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE users NO FORCE ROW LEVEL SECURITY;
+
+ALTER TABLE permissions DISABLE ROW LEVEL SECURITY;
+ALTER TABLE roles DISABLE ROW LEVEL SECURITY;
+ALTER TABLE apps DISABLE ROW LEVEL SECURITY;
+ALTER TABLE users DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION IF EXISTS sso_tenant_visible(INT);
+
+DROP SEQUENCE IF EXISTS apps_app_id_seq;
+
+DROP INDEX IF EXISTS idx_permissions_tenant_perm_name;
+DROP INDEX IF EXISTS idx_apps_tenant_name;
+DROP INDEX IF EXISTS idx_roles_tenant_role_name;
+DROP INDEX IF EXISTS idx_users_tenant_username;
+DROP INDEX IF EXISTS idx_users_tenant_email;
+
+-- fails when several tenants have the same names, they have to be renamed before rollback
+CREATE UNIQUE INDEX IF NOT EXISTS idx_permissions_perm_name ON permissions(perm_name);
+ALTER TABLE apps ADD CONSTRAINT apps_app_name_key UNIQUE (name);
+ALTER TABLE roles ADD CONSTRAINT roles_role_name_key UNIQUE (role_name);
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+
+ALTER TABLE permissions DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE roles DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE apps DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS tenants;
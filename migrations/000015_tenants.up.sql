@@ -0,0 +1,63 @@
+CREATE TABLE IF NOT EXISTS tenants(
+    tenant_id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO tenants(tenant_id, name) VALUES (1, 'default') ON CONFLICT DO NOTHING;
+SELECT setval(pg_get_serial_sequence('tenants', 'tenant_id'), (SELECT MAX(tenant_id) FROM tenants));
+
+-- existing rows belong to default tenant, new rows have to name their tenant
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(tenant_id);
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(tenant_id);
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(tenant_id);
+ALTER TABLE permissions ADD COLUMN IF NOT EXISTS tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(tenant_id);
+
+ALTER TABLE users ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE apps ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE roles ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE permissions ALTER COLUMN tenant_id DROP DEFAULT;
+
+-- names are unique inside tenant only
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+ALTER TABLE roles DROP CONSTRAINT IF EXISTS roles_role_name_key;
+ALTER TABLE apps DROP CONSTRAINT IF EXISTS apps_app_name_key;
+DROP INDEX IF EXISTS idx_permissions_perm_name;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_tenant_email ON users(tenant_id, email);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_tenant_username ON users(tenant_id, username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_tenant_role_name ON roles(tenant_id, role_name);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_apps_tenant_name ON apps(tenant_id, name);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_permissions_tenant_perm_name ON permissions(tenant_id, perm_name);
+
+-- app ids are global, MAX(app_id) + 1 would see only apps of one tenant
+CREATE SEQUENCE IF NOT EXISTS apps_app_id_seq OWNED BY apps.app_id;
+SELECT setval('apps_app_id_seq', COALESCE((SELECT MAX(app_id) FROM apps), 0) + 1, false);
+
+-- sso.tenant_id is set by service for every connection: tenant id, 'all' for maintenance
+-- and empty when tenant is unknown, then no rows are visible
+CREATE OR REPLACE FUNCTION sso_tenant_visible(row_tenant INT) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT CASE COALESCE(current_setting('sso.tenant_id', true), '')
+        WHEN '' THEN FALSE
+        WHEN 'all' THEN TRUE
+        ELSE row_tenant = current_setting('sso.tenant_id', true)::INT
+    END
+$$;
+
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+ALTER TABLE apps ENABLE ROW LEVEL SECURITY;
+ALTER TABLE roles ENABLE ROW LEVEL SECURITY;
+ALTER TABLE permissions ENABLE ROW LEVEL SECURITY;
+
+-- service connects as owner of tables, owner skips policies unless they are forced
+ALTER TABLE users FORCE ROW LEVEL SECURITY;
+ALTER TABLE apps FORCE ROW LEVEL SECURITY;
+ALTER TABLE roles FORCE ROW LEVEL SECURITY;
+ALTER TABLE permissions FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON users USING (sso_tenant_visible(tenant_id));
+CREATE POLICY tenant_isolation ON apps USING (sso_tenant_visible(tenant_id));
+CREATE POLICY tenant_isolation ON roles USING (sso_tenant_visible(tenant_id));
+CREATE POLICY tenant_isolation ON permissions USING (sso_tenant_visible(tenant_id));
@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS groups(
+    group_id SERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL REFERENCES tenants(tenant_id),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    -- members of group inherit roles of its ancestors, group with subgroups cannot be deleted
+    parent_id INT REFERENCES groups(group_id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_groups_tenant_name ON groups(tenant_id, name);
+CREATE INDEX IF NOT EXISTS idx_groups_parent_id ON groups(parent_id);
+
+CREATE TABLE IF NOT EXISTS group_members(
+    group_id INT NOT NULL REFERENCES groups(group_id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_members_user_id ON group_members(user_id);
+
+-- app_id 0 binds role in every app as in user_roles
+CREATE TABLE IF NOT EXISTS group_roles(
+    group_id INT NOT NULL REFERENCES groups(group_id) ON DELETE CASCADE,
+    role_id INT NOT NULL REFERENCES roles(role_id) ON DELETE CASCADE,
+    app_id INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (group_id, role_id, app_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_roles_role_id ON group_roles(role_id);
+
+ALTER TABLE groups ENABLE ROW LEVEL SECURITY;
+ALTER TABLE groups FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON groups USING (sso_tenant_visible(tenant_id));
@@ -0,0 +1,8 @@
+-- new address of user waits here until user confirms it with code sent to that address
+CREATE TABLE IF NOT EXISTS email_changes(
+    user_id INT PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    new_email VARCHAR(255) NOT NULL,
+    code_hash TEXT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,20 @@
+DROP POLICY IF EXISTS tenant_isolation ON group_roles;
+DROP POLICY IF EXISTS tenant_isolation ON group_members;
+DROP POLICY IF EXISTS tenant_isolation ON role_permissions;
+DROP POLICY IF EXISTS tenant_isolation ON user_roles;
+DROP POLICY IF EXISTS tenant_isolation ON email_changes;
+DROP POLICY IF EXISTS tenant_isolation ON user_bans;
+
+ALTER TABLE group_roles NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE group_members NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE role_permissions NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE user_roles NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE email_changes NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE user_bans NO FORCE ROW LEVEL SECURITY;
+
+ALTER TABLE group_roles DISABLE ROW LEVEL SECURITY;
+ALTER TABLE group_members DISABLE ROW LEVEL SECURITY;
+ALTER TABLE role_permissions DISABLE ROW LEVEL SECURITY;
+ALTER TABLE user_roles DISABLE ROW LEVEL SECURITY;
+ALTER TABLE email_changes DISABLE ROW LEVEL SECURITY;
+ALTER TABLE user_bans DISABLE ROW LEVEL SECURITY;
@@ -0,0 +1,36 @@
+-- tables below have no tenant_id, a row belongs to tenant of the users, roles, permissions and groups
+-- it links. Subqueries of policies are filtered by policies of those tables, so a row is visible
+-- only when everything it links is visible, and a link across tenants cannot be inserted.
+-- login_history, refresh_tokens and is_admin stay without policies: they are read and written only
+-- by user id of user which repository has already loaded in tenant of request
+ALTER TABLE user_bans ENABLE ROW LEVEL SECURITY;
+ALTER TABLE email_changes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE user_roles ENABLE ROW LEVEL SECURITY;
+ALTER TABLE role_permissions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE group_members ENABLE ROW LEVEL SECURITY;
+ALTER TABLE group_roles ENABLE ROW LEVEL SECURITY;
+
+ALTER TABLE user_bans FORCE ROW LEVEL SECURITY;
+ALTER TABLE email_changes FORCE ROW LEVEL SECURITY;
+ALTER TABLE user_roles FORCE ROW LEVEL SECURITY;
+ALTER TABLE role_permissions FORCE ROW LEVEL SECURITY;
+ALTER TABLE group_members FORCE ROW LEVEL SECURITY;
+ALTER TABLE group_roles FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON user_bans
+USING (user_id IN (SELECT user_id FROM users));
+
+CREATE POLICY tenant_isolation ON email_changes
+USING (user_id IN (SELECT user_id FROM users));
+
+CREATE POLICY tenant_isolation ON user_roles
+USING (user_id IN (SELECT user_id FROM users) AND role_id IN (SELECT role_id FROM roles));
+
+CREATE POLICY tenant_isolation ON role_permissions
+USING (role_id IN (SELECT role_id FROM roles) AND permission_id IN (SELECT id FROM permissions));
+
+CREATE POLICY tenant_isolation ON group_members
+USING (group_id IN (SELECT group_id FROM groups) AND user_id IN (SELECT user_id FROM users));
+
+CREATE POLICY tenant_isolation ON group_roles
+USING (group_id IN (SELECT group_id FROM groups) AND role_id IN (SELECT role_id FROM roles));
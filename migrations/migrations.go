@@ -0,0 +1,7 @@
+// Package migrations embeds sql migrations of sso database
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,132 @@
+package ssoclient
+
+import (
+	"context"
+	"time"
+
+	"sso/proto/generated/augen"
+)
+
+// Register creates user in default tenant and returns its id, it is not retried because it is not idempotent
+func (c *Client) Register(ctx context.Context, username, email, password string) (int64, error) {
+	return c.RegisterInApp(ctx, 0, username, email, password)
+}
+
+// RegisterInApp creates user in tenant of app and returns its id
+func (c *Client) RegisterInApp(ctx context.Context, appID uint64, username, email, password string) (int64, error) {
+	resp, err := c.auth.Register(ctx, &augen.RegisterRequest{
+		Username: username,
+		Email:    email,
+		Password: password,
+		AppId:    appID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.GetUserId(), nil
+}
+
+// Login returns access token of user in app, it does not touch token cached by client.
+// Scopes are permission names, token carries only those which user is allowed
+func (c *Client) Login(ctx context.Context, email, password string, appID uint64, scopes ...string) (Token, error) {
+	resp, err := c.auth.Login(ctx, &augen.LoginRequest{
+		Email:    email,
+		Password: password,
+		AppId:    appID,
+		Scopes:   scopes,
+	})
+	if err != nil {
+		return Token{}, err
+	}
+
+	return ParseToken(resp.GetToken())
+}
+
+// IsAdmin is called by backend apps, so it needs WithAppCredentials
+func (c *Client) IsAdmin(ctx context.Context, userID int64) (bool, error) {
+	ctx, err := c.appContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var isAdmin bool
+	err = c.retry.do(ctx, func(ctx context.Context) error {
+		resp, err := c.auth.IsAdmin(ctx, &augen.IsAdminRequest{UserId: userID})
+		if err != nil {
+			return err
+		}
+		isAdmin = resp.GetIsAdmin()
+
+		return nil
+	})
+
+	return isAdmin, err
+}
+
+// Introspection is state of token reported by sso, other fields are empty when token is not active
+type Introspection struct {
+	Active bool
+	// TokenType is type of active token, it is always access
+	TokenType string
+	UserID    int64
+	AppID     uint64
+	// TenantID is tenant of user and app, apps see only tokens of their own tenant
+	TenantID int64
+	Email    string
+	Scopes   []string
+	// Roles and Permissions are empty when token does not carry them
+	Roles       []string
+	Permissions []string
+	ID          string
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+}
+
+// Introspect asks sso whether token is still active, unlike local verification it sees bans
+// and revocations, app credentials are required
+func (c *Client) Introspect(ctx context.Context, token string) (Introspection, error) {
+	ctx, err := c.appContext(ctx)
+	if err != nil {
+		return Introspection{}, err
+	}
+
+	var result Introspection
+	err = c.retry.do(ctx, func(ctx context.Context) error {
+		resp, err := c.auth.Introspect(ctx, &augen.IntrospectRequest{Token: token})
+		if err != nil {
+			return err
+		}
+		result = Introspection{
+			Active:      resp.GetActive(),
+			TokenType:   resp.GetTokenType(),
+			UserID:      resp.GetSub(),
+			AppID:       resp.GetAppId(),
+			TenantID:    resp.GetTenantId(),
+			Email:       resp.GetEmail(),
+			Scopes:      resp.GetScopes(),
+			Roles:       resp.GetRoles(),
+			Permissions: resp.GetPermissions(),
+			ID:          resp.GetJti(),
+		}
+		if resp.GetIat() != 0 {
+			result.IssuedAt = time.Unix(resp.GetIat(), 0)
+		}
+		if resp.GetExp() != 0 {
+			result.ExpiresAt = time.Unix(resp.GetExp(), 0)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// Token returns token of configured user, it is cached until shortly before exp
+func (c *Client) Token(ctx context.Context) (Token, error) {
+	if c.tokens == nil {
+		return Token{}, ErrNoCredentials
+	}
+
+	return c.tokens.Token(ctx)
+}
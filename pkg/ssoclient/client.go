@@ -0,0 +1,204 @@
+// Package ssoclient is Go client of sso gRPC services.
+//
+// It wraps generated Auth, Permissions, Status and Profile stubs with typed methods,
+// keeps user token in memory and logs in again shortly before the token expires.
+package ssoclient
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"sso/proto/generated/augen"
+	"sso/proto/generated/permgen"
+	"sso/proto/generated/profgen"
+	"sso/proto/generated/stagen"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+var (
+	ErrNoCredentials    = errors.New("ssoclient: user credentials are not configured")
+	ErrNoAppCredentials = errors.New("ssoclient: app credentials are not configured")
+)
+
+type Client struct {
+	conn    *grpc.ClientConn
+	auth    augen.AuthClient
+	perms   permgen.PermissionsClient
+	status  stagen.StatusClient
+	profile profgen.ProfileClient
+
+	tokens    *TokenSource
+	retry     RetryPolicy
+	appID     int64
+	appSecret string
+}
+
+type options struct {
+	tlsConfig     *tls.Config
+	insecure      bool
+	dialOptions   []grpc.DialOption
+	retry         RetryPolicy
+	email         string
+	password      string
+	loginAppID    uint64
+	scopes        []string
+	refreshBefore time.Duration
+	appID         int64
+	appSecret     string
+}
+
+type Option func(*options)
+
+// WithTLS sets TLS of connection, system roots are used by default
+func WithTLS(cfg *tls.Config) Option {
+	return func(o *options) { o.tlsConfig = cfg }
+}
+
+// WithInsecure dials without TLS, tokens are sent in cleartext so use it only for local runs
+func WithInsecure() Option {
+	return func(o *options) { o.insecure = true }
+}
+
+// WithDialOptions appends options of grpc.NewClient, e.g. interceptors or stats handlers
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) { o.dialOptions = append(o.dialOptions, opts...) }
+}
+
+// WithRetry replaces DefaultRetryPolicy of idempotent calls
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *options) { o.retry = policy }
+}
+
+// WithCredentials makes client log in as user and attach token to calls which need it
+func WithCredentials(email, password string, appID uint64) Option {
+	return func(o *options) {
+		o.email = email
+		o.password = password
+		o.loginAppID = appID
+	}
+}
+
+// WithScopes sets scopes which are requested at login of WithCredentials user
+func WithScopes(scopes ...string) Option {
+	return func(o *options) { o.scopes = scopes }
+}
+
+// WithRefreshBefore sets how long before exp cached token is replaced, one minute by default
+func WithRefreshBefore(d time.Duration) Option {
+	return func(o *options) { o.refreshBefore = d }
+}
+
+// WithAppCredentials is used by calls which backend apps make on their own behalf, e.g. IsAdmin
+func WithAppCredentials(appID int64, secret string) Option {
+	return func(o *options) {
+		o.appID = appID
+		o.appSecret = secret
+	}
+}
+
+// New creates client of sso at addr, connection is established lazily on first call
+func New(addr string, opts ...Option) (*Client, error) {
+	const op = "ssoclient.New"
+
+	o := options{
+		retry:         DefaultRetryPolicy,
+		refreshBefore: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	creds := credentials.NewTLS(o.tlsConfig)
+	if o.insecure {
+		creds = insecure.NewCredentials()
+	}
+
+	dialOptions := append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, o.dialOptions...)
+
+	conn, err := grpc.NewClient(addr, dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	c := &Client{
+		conn:      conn,
+		auth:      augen.NewAuthClient(conn),
+		perms:     permgen.NewPermissionsClient(conn),
+		status:    stagen.NewStatusClient(conn),
+		profile:   profgen.NewProfileClient(conn),
+		retry:     o.retry,
+		appID:     o.appID,
+		appSecret: o.appSecret,
+	}
+
+	if o.email != "" {
+		c.tokens = NewTokenSource(func(ctx context.Context) (string, error) {
+			token, err := c.Login(ctx, o.email, o.password, o.loginAppID, o.scopes...)
+
+			return token.Value, err
+		}, o.refreshBefore)
+	}
+
+	return c, nil
+}
+
+// Close closes connection, client cannot be used after it
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// PerRPCCredentials attaches token of configured user, it can be used for other connections to sso.
+// Returns nil when client has no user credentials
+func (c *Client) PerRPCCredentials() *TokenCredentials {
+	if c.tokens == nil {
+		return nil
+	}
+
+	return NewTokenCredentials(c.tokens, true)
+}
+
+// userCall runs call with token of configured user, retrying when call is idempotent.
+// Rejected token is dropped from cache and call is repeated once with new token
+func (c *Client) userCall(ctx context.Context, idempotent bool, call func(ctx context.Context, opts ...grpc.CallOption) error) error {
+	if c.tokens == nil {
+		return ErrNoCredentials
+	}
+
+	creds := grpc.PerRPCCredentials(NewTokenCredentials(c.tokens, false))
+
+	run := func(ctx context.Context) error {
+		err := call(ctx, creds)
+		if isUnauthenticated(err) {
+			c.tokens.Invalidate()
+			err = call(ctx, creds)
+		}
+
+		return err
+	}
+
+	if !idempotent {
+		return run(ctx)
+	}
+
+	return c.retry.do(ctx, run)
+}
+
+// appContext adds app credentials checked by interceptor of sso
+func (c *Client) appContext(ctx context.Context) (context.Context, error) {
+	if c.appSecret == "" {
+		return nil, ErrNoAppCredentials
+	}
+
+	return metadata.AppendToOutgoingContext(ctx,
+		"x-app-id", strconv.FormatInt(c.appID, 10),
+		"x-app-secret", c.appSecret,
+	), nil
+}
@@ -0,0 +1,236 @@
+package ssoclient
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"sso/proto/generated/augen"
+	"sso/proto/generated/profgen"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeSSO is in-process sso which issues unsigned tokens and serves profile of their user
+type fakeSSO struct {
+	augen.UnimplementedAuthServer
+	profgen.UnimplementedProfileServer
+
+	mu sync.Mutex
+	// lifetime of issued tokens
+	tokenTTL time.Duration
+	logins   int
+	// profile calls fail with these codes in order before they succeed
+	failures []codes.Code
+	calls    int
+	// tokens which profile calls were made with
+	seen []string
+}
+
+func (s *fakeSSO) Login(_ context.Context, req *augen.LoginRequest) (*augen.LoginResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.GetPassword() != "password" {
+		return nil, status.Error(codes.InvalidArgument, "invalid credentials")
+	}
+
+	s.logins++
+	payload, _ := json.Marshal(map[string]any{
+		"uid": s.logins,
+		"exp": time.Now().Add(s.tokenTTL).Unix(),
+	})
+
+	return &augen.LoginResponse{Token: "e30." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"}, nil
+}
+
+func (s *fakeSSO) GetProfile(ctx context.Context, _ *profgen.GetProfileRequest) (*profgen.UserProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if tokens := md.Get("authorization"); len(tokens) > 0 {
+		s.seen = append(s.seen, tokens[0])
+	}
+
+	s.calls++
+	if len(s.failures) > 0 {
+		code := s.failures[0]
+		s.failures = s.failures[1:]
+
+		return nil, status.Error(code, "injected failure")
+	}
+
+	return &profgen.UserProfile{UserId: 7, Email: "user@example.com"}, nil
+}
+
+func (s *fakeSSO) ChangeEmail(context.Context, *profgen.ChangeEmailRequest) (*profgen.ChangeEmailResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+
+	return nil, status.Error(codes.Unavailable, "injected failure")
+}
+
+// newTestClient serves sso over bufconn and returns client logged in as user with password
+func newTestClient(t *testing.T, sso *fakeSSO, opts ...Option) *Client {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	augen.RegisterAuthServer(srv, sso)
+	profgen.RegisterProfileServer(srv, sso)
+
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	opts = append([]Option{
+		WithInsecure(),
+		WithDialOptions(grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		})),
+		WithCredentials("user@example.com", "password", 1),
+		WithRetry(RetryPolicy{
+			MaxAttempts:  3,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+			Codes:        []codes.Code{codes.Unavailable},
+		}),
+	}, opts...)
+
+	c, err := New("passthrough:///bufnet", opts...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	return c
+}
+
+func TestClientCachesToken(t *testing.T) {
+	sso := &fakeSSO{tokenTTL: time.Hour}
+	c := newTestClient(t, sso)
+
+	for range 3 {
+		if _, err := c.Profile(context.Background()); err != nil {
+			t.Fatalf("Profile: %v", err)
+		}
+	}
+
+	if sso.logins != 1 {
+		t.Errorf("logged in %d times, want token cached after first login", sso.logins)
+	}
+	for _, token := range sso.seen {
+		if token != sso.seen[0] {
+			t.Fatalf("calls were made with different tokens %v", sso.seen)
+		}
+	}
+}
+
+func TestClientRefreshesTokenBeforeExpiry(t *testing.T) {
+	tests := []struct {
+		name          string
+		refreshBefore time.Duration
+		wantLogins    int
+	}{
+		{name: "token expires within refresh window", refreshBefore: time.Minute, wantLogins: 2},
+		{name: "token outlives refresh window", refreshBefore: 10 * time.Second, wantLogins: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sso := &fakeSSO{tokenTTL: 30 * time.Second}
+			c := newTestClient(t, sso, WithRefreshBefore(tt.refreshBefore))
+
+			for range 2 {
+				if _, err := c.Profile(context.Background()); err != nil {
+					t.Fatalf("Profile: %v", err)
+				}
+			}
+
+			if sso.logins != tt.wantLogins {
+				t.Errorf("logged in %d times, want %d", sso.logins, tt.wantLogins)
+			}
+		})
+	}
+}
+
+func TestClientRelogsInOnUnauthenticated(t *testing.T) {
+	sso := &fakeSSO{tokenTTL: time.Hour, failures: []codes.Code{codes.Unauthenticated}}
+	c := newTestClient(t, sso)
+
+	if _, err := c.Profile(context.Background()); err != nil {
+		t.Fatalf("Profile: %v", err)
+	}
+
+	if sso.logins != 2 {
+		t.Errorf("logged in %d times, want new login after rejected token", sso.logins)
+	}
+	if len(sso.seen) != 2 || sso.seen[0] == sso.seen[1] {
+		t.Errorf("rejected token was sent again: %v", sso.seen)
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  []codes.Code
+		wantCode  codes.Code
+		wantCalls int
+	}{
+		{
+			name:      "recovers after Unavailable",
+			failures:  []codes.Code{codes.Unavailable, codes.Unavailable},
+			wantCode:  codes.OK,
+			wantCalls: 3,
+		},
+		{
+			name:      "gives up after max attempts",
+			failures:  []codes.Code{codes.Unavailable, codes.Unavailable, codes.Unavailable},
+			wantCode:  codes.Unavailable,
+			wantCalls: 3,
+		},
+		{
+			name:      "does not retry other codes",
+			failures:  []codes.Code{codes.PermissionDenied},
+			wantCode:  codes.PermissionDenied,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sso := &fakeSSO{tokenTTL: time.Hour, failures: tt.failures}
+			c := newTestClient(t, sso)
+
+			_, err := c.Profile(context.Background())
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Profile: got %v, want %s", err, tt.wantCode)
+			}
+			if sso.calls != tt.wantCalls {
+				t.Errorf("server got %d calls, want %d", sso.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClientDoesNotRetryNonIdempotentCalls(t *testing.T) {
+	sso := &fakeSSO{tokenTTL: time.Hour}
+	c := newTestClient(t, sso)
+
+	if _, err := c.ChangeEmail(context.Background(), "new@example.com", "password"); status.Code(err) != codes.Unavailable {
+		t.Fatalf("ChangeEmail: got %v, want Unavailable", err)
+	}
+	if sso.calls != 1 {
+		t.Errorf("ChangeEmail was sent %d times, want 1", sso.calls)
+	}
+}
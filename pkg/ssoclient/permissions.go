@@ -0,0 +1,71 @@
+package ssoclient
+
+import (
+	"context"
+
+	"sso/proto/generated/permgen"
+
+	"google.golang.org/grpc"
+)
+
+// Permission checks are made with token of configured user, users can check only themselves unless they are admins
+
+func (c *Client) CanDeleteUser(ctx context.Context, appID uint64, userID int64) (bool, error) {
+	var allowed bool
+	err := c.userCall(ctx, true, func(ctx context.Context, opts ...grpc.CallOption) error {
+		resp, err := c.perms.DeleteUser(ctx, &permgen.DeleteRequest{AppId: appID, UserId: userID}, opts...)
+		if err != nil {
+			return err
+		}
+		allowed = resp.GetPermission()
+
+		return nil
+	})
+
+	return allowed, err
+}
+
+func (c *Client) CanUpdateUser(ctx context.Context, appID uint64, userID int64) (bool, error) {
+	var allowed bool
+	err := c.userCall(ctx, true, func(ctx context.Context, opts ...grpc.CallOption) error {
+		resp, err := c.perms.UpdateUser(ctx, &permgen.UpdateRequest{AppId: appID, UserId: userID}, opts...)
+		if err != nil {
+			return err
+		}
+		allowed = resp.GetPermission()
+
+		return nil
+	})
+
+	return allowed, err
+}
+
+func (c *Client) CanDownloadVideo(ctx context.Context, appID uint64, userID int64) (bool, error) {
+	var allowed bool
+	err := c.userCall(ctx, true, func(ctx context.Context, opts ...grpc.CallOption) error {
+		resp, err := c.perms.VideoPerm(ctx, &permgen.DownloadRequest{AppId: appID, UserId: userID}, opts...)
+		if err != nil {
+			return err
+		}
+		allowed = resp.GetPermission()
+
+		return nil
+	})
+
+	return allowed, err
+}
+
+func (c *Client) CanChangeOptions(ctx context.Context, appID uint64, userID int64) (bool, error) {
+	var allowed bool
+	err := c.userCall(ctx, true, func(ctx context.Context, opts ...grpc.CallOption) error {
+		resp, err := c.perms.ChangeOptions(ctx, &permgen.ChangeOptionsRequest{AppId: appID, UserId: userID}, opts...)
+		if err != nil {
+			return err
+		}
+		allowed = resp.GetPermission()
+
+		return nil
+	})
+
+	return allowed, err
+}
@@ -0,0 +1,129 @@
+package ssoclient
+
+import (
+	"context"
+	"time"
+
+	"sso/proto/generated/profgen"
+
+	"google.golang.org/grpc"
+)
+
+// Profile belongs to user of WithCredentials, profile methods never take user id
+type Profile struct {
+	UserID       int64
+	Username     string
+	Email        string
+	Avatar       string
+	PendingEmail string    // waits for confirmation
+	LastLogin    time.Time // zero if user has never logged in
+	CreatedAt    time.Time
+}
+
+func (c *Client) Profile(ctx context.Context) (Profile, error) {
+	var profile Profile
+	err := c.userCall(ctx, true, func(ctx context.Context, opts ...grpc.CallOption) error {
+		resp, err := c.profile.GetProfile(ctx, &profgen.GetProfileRequest{}, opts...)
+		if err != nil {
+			return err
+		}
+		profile = profileFrom(resp)
+
+		return nil
+	})
+
+	return profile, err
+}
+
+// UpdateProfile replaces username and avatar, empty avatar removes it
+func (c *Client) UpdateProfile(ctx context.Context, username, avatar string) (Profile, error) {
+	var profile Profile
+	err := c.userCall(ctx, true, func(ctx context.Context, opts ...grpc.CallOption) error {
+		resp, err := c.profile.UpdateProfile(ctx, &profgen.UpdateProfileRequest{
+			Username: username,
+			Avatar:   avatar,
+		}, opts...)
+		if err != nil {
+			return err
+		}
+		profile = profileFrom(resp)
+
+		return nil
+	})
+
+	return profile, err
+}
+
+// ChangeEmail sends code to new address and returns when the code expires, it is not retried
+// because every call sends new code
+func (c *Client) ChangeEmail(ctx context.Context, newEmail, password string) (time.Time, error) {
+	var expiresAt time.Time
+	err := c.userCall(ctx, false, func(ctx context.Context, opts ...grpc.CallOption) error {
+		resp, err := c.profile.ChangeEmail(ctx, &profgen.ChangeEmailRequest{
+			NewEmail: newEmail,
+			Password: password,
+		}, opts...)
+		if err != nil {
+			return err
+		}
+		expiresAt = parseTime(resp.GetExpiresAt())
+
+		return nil
+	})
+
+	return expiresAt, err
+}
+
+// ConfirmEmail changes email to address which code was sent to. Client keeps logging in
+// with email of WithCredentials, so it has to be recreated with the new one
+func (c *Client) ConfirmEmail(ctx context.Context, code string) (Profile, error) {
+	var profile Profile
+	err := c.userCall(ctx, false, func(ctx context.Context, opts ...grpc.CallOption) error {
+		resp, err := c.profile.ConfirmEmail(ctx, &profgen.ConfirmEmailRequest{Code: code}, opts...)
+		if err != nil {
+			return err
+		}
+		profile = profileFrom(resp)
+
+		return nil
+	})
+
+	return profile, err
+}
+
+// ChangePassword revokes every session of user and returns new token, zero token means user
+// has to log in again. Client keeps logging in with password of WithCredentials, so it has
+// to be recreated with the new one
+func (c *Client) ChangePassword(ctx context.Context, current, password string) (Token, error) {
+	var token Token
+	err := c.userCall(ctx, false, func(ctx context.Context, opts ...grpc.CallOption) error {
+		resp, err := c.profile.ChangePassword(ctx, &profgen.ChangePasswordRequest{
+			CurrentPassword: current,
+			NewPassword:     password,
+		}, opts...)
+		if err != nil {
+			return err
+		}
+		if resp.GetToken() == "" {
+			return nil
+		}
+
+		token, err = ParseToken(resp.GetToken())
+
+		return err
+	})
+
+	return token, err
+}
+
+func profileFrom(resp *profgen.UserProfile) Profile {
+	return Profile{
+		UserID:       resp.GetUserId(),
+		Username:     resp.GetUsername(),
+		Email:        resp.GetEmail(),
+		Avatar:       resp.GetAvatar(),
+		PendingEmail: resp.GetPendingEmail(),
+		LastLogin:    parseTime(resp.GetLastLogin()),
+		CreatedAt:    parseTime(resp.GetCreatedAt()),
+	}
+}
@@ -0,0 +1,76 @@
+package ssoclient
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy of idempotent calls, delay grows exponentially with full jitter
+type RetryPolicy struct {
+	// attempts including the first one, 1 disables retries
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	// codes which are retried
+	Codes []codes.Code
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:  3,
+	InitialDelay: 100 * time.Millisecond,
+	MaxDelay:     2 * time.Second,
+	Codes:        []codes.Code{codes.Unavailable, codes.ResourceExhausted, codes.Aborted},
+}
+
+func (p RetryPolicy) do(ctx context.Context, call func(ctx context.Context) error) error {
+	delay := p.InitialDelay
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = call(ctx)
+		if err == nil || attempt >= p.MaxAttempts || !p.retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(jitter(delay))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return err
+		case <-timer.C:
+		}
+
+		delay *= 2
+		if delay > p.MaxDelay {
+			delay = p.MaxDelay
+		}
+	}
+}
+
+func jitter(delay time.Duration) time.Duration {
+	if delay <= 0 {
+		return 0
+	}
+
+	return rand.N(delay)
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	code := status.Code(err)
+	for _, c := range p.Codes {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isUnauthenticated(err error) bool {
+	return status.Code(err) == codes.Unauthenticated
+}
@@ -0,0 +1,230 @@
+package ssoclient
+
+import (
+	"context"
+	"time"
+
+	"sso/proto/generated/stagen"
+
+	"google.golang.org/grpc"
+)
+
+type BanStatus struct {
+	Banned    bool
+	Message   string
+	Reason    string
+	ExpiresAt time.Time // zero for permanent ban
+}
+
+type Role struct {
+	Username string
+	Role     string
+}
+
+type LoginAttempt struct {
+	ID        int64
+	Success   bool
+	Reason    string
+	IP        string
+	UserAgent string
+	AppID     uint64
+	CreatedAt time.Time
+}
+
+// LoginHistoryQuery filters login history, zero From and To are not applied
+type LoginHistoryQuery struct {
+	UserID    int64
+	From      time.Time
+	To        time.Time
+	PageSize  int32
+	PageToken string
+}
+
+type LoginHistoryPage struct {
+	Attempts []LoginAttempt
+	// empty on the last page
+	NextPageToken string
+}
+
+type RoleEntry struct {
+	Role              string
+	Description       string
+	Permissions       []string
+	DeniedPermissions []string
+	Group             string // group which role is inherited from, empty for direct roles
+}
+
+type AppRoles struct {
+	AppID                uint64 // 0 means roles bound in every app
+	Roles                []RoleEntry
+	EffectivePermissions []string
+	// denies which make exceptions of wildcards in EffectivePermissions, "video:*" with
+	// excluded "video:download" allows every video permission except download
+	ExcludedPermissions []string
+}
+
+func (c *Client) IsBanned(ctx context.Context, userID int64) (BanStatus, error) {
+	var ban BanStatus
+	err := c.userCall(ctx, true, func(ctx context.Context, opts ...grpc.CallOption) error {
+		resp, err := c.status.IsBanned(ctx, &stagen.IsBannedRequest{UserId: userID}, opts...)
+		if err != nil {
+			return err
+		}
+		ban = BanStatus{
+			Banned:    resp.GetIsBanned(),
+			Message:   resp.GetMessage(),
+			Reason:    resp.GetReason(),
+			ExpiresAt: parseTime(resp.GetExpiresAt()),
+		}
+
+		return nil
+	})
+
+	return ban, err
+}
+
+func (c *Client) CurrentRole(ctx context.Context, userID int64) (Role, error) {
+	var role Role
+	err := c.userCall(ctx, true, func(ctx context.Context, opts ...grpc.CallOption) error {
+		resp, err := c.status.CurrentRole(ctx, &stagen.RoleRequest{UserId: userID}, opts...)
+		if err != nil {
+			return err
+		}
+		role = Role{Username: resp.GetUsername(), Role: resp.GetRole()}
+
+		return nil
+	})
+
+	return role, err
+}
+
+// LastLogin returns zero time when user has never logged in
+func (c *Client) LastLogin(ctx context.Context, userID int64) (time.Time, error) {
+	var lastLogin time.Time
+	err := c.userCall(ctx, true, func(ctx context.Context, opts ...grpc.CallOption) error {
+		resp, err := c.status.LastLogin(ctx, &stagen.LastLogRequest{UserId: userID}, opts...)
+		if err != nil {
+			return err
+		}
+		lastLogin = parseTime(resp.GetLastlogin())
+
+		return nil
+	})
+
+	return lastLogin, err
+}
+
+func (c *Client) LoginHistory(ctx context.Context, query LoginHistoryQuery) (LoginHistoryPage, error) {
+	req := &stagen.LoginHistoryRequest{
+		UserId:    query.UserID,
+		From:      formatTime(query.From),
+		To:        formatTime(query.To),
+		PageSize:  query.PageSize,
+		PageToken: query.PageToken,
+	}
+
+	var page LoginHistoryPage
+	err := c.userCall(ctx, true, func(ctx context.Context, opts ...grpc.CallOption) error {
+		resp, err := c.status.LoginHistory(ctx, req, opts...)
+		if err != nil {
+			return err
+		}
+
+		page = LoginHistoryPage{
+			Attempts:      make([]LoginAttempt, 0, len(resp.GetAttempts())),
+			NextPageToken: resp.GetNextPageToken(),
+		}
+		for _, a := range resp.GetAttempts() {
+			page.Attempts = append(page.Attempts, LoginAttempt{
+				ID:        a.GetId(),
+				Success:   a.GetSuccess(),
+				Reason:    a.GetReason(),
+				IP:        a.GetIp(),
+				UserAgent: a.GetUserAgent(),
+				AppID:     a.GetAppId(),
+				CreatedAt: parseTime(a.GetCreatedAt()),
+			})
+		}
+
+		return nil
+	})
+
+	return page, err
+}
+
+func (c *Client) Roles(ctx context.Context, userID int64) ([]AppRoles, error) {
+	var apps []AppRoles
+	err := c.userCall(ctx, true, func(ctx context.Context, opts ...grpc.CallOption) error {
+		resp, err := c.status.Roles(ctx, &stagen.RolesRequest{UserId: userID}, opts...)
+		if err != nil {
+			return err
+		}
+
+		apps = make([]AppRoles, 0, len(resp.GetApps()))
+		for _, app := range resp.GetApps() {
+			appRoles := AppRoles{
+				AppID:                app.GetAppId(),
+				Roles:                make([]RoleEntry, 0, len(app.GetRoles())),
+				EffectivePermissions: app.GetEffectivePermissions(),
+				ExcludedPermissions:  app.GetExcludedPermissions(),
+			}
+			for _, role := range app.GetRoles() {
+				appRoles.Roles = append(appRoles.Roles, RoleEntry{
+					Role:              role.GetRole(),
+					Description:       role.GetRoleDescription(),
+					Permissions:       role.GetPermissions(),
+					DeniedPermissions: role.GetDeniedPermissions(),
+					Group:             role.GetGroup(),
+				})
+			}
+			apps = append(apps, appRoles)
+		}
+
+		return nil
+	})
+
+	return apps, err
+}
+
+// BanUser needs admin credentials, zero expiresAt bans permanently. It is not retried
+func (c *Client) BanUser(ctx context.Context, userID int64, reason string, expiresAt time.Time) (int64, error) {
+	var banID int64
+	err := c.userCall(ctx, false, func(ctx context.Context, opts ...grpc.CallOption) error {
+		resp, err := c.status.BanUser(ctx, &stagen.BanRequest{
+			UserId:    userID,
+			Reason:    reason,
+			ExpiresAt: formatTime(expiresAt),
+		}, opts...)
+		if err != nil {
+			return err
+		}
+		banID = resp.GetBanId()
+
+		return nil
+	})
+
+	return banID, err
+}
+
+// UnbanUser needs admin credentials
+func (c *Client) UnbanUser(ctx context.Context, userID int64, reason string) error {
+	return c.userCall(ctx, false, func(ctx context.Context, opts ...grpc.CallOption) error {
+		_, err := c.status.UnbanUser(ctx, &stagen.UnbanRequest{UserId: userID, Reason: reason}, opts...)
+
+		return err
+	})
+}
+
+func parseTime(value string) time.Time {
+	t, _ := time.Parse(time.RFC3339, value)
+
+	return t
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,134 @@
+package ssoclient
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrMalformedToken = errors.New("ssoclient: malformed token")
+
+// Token is access token issued by sso
+type Token struct {
+	Value     string
+	ExpiresAt time.Time // zero when token has no exp claim
+}
+
+// FetchFunc obtains new token, e.g. by logging in
+type FetchFunc func(ctx context.Context) (string, error)
+
+// TokenSource caches token in memory and fetches new one before cached token expires.
+// It is safe for concurrent use, parallel callers wait for one fetch
+type TokenSource struct {
+	fetch         FetchFunc
+	refreshBefore time.Duration
+	now           func() time.Time
+
+	mu    sync.Mutex
+	token Token
+}
+
+func NewTokenSource(fetch FetchFunc, refreshBefore time.Duration) *TokenSource {
+	return &TokenSource{
+		fetch:         fetch,
+		refreshBefore: refreshBefore,
+		now:           time.Now,
+	}
+}
+
+// Token returns cached token or fetches new one when cached is missing or expires within refreshBefore
+func (s *TokenSource) Token(ctx context.Context) (Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.valid() {
+		return s.token, nil
+	}
+
+	value, err := s.fetch(ctx)
+	if err != nil {
+		return Token{}, err
+	}
+
+	token, err := ParseToken(value)
+	if err != nil {
+		return Token{}, err
+	}
+
+	s.token = token
+
+	return token, nil
+}
+
+// Invalidate drops cached token, e.g. after server has rejected it
+func (s *TokenSource) Invalidate() {
+	s.mu.Lock()
+	s.token = Token{}
+	s.mu.Unlock()
+}
+
+func (s *TokenSource) valid() bool {
+	if s.token.Value == "" {
+		return false
+	}
+	if s.token.ExpiresAt.IsZero() {
+		return true
+	}
+
+	return s.now().Add(s.refreshBefore).Before(s.token.ExpiresAt)
+}
+
+// ParseToken reads exp of JWT without verifying signature, client only needs to know when to refresh
+func ParseToken(value string) (Token, error) {
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 {
+		return Token{}, ErrMalformedToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return Token{}, ErrMalformedToken
+	}
+
+	var claims struct {
+		Exp float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return Token{}, ErrMalformedToken
+	}
+
+	token := Token{Value: value}
+	if claims.Exp > 0 {
+		token.ExpiresAt = time.Unix(int64(claims.Exp), 0)
+	}
+
+	return token, nil
+}
+
+// TokenCredentials is grpc.PerRPCCredentials which attaches bearer token of TokenSource
+type TokenCredentials struct {
+	source     *TokenSource
+	requireTLS bool
+}
+
+// NewTokenCredentials attaches tokens of source, requireTLS refuses to send token over plain connection
+func NewTokenCredentials(source *TokenSource, requireTLS bool) *TokenCredentials {
+	return &TokenCredentials{source: source, requireTLS: requireTLS}
+}
+
+func (c *TokenCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	token, err := c.source.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"authorization": "Bearer " + token.Value}, nil
+}
+
+func (c *TokenCredentials) RequireTransportSecurity() bool {
+	return c.requireTLS
+}
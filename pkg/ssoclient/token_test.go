@@ -0,0 +1,86 @@
+package ssoclient
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func unsignedToken(exp time.Time) string {
+	payload := fmt.Sprintf(`{"uid":7,"exp":%d}`, exp.Unix())
+
+	return "e30." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".sig"
+}
+
+func TestTokenSourceRefreshBeforeExpiry(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	now := start
+
+	var fetches int
+	source := NewTokenSource(func(context.Context) (string, error) {
+		fetches++
+
+		return unsignedToken(now.Add(10 * time.Minute)), nil
+	}, time.Minute)
+	source.now = func() time.Time { return now }
+
+	tests := []struct {
+		name        string
+		elapsed     time.Duration
+		wantFetches int
+	}{
+		{name: "first call fetches", elapsed: 0, wantFetches: 1},
+		{name: "fresh token is cached", elapsed: 5 * time.Minute, wantFetches: 1},
+		{name: "token within refresh window is replaced", elapsed: 9*time.Minute + 30*time.Second, wantFetches: 2},
+		{name: "new token is cached", elapsed: 10 * time.Minute, wantFetches: 2},
+	}
+
+	for _, tt := range tests {
+		now = start.Add(tt.elapsed)
+
+		if _, err := source.Token(context.Background()); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if fetches != tt.wantFetches {
+			t.Errorf("%s: fetched %d times, want %d", tt.name, fetches, tt.wantFetches)
+		}
+	}
+
+	source.Invalidate()
+	if _, err := source.Token(context.Background()); err != nil {
+		t.Fatalf("Token after Invalidate: %v", err)
+	}
+	if fetches != 3 {
+		t.Errorf("Invalidate did not drop cached token, fetched %d times", fetches)
+	}
+}
+
+func TestTokenSourceFetchError(t *testing.T) {
+	errLogin := errors.New("login failed")
+	source := NewTokenSource(func(context.Context) (string, error) { return "", errLogin }, time.Minute)
+
+	if _, err := source.Token(context.Background()); !errors.Is(err, errLogin) {
+		t.Fatalf("Token: got %v, want %v", err, errLogin)
+	}
+}
+
+func TestParseToken(t *testing.T) {
+	exp := time.Unix(1_700_000_000, 0)
+
+	token, err := ParseToken(unsignedToken(exp))
+	if err != nil {
+		t.Fatalf("ParseToken: %v", err)
+	}
+	if !token.ExpiresAt.Equal(exp) {
+		t.Errorf("ExpiresAt = %s, want %s", token.ExpiresAt, exp)
+	}
+
+	for _, value := range []string{"", "a.b", "e30.!!!.sig", "e30." + base64.RawURLEncoding.EncodeToString([]byte("[")) + ".sig"} {
+		if _, err := ParseToken(value); !errors.Is(err, ErrMalformedToken) {
+			t.Errorf("ParseToken(%q): got %v, want %v", value, err, ErrMalformedToken)
+		}
+	}
+}
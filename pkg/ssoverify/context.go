@@ -0,0 +1,28 @@
+package ssoverify
+
+import "context"
+
+type contextKey struct{}
+
+type verified struct {
+	claims Claims
+	token  string
+}
+
+// NewContext returns ctx carrying claims and raw token, token is forwarded by PermissionChecker
+func NewContext(ctx context.Context, claims Claims, token string) context.Context {
+	return context.WithValue(ctx, contextKey{}, verified{claims: claims, token: token})
+}
+
+// FromContext returns claims put by interceptor or middleware
+func FromContext(ctx context.Context) (Claims, bool) {
+	v, ok := ctx.Value(contextKey{}).(verified)
+
+	return v.claims, ok
+}
+
+func tokenFromContext(ctx context.Context) string {
+	v, _ := ctx.Value(contextKey{}).(verified)
+
+	return v.token
+}
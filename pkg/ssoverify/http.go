@@ -0,0 +1,38 @@
+package ssoverify
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Middleware verifies bearer token of Authorization header and answers 401 to requests without valid token
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") {
+			unauthorized(w, "authorization token is missing")
+
+			return
+		}
+
+		claims, err := v.Verify(r.Context(), header)
+		if err != nil {
+			unauthorized(w, "invalid token")
+
+			return
+		}
+
+		ctx := NewContext(withPermissionCache(r.Context()), claims, header)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
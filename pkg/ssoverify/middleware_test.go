@@ -0,0 +1,134 @@
+package ssoverify_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sso/pkg/ssoverify"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	v := newVerifier(t, ssoverify.WithHMACSecret(hmacSecret))
+	interceptor := v.UnaryServerInterceptor("/test.Service/Public")
+
+	handler := func(ctx context.Context, _ any) (any, error) {
+		claims, ok := ssoverify.FromContext(ctx)
+		if !ok {
+			return "anonymous", nil
+		}
+
+		return claims.UserID, nil
+	}
+
+	incoming := func(pairs ...string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(pairs...))
+	}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		want   any
+		code   codes.Code
+	}{
+		{
+			name:   "valid token",
+			ctx:    incoming("authorization", "Bearer "+signHMAC(t, validClaims(), "", hmacSecret)),
+			method: "/test.Service/Private",
+			want:   int64(7),
+		},
+		{
+			name:   "missing token",
+			ctx:    context.Background(),
+			method: "/test.Service/Private",
+			code:   codes.Unauthenticated,
+		},
+		{
+			name:   "invalid token",
+			ctx:    incoming("authorization", "Bearer "+signHMAC(t, validClaims(), "", []byte("other-secret"))),
+			method: "/test.Service/Private",
+			code:   codes.Unauthenticated,
+		},
+		{
+			name:   "public method without token",
+			ctx:    context.Background(),
+			method: "/test.Service/Public",
+			want:   "anonymous",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := interceptor(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if tt.code != codes.OK {
+				if status.Code(err) != tt.code {
+					t.Fatalf("got %v, want %s", err, tt.code)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("interceptor: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("handler got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	v := newVerifier(t, ssoverify.WithHMACSecret(hmacSecret))
+
+	var seen ssoverify.Claims
+	handler := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = ssoverify.FromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{name: "valid token", header: "Bearer " + signHMAC(t, validClaims(), "", hmacSecret), want: http.StatusNoContent},
+		{name: "missing token", want: http.StatusUnauthorized},
+		{name: "not bearer", header: "Basic dXNlcjpwYXNz", want: http.StatusUnauthorized},
+		{name: "invalid token", header: "Bearer not-a-token", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen = ssoverify.Claims{}
+
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized {
+				if rec.Header().Get("WWW-Authenticate") == "" {
+					t.Error("401 without WWW-Authenticate")
+				}
+
+				return
+			}
+			if seen.UserID != 7 {
+				t.Errorf("handler saw claims %+v, want user 7", seen)
+			}
+		})
+	}
+}
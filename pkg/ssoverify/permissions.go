@@ -0,0 +1,141 @@
+package ssoverify
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"sso/proto/generated/permgen"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Permission checked by Permissions service
+type Permission int
+
+const (
+	DeleteUser Permission = iota + 1
+	UpdateUser
+	DownloadVideo
+	ChangeOptions
+)
+
+// names of permissions as they are embedded to tokens
+var permissionNames = map[Permission]string{
+	DeleteUser:    "delete_user",
+	UpdateUser:    "update_user",
+	DownloadVideo: "video:download",
+	ChangeOptions: "change_options",
+}
+
+// PermissionChecker answers from permissions embedded to token of caller and asks Permissions service
+// on behalf of caller when token does not carry them, answers of service are cached for the request
+type PermissionChecker struct {
+	client permgen.PermissionsClient
+}
+
+func NewPermissionChecker(conn grpc.ClientConnInterface) *PermissionChecker {
+	return &PermissionChecker{client: permgen.NewPermissionsClient(conn)}
+}
+
+// Allowed checks permission of verified caller in app, ctx must come from interceptor or middleware
+func (p *PermissionChecker) Allowed(ctx context.Context, permission Permission, appID uint64) (bool, error) {
+	claims, ok := FromContext(ctx)
+	if !ok {
+		return false, ErrInvalidToken
+	}
+
+	// embedded list is complete for app of token, sso leaves it out rather than truncates it
+	if claims.Permissions != nil && uint64(claims.AppID) == appID {
+		if name, ok := permissionNames[permission]; ok {
+			return slices.Contains(claims.Permissions, name), nil
+		}
+	}
+
+	cache := permissionCacheFromContext(ctx)
+	key := permissionKey{permission: permission, appID: appID, userID: claims.UserID}
+
+	if cache != nil {
+		if allowed, ok := cache.get(key); ok {
+			return allowed, nil
+		}
+	}
+
+	// users can check only their own permissions, so token of caller is forwarded
+	outCtx := metadata.AppendToOutgoingContext(ctx, "authorization", tokenFromContext(ctx))
+
+	allowed, err := p.check(outCtx, key)
+	if err != nil {
+		return false, err
+	}
+
+	if cache != nil {
+		cache.set(key, allowed)
+	}
+
+	return allowed, nil
+}
+
+func (p *PermissionChecker) check(ctx context.Context, key permissionKey) (bool, error) {
+	switch key.permission {
+	case DeleteUser:
+		resp, err := p.client.DeleteUser(ctx, &permgen.DeleteRequest{AppId: key.appID, UserId: key.userID})
+
+		return resp.GetPermission(), err
+	case UpdateUser:
+		resp, err := p.client.UpdateUser(ctx, &permgen.UpdateRequest{AppId: key.appID, UserId: key.userID})
+
+		return resp.GetPermission(), err
+	case DownloadVideo:
+		resp, err := p.client.VideoPerm(ctx, &permgen.DownloadRequest{AppId: key.appID, UserId: key.userID})
+
+		return resp.GetPermission(), err
+	case ChangeOptions:
+		resp, err := p.client.ChangeOptions(ctx, &permgen.ChangeOptionsRequest{AppId: key.appID, UserId: key.userID})
+
+		return resp.GetPermission(), err
+	}
+
+	return false, fmt.Errorf("ssoverify: unknown permission %d", key.permission)
+}
+
+type permissionKey struct {
+	permission Permission
+	appID      uint64
+	userID     int64
+}
+
+// permissionCache lives as long as one request, so revoked permissions are never served from it for long
+type permissionCache struct {
+	mu      sync.Mutex
+	answers map[permissionKey]bool
+}
+
+type permissionCacheKey struct{}
+
+func withPermissionCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, permissionCacheKey{}, &permissionCache{answers: make(map[permissionKey]bool)})
+}
+
+func permissionCacheFromContext(ctx context.Context) *permissionCache {
+	cache, _ := ctx.Value(permissionCacheKey{}).(*permissionCache)
+
+	return cache
+}
+
+func (c *permissionCache) get(key permissionKey) (bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	allowed, ok := c.answers[key]
+
+	return allowed, ok
+}
+
+func (c *permissionCache) set(key permissionKey, allowed bool) {
+	c.mu.Lock()
+	c.answers[key] = allowed
+	c.mu.Unlock()
+}
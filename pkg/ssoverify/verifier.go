@@ -0,0 +1,260 @@
+// Package ssoverify verifies tokens issued by sso in resource servers.
+//
+// Verifier checks signature with shared HMAC secrets or keys from JWKS endpoint,
+// expiry, audience or app and revocation state. Interceptors and HTTP middleware
+// put verified Claims to request context.
+package ssoverify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidToken  = errors.New("ssoverify: invalid token")
+	ErrUnknownKey    = errors.New("ssoverify: unknown signing key")
+	ErrWrongAudience = errors.New("ssoverify: token is issued for another audience")
+	ErrRevoked       = errors.New("ssoverify: token is revoked")
+	ErrNoKeys        = errors.New("ssoverify: neither HMAC secrets nor JWKS are configured")
+)
+
+// Claims of verified token
+type Claims struct {
+	UserID int64
+	Email  string
+	AppID  int64
+	// TenantID is zero in tokens issued before tenants, they belong to default tenant
+	TenantID int64
+	Scopes   []string
+	// Roles and Permissions are nil when token does not carry them, e.g. they did not fit into it
+	Roles       []string
+	Permissions []string
+	Audience    []string
+	ID          string // jti, empty for tokens issued before it was added
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+}
+
+// HasScope reports whether token grants scope
+func (c Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes, scope)
+}
+
+// HasRole reports whether token carries role, false when token carries no roles at all
+func (c Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+// RevocationChecker tells whether token was revoked before it expired, e.g. by looking up jti in shared cache
+type RevocationChecker interface {
+	Revoked(ctx context.Context, claims Claims) (bool, error)
+}
+
+type Verifier struct {
+	hmacKeys    map[string][]byte
+	hmacDefault []byte
+	jwks        *JWKS
+	audience    string
+	appID       int64
+	revocation  RevocationChecker
+	leeway      time.Duration
+}
+
+type Option func(*Verifier)
+
+// WithHMACSecret verifies HS256 tokens without kid header, it is JWT_SECRET of sso
+func WithHMACSecret(secret []byte) Option {
+	return func(v *Verifier) { v.hmacDefault = secret }
+}
+
+// WithHMACKey verifies HS256 tokens signed by rotated key kid
+func WithHMACKey(kid string, secret []byte) Option {
+	return func(v *Verifier) { v.hmacKeys[kid] = secret }
+}
+
+// WithJWKS verifies RS and ES tokens with keys published by JWKS endpoint
+func WithJWKS(jwks *JWKS) Option {
+	return func(v *Verifier) { v.jwks = jwks }
+}
+
+// WithAudience accepts only tokens with aud claim containing audience
+func WithAudience(audience string) Option {
+	return func(v *Verifier) { v.audience = audience }
+}
+
+// WithAppID accepts only tokens issued for app
+func WithAppID(appID int64) Option {
+	return func(v *Verifier) { v.appID = appID }
+}
+
+// WithRevocation rejects tokens which checker reports as revoked
+func WithRevocation(checker RevocationChecker) Option {
+	return func(v *Verifier) { v.revocation = checker }
+}
+
+// WithLeeway tolerates clock skew between sso and resource server
+func WithLeeway(leeway time.Duration) Option {
+	return func(v *Verifier) { v.leeway = leeway }
+}
+
+func New(opts ...Option) (*Verifier, error) {
+	v := &Verifier{hmacKeys: make(map[string][]byte)}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	if len(v.hmacKeys) == 0 && v.hmacDefault == nil && v.jwks == nil {
+		return nil, ErrNoKeys
+	}
+
+	return v, nil
+}
+
+// Verify checks token and returns its claims, token may have "Bearer " prefix
+func (v *Verifier) Verify(ctx context.Context, token string) (Claims, error) {
+	token = strings.TrimPrefix(token, "Bearer ")
+
+	t, err := jwt.Parse(token,
+		func(t *jwt.Token) (any, error) { return v.key(ctx, t) },
+		jwt.WithValidMethods([]string{"HS256", "HS384", "HS512", "RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(v.leeway),
+	)
+	if err != nil {
+		return Claims{}, errors.Join(ErrInvalidToken, err)
+	}
+
+	mapClaims, ok := t.Claims.(jwt.MapClaims)
+	if !ok || !t.Valid {
+		return Claims{}, ErrInvalidToken
+	}
+
+	claims, err := parseClaims(mapClaims)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	if err := v.checkAudience(claims); err != nil {
+		return Claims{}, err
+	}
+
+	if v.revocation != nil {
+		revoked, err := v.revocation.Revoked(ctx, claims)
+		if err != nil {
+			return Claims{}, fmt.Errorf("ssoverify: check revocation: %w", err)
+		}
+		if revoked {
+			return Claims{}, ErrRevoked
+		}
+	}
+
+	return claims, nil
+}
+
+func (v *Verifier) key(ctx context.Context, t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	if _, ok := t.Method.(*jwt.SigningMethodHMAC); ok {
+		if kid == "" && v.hmacDefault != nil {
+			return v.hmacDefault, nil
+		}
+		if secret, ok := v.hmacKeys[kid]; ok {
+			return secret, nil
+		}
+
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+
+	if v.jwks == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+
+	return v.jwks.Key(ctx, kid)
+}
+
+func (v *Verifier) checkAudience(claims Claims) error {
+	if v.audience != "" && !slices.Contains(claims.Audience, v.audience) {
+		return ErrWrongAudience
+	}
+
+	if v.appID != 0 && claims.AppID != v.appID {
+		return ErrWrongAudience
+	}
+
+	return nil
+}
+
+// parseClaims accepts uid as number and as string, older tokens carry it as string.
+// Refresh tokens are rejected, tokens without typ claim were issued before it and are access tokens
+func parseClaims(mc jwt.MapClaims) (Claims, error) {
+	var claims Claims
+
+	if typ, _ := mc["typ"].(string); typ != "" && typ != "access" {
+		return Claims{}, fmt.Errorf("%w: %q is not access token", ErrInvalidToken, typ)
+	}
+
+	switch uid := mc["uid"].(type) {
+	case float64:
+		claims.UserID = int64(uid)
+	case string:
+		id, err := strconv.ParseInt(uid, 10, 64)
+		if err != nil {
+			return Claims{}, fmt.Errorf("%w: uid is not a number", ErrInvalidToken)
+		}
+		claims.UserID = id
+	default:
+		return Claims{}, fmt.Errorf("%w: uid is missing", ErrInvalidToken)
+	}
+
+	if appID, ok := mc["app_id"].(float64); ok {
+		claims.AppID = int64(appID)
+	}
+	if tenantID, ok := mc["tid"].(float64); ok {
+		claims.TenantID = int64(tenantID)
+	}
+	claims.Email, _ = mc["email"].(string)
+	claims.ID, _ = mc["jti"].(string)
+
+	// scopes are space separated as in oauth2
+	if scope, ok := mc["scope"].(string); ok {
+		claims.Scopes = strings.Fields(scope)
+	}
+	claims.Roles = stringList(mc["roles"])
+	claims.Permissions = stringList(mc["perms"])
+
+	if aud, err := mc.GetAudience(); err == nil {
+		claims.Audience = aud
+	}
+	if iat, err := mc.GetIssuedAt(); err == nil && iat != nil {
+		claims.IssuedAt = iat.Time
+	}
+	if exp, err := mc.GetExpirationTime(); err == nil && exp != nil {
+		claims.ExpiresAt = exp.Time
+	}
+
+	return claims, nil
+}
+
+// stringList keeps difference between missing claim, which is nil, and empty one
+func stringList(claim any) []string {
+	values, ok := claim.([]any)
+	if !ok {
+		return nil
+	}
+
+	list := make([]string, 0, len(values))
+	for _, v := range values {
+		if str, ok := v.(string); ok {
+			list = append(list, str)
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,316 @@
+package ssoverify_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"sso/pkg/ssoverify"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var hmacSecret = []byte("jwt-secret")
+
+// claims of access token of user 7 in app 1, valid for an hour
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+
+	return jwt.MapClaims{
+		"typ":    "access",
+		"uid":    7,
+		"email":  "user@example.com",
+		"app_id": 1,
+		"tid":    2,
+		"jti":    "token-id",
+		"scope":  "read write",
+		"roles":  []string{"viewer"},
+		"iat":    now.Unix(),
+		"exp":    now.Add(time.Hour).Unix(),
+	}
+}
+
+func signHMAC(t *testing.T, claims jwt.MapClaims, kid string, secret []byte) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(secret)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	return signed
+}
+
+func newVerifier(t *testing.T, opts ...ssoverify.Option) *ssoverify.Verifier {
+	t.Helper()
+
+	v, err := ssoverify.New(opts...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	return v
+}
+
+func TestNewWithoutKeys(t *testing.T) {
+	if _, err := ssoverify.New(); !errors.Is(err, ssoverify.ErrNoKeys) {
+		t.Fatalf("New without keys: got %v, want %v", err, ssoverify.ErrNoKeys)
+	}
+}
+
+func TestVerifyHMAC(t *testing.T) {
+	v := newVerifier(t,
+		ssoverify.WithHMACSecret(hmacSecret),
+		ssoverify.WithHMACKey("rotated", []byte("rotated-secret")),
+	)
+
+	claims, err := v.Verify(context.Background(), "Bearer "+signHMAC(t, validClaims(), "", hmacSecret))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.UserID != 7 || claims.AppID != 1 || claims.TenantID != 2 || claims.ID != "token-id" {
+		t.Errorf("claims = %+v", claims)
+	}
+	if !claims.HasScope("write") || !claims.HasRole("viewer") || claims.HasRole("admin") {
+		t.Errorf("scopes %v and roles %v are not parsed", claims.Scopes, claims.Roles)
+	}
+
+	if _, err := v.Verify(context.Background(), signHMAC(t, validClaims(), "rotated", []byte("rotated-secret"))); err != nil {
+		t.Errorf("token of rotated key: %v", err)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	v := newVerifier(t,
+		ssoverify.WithHMACSecret(hmacSecret),
+		ssoverify.WithAudience("orders"),
+		ssoverify.WithAppID(1),
+	)
+
+	with := func(key string, value any) jwt.MapClaims {
+		claims := validClaims()
+		claims["aud"] = "orders"
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+
+		return claims
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{
+			name:  "valid",
+			token: signHMAC(t, with("aud", "orders"), "", hmacSecret),
+		},
+		{
+			name:  "wrong audience",
+			token: signHMAC(t, with("aud", "billing"), "", hmacSecret),
+			want:  ssoverify.ErrWrongAudience,
+		},
+		{
+			name:  "missing audience",
+			token: signHMAC(t, with("aud", nil), "", hmacSecret),
+			want:  ssoverify.ErrWrongAudience,
+		},
+		{
+			name:  "wrong app",
+			token: signHMAC(t, with("app_id", 2), "", hmacSecret),
+			want:  ssoverify.ErrWrongAudience,
+		},
+		{
+			name:  "expired",
+			token: signHMAC(t, with("exp", time.Now().Add(-time.Minute).Unix()), "", hmacSecret),
+			want:  ssoverify.ErrInvalidToken,
+		},
+		{
+			name:  "without exp",
+			token: signHMAC(t, with("exp", nil), "", hmacSecret),
+			want:  ssoverify.ErrInvalidToken,
+		},
+		{
+			name:  "refresh token",
+			token: signHMAC(t, with("typ", "refresh"), "", hmacSecret),
+			want:  ssoverify.ErrInvalidToken,
+		},
+		{
+			name:  "wrong secret",
+			token: signHMAC(t, with("aud", "orders"), "", []byte("other-secret")),
+			want:  ssoverify.ErrInvalidToken,
+		},
+		{
+			name:  "unknown kid",
+			token: signHMAC(t, with("aud", "orders"), "unknown", hmacSecret),
+			want:  ssoverify.ErrUnknownKey,
+		},
+		{
+			name:  "garbage",
+			token: "not-a-token",
+			want:  ssoverify.ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(context.Background(), tt.token)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Verify: %v", err)
+				}
+
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Verify: got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyLeeway(t *testing.T) {
+	claims := validClaims()
+	claims["exp"] = time.Now().Add(-10 * time.Second).Unix()
+	token := signHMAC(t, claims, "", hmacSecret)
+
+	if _, err := newVerifier(t, ssoverify.WithHMACSecret(hmacSecret)).Verify(context.Background(), token); err == nil {
+		t.Error("token expired 10s ago is accepted without leeway")
+	}
+
+	v := newVerifier(t, ssoverify.WithHMACSecret(hmacSecret), ssoverify.WithLeeway(time.Minute))
+	if _, err := v.Verify(context.Background(), token); err != nil {
+		t.Errorf("token expired 10s ago with minute of leeway: %v", err)
+	}
+}
+
+// jwksServer publishes RSA key with kid and counts fetches
+func jwksServer(t *testing.T, kid string, key *rsa.PublicKey, fetches *int) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		*fetches++
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestVerifyJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	var fetches int
+	srv := jwksServer(t, "key-1", &key.PublicKey, &fetches)
+	v := newVerifier(t, ssoverify.WithJWKS(ssoverify.NewJWKS(srv.URL, time.Hour, srv.Client())))
+
+	sign := func(kid string, key *rsa.PrivateKey) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, validClaims())
+		token.Header["kid"] = kid
+
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("sign token: %v", err)
+		}
+
+		return signed
+	}
+
+	claims, err := v.Verify(context.Background(), sign("key-1", key))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.UserID != 7 {
+		t.Errorf("UserID = %d, want 7", claims.UserID)
+	}
+
+	if _, err := v.Verify(context.Background(), sign("key-1", key)); err != nil {
+		t.Fatalf("second Verify: %v", err)
+	}
+	if fetches != 1 {
+		t.Errorf("keys are fetched %d times, want cached after first fetch", fetches)
+	}
+
+	if _, err := v.Verify(context.Background(), sign("key-2", key)); !errors.Is(err, ssoverify.ErrUnknownKey) {
+		t.Errorf("unknown kid: got %v, want %v", err, ssoverify.ErrUnknownKey)
+	}
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	if _, err := v.Verify(context.Background(), sign("key-1", other)); !errors.Is(err, ssoverify.ErrInvalidToken) {
+		t.Errorf("token signed by another key: got %v, want %v", err, ssoverify.ErrInvalidToken)
+	}
+
+	// HMAC token must not pass when only JWKS is configured
+	if _, err := v.Verify(context.Background(), signHMAC(t, validClaims(), "key-1", hmacSecret)); err == nil {
+		t.Error("HMAC token is accepted by JWKS verifier")
+	}
+}
+
+// revokedIDs reports tokens with listed jti as revoked
+type revokedIDs map[string]bool
+
+func (r revokedIDs) Revoked(_ context.Context, claims ssoverify.Claims) (bool, error) {
+	if claims.ID == "broken" {
+		return false, errors.New("cache is down")
+	}
+
+	return r[claims.ID], nil
+}
+
+func TestVerifyRevocation(t *testing.T) {
+	v := newVerifier(t,
+		ssoverify.WithHMACSecret(hmacSecret),
+		ssoverify.WithRevocation(revokedIDs{"revoked": true}),
+	)
+
+	verify := func(jti string) error {
+		claims := validClaims()
+		claims["jti"] = jti
+
+		_, err := v.Verify(context.Background(), signHMAC(t, claims, "", hmacSecret))
+
+		return err
+	}
+
+	if err := verify("token-id"); err != nil {
+		t.Errorf("active token: %v", err)
+	}
+	if err := verify("revoked"); !errors.Is(err, ssoverify.ErrRevoked) {
+		t.Errorf("revoked token: got %v, want %v", err, ssoverify.ErrRevoked)
+	}
+	// token cannot be trusted when its state is unknown
+	if err := verify("broken"); err == nil || errors.Is(err, ssoverify.ErrRevoked) {
+		t.Errorf("failed revocation check: got %v, want check error", err)
+	}
+}
@@ -23,6 +23,8 @@ const (
 	Permissions_UpdateUser_FullMethodName    = "/permissions.Permissions/UpdateUser"
 	Permissions_VideoPerm_FullMethodName     = "/permissions.Permissions/VideoPerm"
 	Permissions_ChangeOptions_FullMethodName = "/permissions.Permissions/ChangeOptions"
+	Permissions_ExportPolicy_FullMethodName  = "/permissions.Permissions/ExportPolicy"
+	Permissions_ImportPolicy_FullMethodName  = "/permissions.Permissions/ImportPolicy"
 )
 
 // PermissionsClient is the client API for Permissions service.
@@ -33,6 +35,8 @@ type PermissionsClient interface {
 	UpdateUser(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
 	VideoPerm(ctx context.Context, in *DownloadRequest, opts ...grpc.CallOption) (*DownloadResponse, error)
 	ChangeOptions(ctx context.Context, in *ChangeOptionsRequest, opts ...grpc.CallOption) (*ChangeOptionsResponse, error)
+	ExportPolicy(ctx context.Context, in *ExportPolicyRequest, opts ...grpc.CallOption) (*ExportPolicyResponse, error)
+	ImportPolicy(ctx context.Context, in *ImportPolicyRequest, opts ...grpc.CallOption) (*ImportPolicyResponse, error)
 }
 
 type permissionsClient struct {
@@ -83,6 +87,26 @@ func (c *permissionsClient) ChangeOptions(ctx context.Context, in *ChangeOptions
 	return out, nil
 }
 
+func (c *permissionsClient) ExportPolicy(ctx context.Context, in *ExportPolicyRequest, opts ...grpc.CallOption) (*ExportPolicyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExportPolicyResponse)
+	err := c.cc.Invoke(ctx, Permissions_ExportPolicy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permissionsClient) ImportPolicy(ctx context.Context, in *ImportPolicyRequest, opts ...grpc.CallOption) (*ImportPolicyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImportPolicyResponse)
+	err := c.cc.Invoke(ctx, Permissions_ImportPolicy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PermissionsServer is the server API for Permissions service.
 // All implementations must embed UnimplementedPermissionsServer
 // for forward compatibility.
@@ -91,6 +115,8 @@ type PermissionsServer interface {
 	UpdateUser(context.Context, *UpdateRequest) (*UpdateResponse, error)
 	VideoPerm(context.Context, *DownloadRequest) (*DownloadResponse, error)
 	ChangeOptions(context.Context, *ChangeOptionsRequest) (*ChangeOptionsResponse, error)
+	ExportPolicy(context.Context, *ExportPolicyRequest) (*ExportPolicyResponse, error)
+	ImportPolicy(context.Context, *ImportPolicyRequest) (*ImportPolicyResponse, error)
 	mustEmbedUnimplementedPermissionsServer()
 }
 
@@ -113,6 +139,12 @@ func (UnimplementedPermissionsServer) VideoPerm(context.Context, *DownloadReques
 func (UnimplementedPermissionsServer) ChangeOptions(context.Context, *ChangeOptionsRequest) (*ChangeOptionsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ChangeOptions not implemented")
 }
+func (UnimplementedPermissionsServer) ExportPolicy(context.Context, *ExportPolicyRequest) (*ExportPolicyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportPolicy not implemented")
+}
+func (UnimplementedPermissionsServer) ImportPolicy(context.Context, *ImportPolicyRequest) (*ImportPolicyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportPolicy not implemented")
+}
 func (UnimplementedPermissionsServer) mustEmbedUnimplementedPermissionsServer() {}
 func (UnimplementedPermissionsServer) testEmbeddedByValue()                     {}
 
@@ -206,6 +238,42 @@ func _Permissions_ChangeOptions_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Permissions_ExportPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PermissionsServer).ExportPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Permissions_ExportPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PermissionsServer).ExportPolicy(ctx, req.(*ExportPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Permissions_ImportPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PermissionsServer).ImportPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Permissions_ImportPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PermissionsServer).ImportPolicy(ctx, req.(*ImportPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Permissions_ServiceDesc is the grpc.ServiceDesc for Permissions service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -229,6 +297,14 @@ var Permissions_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ChangeOptions",
 			Handler:    _Permissions_ChangeOptions_Handler,
 		},
+		{
+			MethodName: "ExportPolicy",
+			Handler:    _Permissions_ExportPolicy_Handler,
+		},
+		{
+			MethodName: "ImportPolicy",
+			Handler:    _Permissions_ImportPolicy_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "permissions.proto",
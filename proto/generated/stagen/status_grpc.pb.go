@@ -19,9 +19,13 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Status_IsBanned_FullMethodName    = "/status.Status/IsBanned"
-	Status_CurrentRole_FullMethodName = "/status.Status/CurrentRole"
-	Status_LastLogin_FullMethodName   = "/status.Status/LastLogin"
+	Status_IsBanned_FullMethodName     = "/status.Status/IsBanned"
+	Status_CurrentRole_FullMethodName  = "/status.Status/CurrentRole"
+	Status_LastLogin_FullMethodName    = "/status.Status/LastLogin"
+	Status_BanUser_FullMethodName      = "/status.Status/BanUser"
+	Status_UnbanUser_FullMethodName    = "/status.Status/UnbanUser"
+	Status_LoginHistory_FullMethodName = "/status.Status/LoginHistory"
+	Status_Roles_FullMethodName        = "/status.Status/Roles"
 )
 
 // StatusClient is the client API for Status service.
@@ -31,6 +35,10 @@ type StatusClient interface {
 	IsBanned(ctx context.Context, in *IsBannedRequest, opts ...grpc.CallOption) (*IsBannedResponse, error)
 	CurrentRole(ctx context.Context, in *RoleRequest, opts ...grpc.CallOption) (*RoleResponse, error)
 	LastLogin(ctx context.Context, in *LastLogRequest, opts ...grpc.CallOption) (*LastLogResponse, error)
+	BanUser(ctx context.Context, in *BanRequest, opts ...grpc.CallOption) (*BanResponse, error)
+	UnbanUser(ctx context.Context, in *UnbanRequest, opts ...grpc.CallOption) (*UnbanResponse, error)
+	LoginHistory(ctx context.Context, in *LoginHistoryRequest, opts ...grpc.CallOption) (*LoginHistoryResponse, error)
+	Roles(ctx context.Context, in *RolesRequest, opts ...grpc.CallOption) (*RolesResponse, error)
 }
 
 type statusClient struct {
@@ -71,6 +79,46 @@ func (c *statusClient) LastLogin(ctx context.Context, in *LastLogRequest, opts .
 	return out, nil
 }
 
+func (c *statusClient) BanUser(ctx context.Context, in *BanRequest, opts ...grpc.CallOption) (*BanResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BanResponse)
+	err := c.cc.Invoke(ctx, Status_BanUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusClient) UnbanUser(ctx context.Context, in *UnbanRequest, opts ...grpc.CallOption) (*UnbanResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnbanResponse)
+	err := c.cc.Invoke(ctx, Status_UnbanUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusClient) LoginHistory(ctx context.Context, in *LoginHistoryRequest, opts ...grpc.CallOption) (*LoginHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoginHistoryResponse)
+	err := c.cc.Invoke(ctx, Status_LoginHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusClient) Roles(ctx context.Context, in *RolesRequest, opts ...grpc.CallOption) (*RolesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RolesResponse)
+	err := c.cc.Invoke(ctx, Status_Roles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // StatusServer is the server API for Status service.
 // All implementations must embed UnimplementedStatusServer
 // for forward compatibility.
@@ -78,6 +126,10 @@ type StatusServer interface {
 	IsBanned(context.Context, *IsBannedRequest) (*IsBannedResponse, error)
 	CurrentRole(context.Context, *RoleRequest) (*RoleResponse, error)
 	LastLogin(context.Context, *LastLogRequest) (*LastLogResponse, error)
+	BanUser(context.Context, *BanRequest) (*BanResponse, error)
+	UnbanUser(context.Context, *UnbanRequest) (*UnbanResponse, error)
+	LoginHistory(context.Context, *LoginHistoryRequest) (*LoginHistoryResponse, error)
+	Roles(context.Context, *RolesRequest) (*RolesResponse, error)
 	mustEmbedUnimplementedStatusServer()
 }
 
@@ -97,6 +149,18 @@ func (UnimplementedStatusServer) CurrentRole(context.Context, *RoleRequest) (*Ro
 func (UnimplementedStatusServer) LastLogin(context.Context, *LastLogRequest) (*LastLogResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method LastLogin not implemented")
 }
+func (UnimplementedStatusServer) BanUser(context.Context, *BanRequest) (*BanResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BanUser not implemented")
+}
+func (UnimplementedStatusServer) UnbanUser(context.Context, *UnbanRequest) (*UnbanResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnbanUser not implemented")
+}
+func (UnimplementedStatusServer) LoginHistory(context.Context, *LoginHistoryRequest) (*LoginHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LoginHistory not implemented")
+}
+func (UnimplementedStatusServer) Roles(context.Context, *RolesRequest) (*RolesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Roles not implemented")
+}
 func (UnimplementedStatusServer) mustEmbedUnimplementedStatusServer() {}
 func (UnimplementedStatusServer) testEmbeddedByValue()                {}
 
@@ -172,6 +236,78 @@ func _Status_LastLogin_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Status_BanUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServer).BanUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Status_BanUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServer).BanUser(ctx, req.(*BanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Status_UnbanUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnbanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServer).UnbanUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Status_UnbanUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServer).UnbanUser(ctx, req.(*UnbanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Status_LoginHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServer).LoginHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Status_LoginHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServer).LoginHistory(ctx, req.(*LoginHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Status_Roles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RolesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServer).Roles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Status_Roles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServer).Roles(ctx, req.(*RolesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Status_ServiceDesc is the grpc.ServiceDesc for Status service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -191,6 +327,22 @@ var Status_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "LastLogin",
 			Handler:    _Status_LastLogin_Handler,
 		},
+		{
+			MethodName: "BanUser",
+			Handler:    _Status_BanUser_Handler,
+		},
+		{
+			MethodName: "UnbanUser",
+			Handler:    _Status_UnbanUser_Handler,
+		},
+		{
+			MethodName: "LoginHistory",
+			Handler:    _Status_LoginHistory_Handler,
+		},
+		{
+			MethodName: "Roles",
+			Handler:    _Status_Roles_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "status.proto",